@@ -0,0 +1,15 @@
+package main
+
+import (
+	"gitee.com/geekbang/basic-go/webook/internal/job"
+	"github.com/gin-gonic/gin"
+	"github.com/robfig/cron/v3"
+)
+
+type App struct {
+	server    *gin.Engine
+	cron      *cron.Cron
+	scheduler *job.Scheduler
+	// 停止 cron 之后要释放热榜任务的锁
+	rankingJob *job.RankingJob
+}
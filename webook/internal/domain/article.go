@@ -10,6 +10,10 @@ type Article struct {
 	Author  Author
 	Ctime   time.Time
 	Utime   time.Time
+	// PublishTime 定时发表的时间，只有 ArticleStatusScheduled 状态下才有意义
+	PublishTime time.Time
+	// Tags 为 nil 的时候，保存文章不会修改原本的标签
+	Tags []string
 }
 
 func (a Article) Abstract() string {
@@ -20,6 +24,32 @@ func (a Article) Abstract() string {
 	return string(res)
 }
 
+// Tag 标签，ArticleCnt 是这个标签下面已经发表的文章数量
+type Tag struct {
+	Name       string
+	ArticleCnt int64
+}
+
+// ArticleRevision 文章的历史版本，每次保存和发表都会生成一个，不可修改
+type ArticleRevision struct {
+	Id        int64
+	ArticleId int64
+	AuthorId  int64
+	Title     string
+	Content   string
+	// 生成这个版本的时候文章的状态
+	Status ArticleStatus
+	Ctime  time.Time
+}
+
+func (r ArticleRevision) Abstract() string {
+	res := []rune(r.Content)
+	if len(res) > 100 {
+		res = res[:100]
+	}
+	return string(res)
+}
+
 type Author struct {
 	Id   int64
 	Name string
@@ -37,6 +67,8 @@ const (
 	ArticleStatusPublished
 	// ArticleStatusPrivate 仅自己可见
 	ArticleStatusPrivate
+	// ArticleStatusScheduled 定时发表，还没到时间
+	ArticleStatusScheduled
 )
 
 //go:inline
@@ -12,11 +12,12 @@ func _() {
 	_ = x[ArticleStatusUnpublished-1]
 	_ = x[ArticleStatusPublished-2]
 	_ = x[ArticleStatusPrivate-3]
+	_ = x[ArticleStatusScheduled-4]
 }
 
-const _ArticleStatus_name = "ArticleStatusUnknownArticleStatusUnpublishedArticleStatusPublishedArticleStatusPrivate"
+const _ArticleStatus_name = "ArticleStatusUnknownArticleStatusUnpublishedArticleStatusPublishedArticleStatusPrivateArticleStatusScheduled"
 
-var _ArticleStatus_index = [...]uint8{0, 20, 44, 66, 86}
+var _ArticleStatus_index = [...]uint8{0, 20, 44, 66, 86, 108}
 
 func (i ArticleStatus) String() string {
 	if i >= ArticleStatus(len(_ArticleStatus_index)-1) {
@@ -0,0 +1,20 @@
+package domain
+
+import "time"
+
+// Comment 评论，RootId 为 0 的是直接评论文章的根评论，其余的都是某个根评论下面的回复
+type Comment struct {
+	Id    int64
+	Biz   string
+	BizId int64
+	// Commentator 发表评论的人
+	Commentator Author
+	Content     string
+	// RootId 所属的根评论
+	RootId int64
+	// ParentId 回复的是哪一条评论，根评论为 0
+	ParentId int64
+	// ReplyCnt 根评论下面有多少条回复，回复本身没有这个数据
+	ReplyCnt int64
+	Ctime    time.Time
+}
@@ -0,0 +1,13 @@
+package domain
+
+import "time"
+
+// FeedEvent 时间线里面的一条，目前只有发表文章
+type FeedEvent struct {
+	ArticleId int64
+	AuthorId  int64
+	// Ctime 第一次发表的时间，重新发表不会变
+	Ctime time.Time
+	// Article 读时间线的时候填充，文章已经撤回或者删除的时候是零值
+	Article Article
+}
@@ -0,0 +1,20 @@
+package domain
+
+import "time"
+
+// FollowRelation Follower 关注了 Followee
+type FollowRelation struct {
+	Id       int64
+	Follower int64
+	Followee int64
+	// Utime 关注的时间，取消之后重新关注会更新
+	Utime time.Time
+}
+
+// FollowStatics 关注相关的统计数据
+type FollowStatics struct {
+	// Followers 粉丝数
+	Followers int64
+	// Followees 关注了多少人
+	Followees int64
+}
@@ -0,0 +1,13 @@
+package domain
+
+// Interactive 某个业务资源（比如文章）的互动数据
+type Interactive struct {
+	Biz        string
+	BizId      int64
+	ReadCnt    int64
+	LikeCnt    int64
+	CollectCnt int64
+	// 下面两个字段和具体的用户相关
+	Liked     bool
+	Collected bool
+}
@@ -0,0 +1,31 @@
+package domain
+
+import (
+	"github.com/robfig/cron/v3"
+	"time"
+)
+
+// Job 存储在数据库里面的定时任务
+type Job struct {
+	Id   int64
+	Name string
+	// Executor 用哪个执行器执行
+	Executor string
+	// Cfg 执行器需要的配置，具体格式由执行器决定
+	Cfg string
+	// Expression cron 表达式，精确到秒
+	Expression string
+	NextTime   time.Time
+}
+
+var jobParser = cron.NewParser(cron.Second | cron.Minute | cron.Hour |
+	cron.Dom | cron.Month | cron.Dow | cron.Descriptor)
+
+// Next 计算 t 之后的下一次执行时间
+func (j Job) Next(t time.Time) (time.Time, error) {
+	s, err := jobParser.Parse(j.Expression)
+	if err != nil {
+		return time.Time{}, err
+	}
+	return s.Next(t), nil
+}
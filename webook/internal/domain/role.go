@@ -0,0 +1,70 @@
+package domain
+
+// Role 用户的角色，普通用户没有角色
+type Role string
+
+const (
+	// RoleModerator 版主，可以处理违规的用户和文章
+	RoleModerator Role = "moderator"
+	// RoleAdmin 管理员，什么都能做
+	RoleAdmin Role = "admin"
+)
+
+// Permission 权限，放在 access token 里面，接口按照权限来校验
+type Permission string
+
+const (
+	PermissionUserList        Permission = "user:list"
+	PermissionUserBan         Permission = "user:ban"
+	PermissionArticleWithdraw Permission = "article:withdraw"
+)
+
+var rolePermissions = map[Role][]Permission{
+	RoleModerator: {
+		PermissionUserList,
+		PermissionUserBan,
+		PermissionArticleWithdraw,
+	},
+	RoleAdmin: {
+		PermissionUserList,
+		PermissionUserBan,
+		PermissionArticleWithdraw,
+	},
+}
+
+// roleRanks 只能处理级别比自己低的用户，普通用户是 0
+var roleRanks = map[Role]int{
+	RoleModerator: 1,
+	RoleAdmin:     2,
+}
+
+// Rank 多个角色取最高的级别
+func Rank(roles []Role) int {
+	res := 0
+	for _, r := range roles {
+		res = max(res, roleRanks[r])
+	}
+	return res
+}
+
+// Valid 不认识的角色忽略掉，比如说数据库里面还留着已经下线的角色
+func (r Role) Valid() bool {
+	_, ok := rolePermissions[r]
+	return ok
+}
+
+// Permissions 多个角色的权限合在一起，去掉重复的
+func Permissions(roles []Role) []Permission {
+	var res []Permission
+	seen := make(map[Permission]struct{})
+	for _, r := range roles {
+		for _, p := range rolePermissions[r] {
+			if _, ok := seen[p]; ok {
+				continue
+			}
+			seen[p] = struct{}{}
+			res = append(res, p)
+		}
+	}
+	return res
+}
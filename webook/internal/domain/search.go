@@ -0,0 +1,13 @@
+package domain
+
+import "time"
+
+// SearchArticle 文章的搜索结果，Title 和 Abstract 里面命中的部分已经高亮了
+type SearchArticle struct {
+	Id       int64
+	Title    string
+	Abstract string
+	Author   Author
+	Utime    time.Time
+	Score    float64
+}
@@ -8,7 +8,50 @@ type User struct {
 	Nickname string
 	Phone    string
 	Password string
+	// Birthday 零值表示没有填
+	Birthday time.Time
+	AboutMe  string
+	Status   UserStatus
 
 	WechatInfo WechatInfo
 	Ctime      time.Time
 }
+
+// LoginMethods 用户现在可以用哪些方式登录，邮箱要有密码才能登录
+func (u User) LoginMethods() []LoginMethod {
+	var res []LoginMethod
+	if u.Email != "" && u.Password != "" {
+		res = append(res, LoginMethodEmail)
+	}
+	if u.Phone != "" {
+		res = append(res, LoginMethodPhone)
+	}
+	if u.WechatInfo.OpenID != "" {
+		res = append(res, LoginMethodWechat)
+	}
+	return res
+}
+
+type LoginMethod string
+
+const (
+	LoginMethodEmail  LoginMethod = "email"
+	LoginMethodPhone  LoginMethod = "phone"
+	LoginMethodWechat LoginMethod = "wechat"
+)
+
+type UserStatus uint8
+
+// DeactivatedNickname 注销之后的昵称，别人看到的作者和评论者都是这个
+const DeactivatedNickname = "已注销用户"
+
+const (
+	// UserStatusActive 零值，以前的用户都是这个状态
+	UserStatusActive UserStatus = iota
+	// UserStatusPending 邮箱注册之后还没有确认验证码，不能登录
+	UserStatusPending
+	// UserStatusDeactivated 已经注销，个人信息都清空了
+	UserStatusDeactivated
+	// UserStatusBanned 被管理员封禁
+	UserStatusBanned
+)
@@ -0,0 +1,25 @@
+package startup
+
+import (
+	"gitee.com/geekbang/basic-go/webook/internal/web/jwt"
+	"time"
+)
+
+func InitJWTConfig() jwt.Config {
+	return jwt.Config{
+		Access:  initTokenConfig("access", "moyn8y9abnd7q4zkq2m73yw8tu9j5ixm", time.Minute*30),
+		Refresh: initTokenConfig("refresh", "q3vx7k2m9ptw4zr8ybn6hd5jc1fa0ues", time.Hour*24*7),
+	}
+}
+
+func initTokenConfig(kid string, secret string, expiration time.Duration) jwt.TokenConfig {
+	key, err := jwt.NewHMACKey(kid, secret)
+	if err != nil {
+		panic(err)
+	}
+	ks, err := jwt.NewKeySet(kid, key)
+	if err != nil {
+		panic(err)
+	}
+	return jwt.TokenConfig{Keys: ks, Expiration: expiration}
+}
@@ -7,6 +7,10 @@ import (
 	"gitee.com/geekbang/basic-go/webook/internal/repository/cache"
 	"gitee.com/geekbang/basic-go/webook/internal/repository/dao"
 	"gitee.com/geekbang/basic-go/webook/internal/repository/dao/article"
+	"gitee.com/geekbang/basic-go/webook/internal/repository/dao/comment"
+	"gitee.com/geekbang/basic-go/webook/internal/repository/dao/feed"
+	"gitee.com/geekbang/basic-go/webook/internal/repository/dao/follow"
+	"gitee.com/geekbang/basic-go/webook/internal/repository/dao/interactive"
 	"gitee.com/geekbang/basic-go/webook/internal/service"
 	"gitee.com/geekbang/basic-go/webook/internal/web"
 	"gitee.com/geekbang/basic-go/webook/internal/web/jwt"
@@ -15,7 +19,7 @@ import (
 	"github.com/google/wire"
 )
 
-var thirdProvider = wire.NewSet(InitDB, InitRedis, ioc.InitLogger, jwt.NewJWTHandler)
+var thirdProvider = wire.NewSet(InitDB, InitRedis, ioc.InitLogger, InitJWTConfig, jwt.NewJWTHandler)
 var userSvcProvider = wire.NewSet(
 	dao.NewUserDaoGorm,
 	cache.NewRedisUserCache,
@@ -29,8 +33,47 @@ var articleSvcProvider = wire.NewSet(
 	cache.NewRedisArticleCache,
 )
 
+var interactiveSvcProvider = wire.NewSet(
+	service.NewInteractiveService,
+	repository.NewInteractiveRepository,
+	interactive.NewInteractiveDaoGORM,
+	cache.NewRedisInteractiveCache,
+)
+
+var rankingSvcProvider = wire.NewSet(
+	service.NewBatchRankingService,
+	repository.NewCachedRankingRepository,
+	cache.NewRankingRedisCache,
+	cache.NewRankingLocalCache,
+)
+
+var commentSvcProvider = wire.NewSet(
+	service.NewCommentService,
+	repository.NewCommentRepository,
+	comment.NewCommentDaoGORM,
+	cache.NewRedisCommentCache,
+)
+
+var followSvcProvider = wire.NewSet(
+	service.NewFollowService,
+	repository.NewFollowRepository,
+	follow.NewFollowDaoGORM,
+	cache.NewRedisFollowCache,
+)
+
+var feedSvcProvider = wire.NewSet(
+	service.NewFeedService,
+	repository.NewFeedRepository,
+	feed.NewFeedDaoGORM,
+)
+
+var searchSvcProvider = wire.NewSet(
+	service.NewSearchService,
+	ioc.InitSearchRepository,
+)
+
 var codeSvcProvider = wire.NewSet(
-	ioc.InitSMSService, cache.NewCodeCacheImpl,
+	ioc.InitSMSService, ioc.InitEmailService, cache.NewCodeCacheImpl,
 	repository.NewCodeRepoImpl,
 	service.NewCodeServiceImpl,
 )
@@ -53,8 +96,32 @@ func InitWebServer() *gin.Engine {
 
 		// article
 		articleSvcProvider,
+		interactiveSvcProvider,
+		rankingSvcProvider,
 		web.NewArticleHandler,
 
+		// comment
+		commentSvcProvider,
+		web.NewCommentHandler,
+
+		// follow
+		followSvcProvider,
+		web.NewFollowHandler,
+
+		// feed
+		feedSvcProvider,
+		web.NewFeedHandler,
+
+		// search
+		searchSvcProvider,
+		web.NewSearchHandler,
+
+		// admin
+		web.NewAdminHandler,
+
+		// jwks
+		web.NewJWKSHandler,
+
 		// web
 		ioc.InitMiddlewares,
 		ioc.InitWebServer,
@@ -66,6 +133,12 @@ func InitArticleHandler() *web.ArticleHandler {
 	wire.Build(
 		thirdProvider,
 		articleSvcProvider,
+		interactiveSvcProvider,
+		rankingSvcProvider,
+		searchSvcProvider,
+		commentSvcProvider,
+		followSvcProvider,
+		feedSvcProvider,
 		userSvcProvider,
 		web.NewArticleHandler,
 	)
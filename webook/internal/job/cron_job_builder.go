@@ -0,0 +1,37 @@
+package job
+
+import (
+	"gitee.com/geekbang/basic-go/webook/pkg/logger"
+	"github.com/robfig/cron/v3"
+	"time"
+)
+
+// CronJobBuilder 把 Job 转换成 cron.Job，顺便加上日志
+type CronJobBuilder struct {
+	l logger.Logger
+}
+
+func NewCronJobBuilder(l logger.Logger) *CronJobBuilder {
+	return &CronJobBuilder{l: l}
+}
+
+func (b *CronJobBuilder) Build(job Job) cron.Job {
+	name := job.Name()
+	return cronJobAdapterFunc(func() {
+		start := time.Now()
+		b.l.Debug("开始运行任务", logger.String("name", name))
+		err := job.Run()
+		if err != nil {
+			// 任务失败了，上一次成功的结果还在，下一次调度再试
+			b.l.Error("运行任务失败", logger.String("name", name), logger.Error(err))
+		}
+		b.l.Debug("结束运行任务", logger.String("name", name),
+			logger.Int64("duration_ms", time.Since(start).Milliseconds()))
+	})
+}
+
+type cronJobAdapterFunc func()
+
+func (c cronJobAdapterFunc) Run() {
+	c()
+}
@@ -0,0 +1,48 @@
+package job
+
+import (
+	"context"
+	"fmt"
+	"gitee.com/geekbang/basic-go/webook/internal/domain"
+)
+
+// Executor 执行数据库里面定义的任务
+type Executor interface {
+	Name() string
+	// Exec ctx 被取消的时候要尽快返回，比如说任务被别的实例抢走了
+	Exec(ctx context.Context, j domain.Job) error
+}
+
+// LocalFuncExecutor 在本地调用注册好的方法，按照 domain.Job 的 Name 查找
+type LocalFuncExecutor struct {
+	funcs map[string]func(ctx context.Context, j domain.Job) error
+}
+
+func NewLocalFuncExecutor() *LocalFuncExecutor {
+	return &LocalFuncExecutor{
+		funcs: make(map[string]func(ctx context.Context, j domain.Job) error),
+	}
+}
+
+func (l *LocalFuncExecutor) Name() string {
+	return "local"
+}
+
+func (l *LocalFuncExecutor) RegisterFunc(name string, fn func(ctx context.Context, j domain.Job) error) {
+	l.funcs[name] = fn
+}
+
+// RegisterJob 把普通的 Job 注册进来，Job 自己控制超时
+func (l *LocalFuncExecutor) RegisterJob(job Job) {
+	l.funcs[job.Name()] = func(ctx context.Context, j domain.Job) error {
+		return job.Run()
+	}
+}
+
+func (l *LocalFuncExecutor) Exec(ctx context.Context, j domain.Job) error {
+	fn, ok := l.funcs[j.Name]
+	if !ok {
+		return fmt.Errorf("未注册本地方法 %s", j.Name)
+	}
+	return fn(ctx, j)
+}
@@ -0,0 +1,48 @@
+package job
+
+import (
+	"context"
+	"gitee.com/geekbang/basic-go/webook/internal/domain"
+	"gitee.com/geekbang/basic-go/webook/internal/service"
+	"gitee.com/geekbang/basic-go/webook/pkg/logger"
+	"time"
+)
+
+// PublishJob 发表到点了的定时文章
+// 由 Scheduler 调度，同一时刻只有抢占到任务的实例在执行
+type PublishJob struct {
+	svc service.ArticleService
+	// 每一批处理多少篇
+	batchSize int
+	l         logger.Logger
+}
+
+func NewPublishJob(svc service.ArticleService, l logger.Logger) *PublishJob {
+	return &PublishJob{
+		svc:       svc,
+		batchSize: 100,
+		l:         l,
+	}
+}
+
+func (p *PublishJob) Name() string {
+	return "scheduled_publish"
+}
+
+// Exec 可以直接注册到 LocalFuncExecutor 上
+func (p *PublishJob) Exec(ctx context.Context, _ domain.Job) error {
+	now := time.Now()
+	for {
+		cnt, err := p.svc.PublishScheduled(ctx, now, p.batchSize)
+		if err != nil {
+			return err
+		}
+		if cnt > 0 {
+			p.l.Info("定时发表文章", logger.Int64("cnt", int64(cnt)))
+		}
+		// 没有成功发表的就不再继续了，避免一直失败的文章导致死循环
+		if cnt < p.batchSize {
+			return nil
+		}
+	}
+}
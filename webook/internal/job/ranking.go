@@ -0,0 +1,104 @@
+package job
+
+import (
+	"context"
+	"gitee.com/geekbang/basic-go/webook/internal/service"
+	"gitee.com/geekbang/basic-go/webook/pkg/logger"
+	"gitee.com/geekbang/basic-go/webook/pkg/utils/lock"
+	"sync"
+	"time"
+)
+
+var _ Job = (*RankingJob)(nil)
+
+// RankingJob 定时计算热榜
+// 多个实例只有拿到分布式锁的那个会真的去算
+type RankingJob struct {
+	svc     service.RankingService
+	timeout time.Duration
+	client  *lock.Client
+	key     string
+	l       logger.Logger
+
+	localLock sync.Mutex
+	lock      *lock.Lock
+}
+
+func NewRankingJob(svc service.RankingService, client *lock.Client,
+	l logger.Logger, timeout time.Duration) *RankingJob {
+	return &RankingJob{
+		svc:     svc,
+		timeout: timeout,
+		client:  client,
+		key:     "job:ranking",
+		l:       l,
+	}
+}
+
+func (r *RankingJob) Name() string {
+	return "ranking"
+}
+
+func (r *RankingJob) Run() error {
+	r.localLock.Lock()
+	defer r.localLock.Unlock()
+	if r.lock == nil {
+		ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+		defer cancel()
+		// 拿到锁之后一直持有，这样热榜会一直由同一个实例来计算
+		lk, err := r.client.TryLock(ctx, r.key, r.timeout)
+		if err == lock.ErrFailedToPreemptLock {
+			// 别的实例在算
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		r.lock = lk
+		go func() {
+			// 续约失败说明锁丢了，下一次重新抢
+			er := lk.AutoRefresh(r.timeout/2, time.Second)
+			if er != nil {
+				r.l.Warn("热榜任务的分布式锁续约失败", logger.Error(er))
+			}
+			r.localLock.Lock()
+			if r.lock == lk {
+				r.lock = nil
+			}
+			r.localLock.Unlock()
+		}()
+	}
+	lk := r.lock
+	select {
+	case <-lk.Lost():
+		// 锁已经丢了，别的实例可能已经在算了
+		r.lock = nil
+		return nil
+	default:
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), r.timeout)
+	defer cancel()
+	// 算到一半锁丢了就停下来，不然可能覆盖掉别的实例的结果
+	go func() {
+		select {
+		case <-lk.Lost():
+			cancel()
+		case <-ctx.Done():
+		}
+	}()
+	return r.svc.TopN(ctx)
+}
+
+// Close 释放分布式锁，让别的实例可以马上接手。停止 cron 之后调用
+func (r *RankingJob) Close() error {
+	r.localLock.Lock()
+	defer r.localLock.Unlock()
+	if r.lock == nil {
+		return nil
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	err := r.lock.Unlock(ctx)
+	r.lock = nil
+	return err
+}
@@ -0,0 +1,123 @@
+package job
+
+import (
+	"context"
+	"gitee.com/geekbang/basic-go/webook/internal/domain"
+	"gitee.com/geekbang/basic-go/webook/internal/service"
+	"gitee.com/geekbang/basic-go/webook/pkg/logger"
+	"time"
+)
+
+// Scheduler 从数据库里面抢占任务来执行
+// 多个实例同时运行的时候，一个任务同一时刻只会被一个实例执行
+type Scheduler struct {
+	svc   service.CronJobService
+	execs map[string]Executor
+	l     logger.Logger
+	// 没有任务的时候隔多久再抢
+	interval  time.Duration
+	dbTimeout time.Duration
+	// 控制同时运行的任务数量
+	limiter chan struct{}
+}
+
+func NewScheduler(svc service.CronJobService, l logger.Logger) *Scheduler {
+	return &Scheduler{
+		svc:       svc,
+		execs:     make(map[string]Executor),
+		l:         l,
+		interval:  time.Second,
+		dbTimeout: time.Second,
+		limiter:   make(chan struct{}, 100),
+	}
+}
+
+func (s *Scheduler) RegisterExecutor(exec Executor) {
+	s.execs[exec.Name()] = exec
+}
+
+// Schedule 会一直阻塞，直到 ctx 被取消
+func (s *Scheduler) Schedule(ctx context.Context) error {
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case s.limiter <- struct{}{}:
+		}
+
+		dbCtx, cancel := context.WithTimeout(ctx, s.dbTimeout)
+		j, err := s.svc.Preempt(dbCtx)
+		cancel()
+		if err != nil {
+			<-s.limiter
+			if err != service.ErrNoJobToPreempt {
+				s.l.Error("抢占任务失败", logger.Error(err))
+			}
+			select {
+			case <-ctx.Done():
+				return ctx.Err()
+			case <-time.After(s.interval):
+			}
+			continue
+		}
+
+		go func() {
+			defer func() {
+				<-s.limiter
+			}()
+			s.run(ctx, j)
+		}()
+	}
+}
+
+func (s *Scheduler) run(ctx context.Context, j domain.Job) {
+	execCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	go s.heartbeat(execCtx, cancel, j)
+
+	exec, ok := s.execs[j.Executor]
+	if !ok {
+		s.l.Error("找不到执行器", logger.Int64("jid", j.Id),
+			logger.String("executor", j.Executor))
+	} else if err := exec.Exec(execCtx, j); err != nil {
+		// 执行失败也照常计算下一次时间，等下一次调度
+		s.l.Error("执行任务失败", logger.Int64("jid", j.Id),
+			logger.String("name", j.Name), logger.Error(err))
+	}
+	if execCtx.Err() != nil && ctx.Err() == nil {
+		// 任务已经被别人抢走了，不要再去释放
+		return
+	}
+
+	dbCtx, dbCancel := context.WithTimeout(context.Background(), s.dbTimeout)
+	defer dbCancel()
+	if err := s.svc.Release(dbCtx, j); err != nil {
+		s.l.Error("释放任务失败", logger.Int64("jid", j.Id), logger.Error(err))
+	}
+}
+
+// heartbeat 续约，发现任务被抢走了就通过 cancel 通知执行器停下来
+func (s *Scheduler) heartbeat(ctx context.Context, cancel context.CancelFunc, j domain.Job) {
+	ticker := time.NewTicker(s.svc.HeartbeatInterval())
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+		dbCtx, dbCancel := context.WithTimeout(ctx, s.dbTimeout)
+		err := s.svc.Heartbeat(dbCtx, j)
+		dbCancel()
+		switch err {
+		case nil:
+		case service.ErrJobNotHold:
+			s.l.Warn("任务被别的实例抢走了", logger.Int64("jid", j.Id))
+			cancel()
+			return
+		default:
+			// 偶尔失败没关系，只要在超时之前续约成功就可以
+			s.l.Error("任务续约失败", logger.Int64("jid", j.Id), logger.Error(err))
+		}
+	}
+}
@@ -0,0 +1,7 @@
+package job
+
+// Job 后台任务的抽象
+type Job interface {
+	Name() string
+	Run() error
+}
@@ -2,6 +2,7 @@ package repository
 
 import (
 	"context"
+	"errors"
 	"gitee.com/geekbang/basic-go/webook/internal/domain"
 	"gitee.com/geekbang/basic-go/webook/internal/repository/cache"
 	"gitee.com/geekbang/basic-go/webook/internal/repository/dao/article"
@@ -12,8 +13,10 @@ import (
 var (
 	ErrArticleDuplicate        = article.ErrArticleDuplicate
 	ErrPossibleIncorrectAuthor = article.ErrPossibleIncorrectAuthor
+	ErrArticleNotFound         = article.ErrRecordNotFound
 )
 
+//go:generate mockgen -source=$GOFILE -destination=mocks/mock_$GOFILE --package=$GOPACKAGEmocks
 type ArticleRepository interface {
 	Create(ctx context.Context, art domain.Article) (int64, error)
 	Update(ctx context.Context, art domain.Article) error
@@ -22,6 +25,28 @@ type ArticleRepository interface {
 	List(ctx context.Context, uid int64, offset int, limit int) ([]domain.Article, error)
 	GetById(ctx context.Context, id int64) (domain.Article, error)
 	GetPubById(ctx context.Context, id int64) (domain.Article, error)
+	// ListPub 读者看的文章列表，只返回摘要，不返回全文
+	ListPub(ctx context.Context, utime time.Time, id int64, limit int) ([]domain.Article, error)
+	ListRevisions(ctx context.Context, uid int64, artId int64, offset int, limit int) ([]domain.ArticleRevision, error)
+	GetRevision(ctx context.Context, id int64) (domain.ArticleRevision, error)
+	ListScheduled(ctx context.Context, before time.Time, limit int) ([]domain.Article, error)
+	TransitStatus(ctx context.Context, art domain.Article, from domain.ArticleStatus, to domain.ArticleStatus) (bool, error)
+	CancelSchedule(ctx context.Context, uid int64, id int64) error
+	Delete(ctx context.Context, uid int64, id int64) error
+	// Recover 只能恢复 deadline 之后删除的文章
+	Recover(ctx context.Context, uid int64, id int64, deadline time.Time) error
+	ListDeleted(ctx context.Context, uid int64, deadline time.Time, offset int, limit int) ([]domain.Article, error)
+	// ListByTag 标签下面已经发表的文章，只返回摘要
+	ListByTag(ctx context.Context, tag string, offset int, limit int) ([]domain.Article, error)
+	PopularTags(ctx context.Context, n int) ([]domain.Tag, error)
+	// DelAuthorCache 作者信息变了之后，删除缓存里面带有作者信息的文章
+	DelAuthorCache(ctx context.Context, author int64) error
+	// WithdrawByAuthor 撤回作者已经发表和定时发表的文章，比如说注销账号的时候
+	WithdrawByAuthor(ctx context.Context, author int64) error
+	// ListAllByAuthor 作者所有的文章，包括全文，不走缓存
+	ListAllByAuthor(ctx context.Context, author int64) ([]domain.Article, error)
+	// ForceWithdraw 不校验作者，把文章改成仅自己可见
+	ForceWithdraw(ctx context.Context, id int64) error
 }
 
 type articleRepository struct {
@@ -29,6 +54,7 @@ type articleRepository struct {
 	cache    cache.ArticleCache
 	l        logger.Logger
 	userRepo UserRepo
+	search   SearchRepository
 }
 
 func (repo *articleRepository) GetPubById(ctx context.Context, id int64) (domain.Article, error) {
@@ -55,6 +81,7 @@ func (repo *articleRepository) GetPubById(ctx context.Context, id int64) (domain
 			Name: user.Nickname,
 		},
 	}
+	res.Tags = repo.getTags(ctx, repo.dao.GetPubTags, res.Id)
 	// 也可以同步
 	go func() {
 		if err = repo.cache.SetPub(ctx, res); err != nil {
@@ -65,6 +92,245 @@ func (repo *articleRepository) GetPubById(ctx context.Context, id int64) (domain
 	return res, nil
 }
 
+func (repo *articleRepository) ListPub(ctx context.Context, utime time.Time, id int64, limit int) ([]domain.Article, error) {
+	var cursor int64
+	if !utime.IsZero() {
+		cursor = utime.UnixMilli()
+	}
+	data, err := repo.dao.ListPub(ctx, domain.ArticleStatusPublished.ToUint8(), cursor, id, limit)
+	if err != nil {
+		return nil, err
+	}
+	return repo.toPubAbstracts(ctx, data), nil
+}
+
+// toPubAbstracts 转成读者看的列表，只保留摘要，并且查询作者的名字
+func (repo *articleRepository) toPubAbstracts(ctx context.Context, data []article.PublishedArticle) []domain.Article {
+	// 同一页里面同一个作者可能出现多次，只查一次
+	authors := make(map[int64]domain.Author, len(data))
+	res := make([]domain.Article, 0, len(data))
+	for _, pub := range data {
+		art := repo.toDomain(article.Article(pub))
+		art.Content = art.Abstract()
+		author, ok := authors[art.Author.Id]
+		if !ok {
+			user, er := repo.userRepo.FindById(ctx, art.Author.Id)
+			if er != nil {
+				// 作者名字拿不到，文章照样展示
+				repo.l.Error("查询文章作者失败", logger.Error(er),
+					logger.Int64("uid", art.Author.Id))
+			}
+			author = domain.Author{Id: art.Author.Id, Name: user.Nickname}
+			authors[art.Author.Id] = author
+		}
+		art.Author = author
+		res = append(res, art)
+	}
+	return res
+}
+
+func (repo *articleRepository) ListByTag(ctx context.Context, tag string, offset int, limit int) ([]domain.Article, error) {
+	// 和创作者的列表一样，只缓存第一页
+	if offset == 0 && limit == 100 {
+		data, err := repo.cache.GetTagFirstPage(ctx, tag)
+		if err == nil {
+			return data, nil
+		}
+	}
+	data, err := repo.dao.ListByTag(ctx, tag, domain.ArticleStatusPublished.ToUint8(), offset, limit)
+	if err != nil {
+		return nil, err
+	}
+	res := repo.toPubAbstracts(ctx, data)
+	repo.fillTags(ctx, repo.dao.GetPubTags, res)
+	if offset == 0 && limit == 100 {
+		go func() {
+			ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+			defer cancel()
+			if er := repo.cache.SetTagFirstPage(ctx, tag, res); er != nil {
+				repo.l.Error("设置标签第一页缓存失败", logger.Error(er))
+			}
+		}()
+	}
+	return res, nil
+}
+
+func (repo *articleRepository) PopularTags(ctx context.Context, n int) ([]domain.Tag, error) {
+	res, err := repo.cache.GetPopularTags(ctx)
+	// 缓存里面的数量不够，就查数据库
+	if err == nil && len(res) >= n {
+		return res[:n], nil
+	}
+	data, err := repo.dao.PopularTags(ctx, domain.ArticleStatusPublished.ToUint8(), n)
+	if err != nil {
+		return nil, err
+	}
+	res = make([]domain.Tag, 0, len(data))
+	for _, tc := range data {
+		res = append(res, domain.Tag{Name: tc.Name, ArticleCnt: tc.Cnt})
+	}
+	if er := repo.cache.SetPopularTags(ctx, res); er != nil {
+		repo.l.Error("设置热门标签缓存失败", logger.Error(er))
+	}
+	return res, nil
+}
+
+// syncSearch 线上库变了之后，按照线上库最新的数据更新搜索索引
+// 只有已发表并且没有删除的文章才能被搜到，失败了只记录日志
+func (repo *articleRepository) syncSearch(ctx context.Context, id int64) {
+	pub, err := repo.dao.GetPubById(ctx, id)
+	switch {
+	case err == nil && pub.Status == domain.ArticleStatusPublished.ToUint8():
+		err = repo.search.InputArticle(ctx, repo.toDomain(article.Article(pub)))
+	case err == nil || errors.Is(err, article.ErrRecordNotFound):
+		err = repo.search.DeleteArticle(ctx, id)
+	}
+	if err != nil {
+		repo.l.Error("更新搜索索引失败", logger.Int64("aid", id), logger.Error(err))
+	}
+}
+
+// tagsFunc 是 dao.GetTags 或者 dao.GetPubTags，作者看到的是制作库的标签，读者和标签页用线上的标签
+type tagsFunc func(ctx context.Context, artIds []int64) (map[int64][]string, error)
+
+// getTags 查询单篇文章的标签，查询失败只记录日志
+func (repo *articleRepository) getTags(ctx context.Context, get tagsFunc, id int64) []string {
+	tags, err := get(ctx, []int64{id})
+	if err != nil {
+		repo.l.Error("查询文章标签失败", logger.Int64("aid", id), logger.Error(err))
+		return nil
+	}
+	return tags[id]
+}
+
+// fillTags 批量查询并填充文章的标签，查询失败只记录日志
+func (repo *articleRepository) fillTags(ctx context.Context, get tagsFunc, arts []domain.Article) {
+	ids := make([]int64, 0, len(arts))
+	for _, art := range arts {
+		ids = append(ids, art.Id)
+	}
+	tags, err := get(ctx, ids)
+	if err != nil {
+		repo.l.Error("查询文章标签失败", logger.Error(err))
+		return
+	}
+	for i := range arts {
+		arts[i].Tags = tags[arts[i].Id]
+	}
+}
+
+// delTagFirstPage 文章的标签下面的第一页缓存都要删除
+// 修改标签的时候，被去掉的标签的缓存只能等过期
+func (repo *articleRepository) delTagFirstPage(ctx context.Context, tags []string) {
+	if err := repo.cache.DelTagFirstPage(ctx, tags...); err != nil {
+		repo.l.Error("删除标签第一页缓存失败", logger.Error(err))
+	}
+}
+
+func (repo *articleRepository) ListScheduled(ctx context.Context, before time.Time, limit int) ([]domain.Article, error) {
+	data, err := repo.dao.ListScheduled(ctx, domain.ArticleStatusScheduled.ToUint8(), before.UnixMilli(), limit)
+	if err != nil {
+		return nil, err
+	}
+	res := make([]domain.Article, 0, len(data))
+	for _, art := range data {
+		res = append(res, repo.toDomain(art))
+	}
+	return res, nil
+}
+
+func (repo *articleRepository) TransitStatus(ctx context.Context, art domain.Article,
+	from domain.ArticleStatus, to domain.ArticleStatus) (bool, error) {
+	ok, err := repo.dao.TransitStatus(ctx, art.Id, from.ToUint8(), to.ToUint8())
+	if err != nil || !ok {
+		return ok, err
+	}
+	if err = repo.cache.DelFirstPage(ctx, art.Author.Id); err != nil {
+		repo.l.Error("删除缓存失败",
+			logger.Int64("author", art.Author.Id), logger.Error(err))
+	}
+	return true, nil
+}
+
+func (repo *articleRepository) CancelSchedule(ctx context.Context, uid int64, id int64) error {
+	err := repo.dao.CancelSchedule(ctx, uid, id,
+		domain.ArticleStatusScheduled.ToUint8(), domain.ArticleStatusUnpublished.ToUint8())
+	if err != nil {
+		return err
+	}
+	if err = repo.cache.DelFirstPage(ctx, uid); err != nil {
+		repo.l.Error("删除缓存失败",
+			logger.Int64("author", uid), logger.Error(err))
+	}
+	return nil
+}
+
+func (repo *articleRepository) Delete(ctx context.Context, uid int64, id int64) error {
+	err := repo.dao.Delete(ctx, uid, id)
+	if err != nil {
+		return err
+	}
+	repo.syncSearch(ctx, id)
+	repo.delCache(ctx, uid, id)
+	return nil
+}
+
+func (repo *articleRepository) Recover(ctx context.Context, uid int64, id int64, deadline time.Time) error {
+	err := repo.dao.Recover(ctx, uid, id, deadline.UnixMilli())
+	if err != nil {
+		return err
+	}
+	repo.syncSearch(ctx, id)
+	// 删除期间可能缓存了查不到的结果，所以恢复的时候也要删
+	repo.delCache(ctx, uid, id)
+	return nil
+}
+
+func (repo *articleRepository) delCache(ctx context.Context, uid int64, id int64) {
+	if err := repo.cache.Del(ctx, id); err != nil {
+		repo.l.Error("删除文章缓存失败",
+			logger.Int64("aid", id), logger.Error(err))
+	}
+	if err := repo.cache.DelFirstPage(ctx, uid); err != nil {
+		repo.l.Error("删除第一页缓存失败",
+			logger.Int64("author", uid), logger.Error(err))
+	}
+	repo.delTagFirstPage(ctx, repo.getTags(ctx, repo.dao.GetPubTags, id))
+}
+
+func (repo *articleRepository) ListDeleted(ctx context.Context, uid int64, deadline time.Time,
+	offset int, limit int) ([]domain.Article, error) {
+	data, err := repo.dao.ListDeleted(ctx, uid, deadline.UnixMilli(), offset, limit)
+	if err != nil {
+		return nil, err
+	}
+	res := make([]domain.Article, 0, len(data))
+	for _, art := range data {
+		res = append(res, repo.toDomain(art))
+	}
+	return res, nil
+}
+
+func (repo *articleRepository) ListRevisions(ctx context.Context, uid int64, artId int64, offset int, limit int) ([]domain.ArticleRevision, error) {
+	data, err := repo.dao.ListRevisions(ctx, uid, artId, offset, limit)
+	if err != nil {
+		return nil, err
+	}
+	res := make([]domain.ArticleRevision, 0, len(data))
+	for _, rev := range data {
+		res = append(res, repo.revisionToDomain(rev))
+	}
+	return res, nil
+}
+
+func (repo *articleRepository) GetRevision(ctx context.Context, id int64) (domain.ArticleRevision, error) {
+	rev, err := repo.dao.GetRevision(ctx, id)
+	if err != nil {
+		return domain.ArticleRevision{}, err
+	}
+	return repo.revisionToDomain(rev), nil
+}
+
 func (repo *articleRepository) GetById(ctx context.Context, id int64) (domain.Article, error) {
 	cachedArt, err := repo.cache.Get(ctx, id)
 	if err == nil {
@@ -74,7 +340,9 @@ func (repo *articleRepository) GetById(ctx context.Context, id int64) (domain.Ar
 	if err != nil {
 		return domain.Article{}, err
 	}
-	return repo.toDomain(art), nil
+	res := repo.toDomain(art)
+	res.Tags = repo.getTags(ctx, repo.dao.GetTags, id)
+	return res, nil
 }
 
 func (repo *articleRepository) List(ctx context.Context, uid int64, offset int, limit int) ([]domain.Article, error) {
@@ -93,6 +361,7 @@ func (repo *articleRepository) List(ctx context.Context, uid int64, offset int,
 	for _, art := range data {
 		res = append(res, repo.toDomain(art))
 	}
+	repo.fillTags(ctx, repo.dao.GetTags, res)
 	go func() {
 		ctx, cancel := context.WithTimeout(context.Background(), time.Second)
 		defer cancel()
@@ -111,7 +380,13 @@ func (repo *articleRepository) List(ctx context.Context, uid int64, offset int,
 }
 
 func (repo *articleRepository) SyncStatus(ctx context.Context, uid int64, id int64, status domain.ArticleStatus) (int64, error) {
-	return repo.dao.SyncStatus(ctx, uid, id, status.ToUint8())
+	res, err := repo.dao.SyncStatus(ctx, uid, id, status.ToUint8())
+	if err != nil {
+		return res, err
+	}
+	repo.syncSearch(ctx, id)
+	repo.delTagFirstPage(ctx, repo.getTags(ctx, repo.dao.GetPubTags, id))
+	return res, nil
 }
 
 func (repo *articleRepository) Sync(ctx context.Context, art domain.Article) (int64, error) {
@@ -119,13 +394,20 @@ func (repo *articleRepository) Sync(ctx context.Context, art domain.Article) (in
 	if err != nil {
 		return 0, err
 	}
+	repo.syncSearch(ctx, id)
 	go func() {
+		art.Id = id
 		author := art.Author.Id
 		err = repo.cache.DelFirstPage(ctx, author)
 		if err != nil {
 			repo.l.Error("删除第一页缓存失败",
 				logger.Int64("author", author), logger.Error(err))
 		}
+		if art.Tags == nil {
+			// 没有修改标签，用原本的
+			art.Tags = repo.getTags(ctx, repo.dao.GetPubTags, id)
+		}
+		repo.delTagFirstPage(ctx, art.Tags)
 		user, err := repo.userRepo.FindById(ctx, author)
 		if err != nil {
 			repo.l.Error("提前设置缓存准备用户信息失败",
@@ -173,16 +455,26 @@ func (repo *articleRepository) Create(ctx context.Context, art domain.Article) (
 }
 
 func (repo *articleRepository) toEntity(art domain.Article) article.Article {
+	var publishTime int64
+	if !art.PublishTime.IsZero() {
+		publishTime = art.PublishTime.UnixMilli()
+	}
 	return article.Article{
-		ID:       art.Id,
-		Title:    art.Title,
-		Content:  art.Content,
-		AuthorID: art.Author.Id,
-		Status:   art.Status.ToUint8(),
+		ID:          art.Id,
+		Title:       art.Title,
+		Content:     art.Content,
+		AuthorID:    art.Author.Id,
+		Status:      art.Status.ToUint8(),
+		PublishTime: publishTime,
+		Tags:        art.Tags,
 	}
 }
 
 func (repo *articleRepository) toDomain(art article.Article) domain.Article {
+	var publishTime time.Time
+	if art.PublishTime > 0 {
+		publishTime = time.UnixMilli(art.PublishTime)
+	}
 	return domain.Article{
 		Id:      art.ID,
 		Title:   art.Title,
@@ -191,8 +483,21 @@ func (repo *articleRepository) toDomain(art article.Article) domain.Article {
 		Author: domain.Author{
 			Id: art.AuthorID,
 		},
-		Ctime: time.UnixMilli(art.Ctime),
-		Utime: time.UnixMilli(art.Utime),
+		Ctime:       time.UnixMilli(art.Ctime),
+		Utime:       time.UnixMilli(art.Utime),
+		PublishTime: publishTime,
+	}
+}
+
+func (repo *articleRepository) revisionToDomain(rev article.ArticleRevision) domain.ArticleRevision {
+	return domain.ArticleRevision{
+		Id:        rev.ID,
+		ArticleId: rev.ArticleID,
+		AuthorId:  rev.AuthorID,
+		Title:     rev.Title,
+		Content:   rev.Content,
+		Status:    domain.ArticleStatus(rev.Status),
+		Ctime:     time.UnixMilli(rev.Ctime),
 	}
 }
 
@@ -208,11 +513,107 @@ func (repo *articleRepository) preCache(ctx context.Context, arts []domain.Artic
 }
 
 func NewArticleRepository(dao article.ArticleDao, c cache.ArticleCache, log logger.Logger,
-	repo UserRepo) ArticleRepository {
+	repo UserRepo, search SearchRepository) ArticleRepository {
 	return &articleRepository{
 		dao:      dao,
 		cache:    c,
 		l:        log,
 		userRepo: repo,
+		search:   search,
 	}
 }
+
+func (repo *articleRepository) DelAuthorCache(ctx context.Context, author int64) error {
+	ids, err := repo.dao.ListPubIds(ctx, author)
+	if err != nil {
+		return err
+	}
+	if err = repo.cache.DelPub(ctx, ids...); err != nil {
+		return err
+	}
+	// 标签第一页里面也有作者名字
+	tags, err := repo.dao.GetPubTags(ctx, ids)
+	if err != nil {
+		return err
+	}
+	var allTags []string
+	for _, ts := range tags {
+		allTags = append(allTags, ts...)
+	}
+	if len(allTags) > 0 {
+		repo.delTagFirstPage(ctx, allTags)
+	}
+	return nil
+}
+
+func (repo *articleRepository) WithdrawByAuthor(ctx context.Context, author int64) error {
+	ids, err := repo.dao.WithdrawByAuthor(ctx, author,
+		domain.ArticleStatusPublished.ToUint8(),
+		domain.ArticleStatusScheduled.ToUint8(),
+		domain.ArticleStatusPrivate.ToUint8())
+	if err != nil {
+		return err
+	}
+	for _, id := range ids {
+		repo.syncSearch(ctx, id)
+		if er := repo.cache.Del(ctx, id); er != nil {
+			repo.l.Error("删除文章缓存失败",
+				logger.Int64("aid", id), logger.Error(er))
+		}
+	}
+	if er := repo.cache.DelFirstPage(ctx, author); er != nil {
+		repo.l.Error("删除第一页缓存失败",
+			logger.Int64("author", author), logger.Error(er))
+	}
+	tags, err := repo.dao.GetPubTags(ctx, ids)
+	if err != nil {
+		repo.l.Error("查询文章标签失败", logger.Error(err))
+		return nil
+	}
+	var allTags []string
+	for _, ts := range tags {
+		allTags = append(allTags, ts...)
+	}
+	if len(allTags) > 0 {
+		repo.delTagFirstPage(ctx, allTags)
+	}
+	return nil
+}
+
+func (repo *articleRepository) ListAllByAuthor(ctx context.Context, author int64) ([]domain.Article, error) {
+	const batchSize = 100
+	var res []domain.Article
+	for offset := 0; ; offset += batchSize {
+		data, err := repo.dao.GetByAuthor(ctx, author, offset, batchSize)
+		if err != nil {
+			return nil, err
+		}
+		for _, art := range data {
+			res = append(res, repo.toDomain(art))
+		}
+		if len(data) < batchSize {
+			break
+		}
+	}
+	repo.fillTags(ctx, repo.dao.GetTags, res)
+	return res, nil
+}
+
+func (repo *articleRepository) ForceWithdraw(ctx context.Context, id int64) error {
+	art, err := repo.dao.GetById(ctx, id)
+	if err != nil {
+		return err
+	}
+	_, err = repo.dao.SyncStatus(ctx, art.AuthorID, id, domain.ArticleStatusPrivate.ToUint8())
+	if err == article.ErrPossibleIncorrectAuthor {
+		// 作者是从数据库里面查出来的，那就只能是没有发表过
+		return ErrArticleNotFound
+	}
+	if err != nil {
+		return err
+	}
+	repo.syncSearch(ctx, id)
+	// 读者那边的缓存也要删掉，不然下架了还能看到
+	repo.delCache(ctx, art.AuthorID, id)
+	return nil
+}
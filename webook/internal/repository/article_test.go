@@ -0,0 +1,72 @@
+package repository
+
+import (
+	"context"
+	"gitee.com/geekbang/basic-go/webook/internal/repository/cache"
+	"gitee.com/geekbang/basic-go/webook/internal/repository/cache/redismocks"
+	"gitee.com/geekbang/basic-go/webook/internal/repository/dao/article"
+	mock_article "gitee.com/geekbang/basic-go/webook/internal/repository/dao/article/mocks"
+	"gitee.com/geekbang/basic-go/webook/pkg/logger"
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
+	"testing"
+	"time"
+)
+
+func TestArticleRepository_DeleteAndRecover(t *testing.T) {
+	// 删除和恢复都要把作者、读者、作者第一页、标签第一页的缓存删掉
+	mockCache := func(ctrl *gomock.Controller) redis.Cmdable {
+		cmd := redismocks.NewMockCmdable(ctrl)
+		res := redis.NewIntCmd(context.Background())
+		res.SetVal(1)
+		cmd.EXPECT().Del(gomock.Any(), "article:author:1", "article:reader:1").Return(res)
+		cmd.EXPECT().Del(gomock.Any(), "article:first_page:123").Return(res)
+		cmd.EXPECT().Del(gomock.Any(), "article:tag_first_page:go", "article:tag_first_page:后端").Return(res)
+		return cmd
+	}
+	testCases := []struct {
+		name string
+		mock func(ctrl *gomock.Controller) article.ArticleDao
+		op   func(repo ArticleRepository) error
+	}{
+		{
+			name: "删除",
+			mock: func(ctrl *gomock.Controller) article.ArticleDao {
+				d := mock_article.NewMockArticleDao(ctrl)
+				d.EXPECT().Delete(gomock.Any(), int64(123), int64(1)).Return(nil)
+				d.EXPECT().GetPubById(gomock.Any(), int64(1)).Return(article.PublishedArticle{}, article.ErrRecordNotFound)
+				d.EXPECT().GetPubTags(gomock.Any(), []int64{1}).Return(map[int64][]string{1: {"go", "后端"}}, nil)
+				return d
+			},
+			op: func(repo ArticleRepository) error {
+				return repo.Delete(context.Background(), 123, 1)
+			},
+		},
+		{
+			name: "恢复",
+			mock: func(ctrl *gomock.Controller) article.ArticleDao {
+				d := mock_article.NewMockArticleDao(ctrl)
+				d.EXPECT().Recover(gomock.Any(), int64(123), int64(1), int64(1000)).Return(nil)
+				d.EXPECT().GetPubById(gomock.Any(), int64(1)).Return(article.PublishedArticle{ID: 1, Status: 2}, nil)
+				d.EXPECT().GetPubTags(gomock.Any(), []int64{1}).Return(map[int64][]string{1: {"go", "后端"}}, nil)
+				return d
+			},
+			op: func(repo ArticleRepository) error {
+				return repo.Recover(context.Background(), 123, 1, time.UnixMilli(1000))
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+			d := tc.mock(ctrl)
+			l := &logger.NopLogger{}
+			repo := NewArticleRepository(d, cache.NewRedisArticleCache(mockCache(ctrl)), l,
+				nil, NewLocalSearchRepository(d, l))
+			assert.NoError(t, tc.op(repo))
+		})
+	}
+}
@@ -9,6 +9,7 @@ import (
 	"time"
 )
 
+//go:generate mockgen -source=$GOFILE -destination=mocks/mock_$GOFILE --package=$GOPACKAGEmocks
 type ArticleCache interface {
 	// GetFirstPage 只缓存第第一页的数据
 	// 并且不缓存整个 Content
@@ -22,6 +23,18 @@ type ArticleCache interface {
 	// SetPub 正常来说，创作者和读者的 Redis 集群要分开，因为读者是一个核心中的核心
 	SetPub(ctx context.Context, article domain.Article) error
 	GetPub(ctx context.Context, id int64) (domain.Article, error)
+	// Del 同时删除创作者和读者的缓存
+	Del(ctx context.Context, id int64) error
+	// DelPub 只删除读者的缓存
+	DelPub(ctx context.Context, ids ...int64) error
+
+	// GetTagFirstPage 和 GetFirstPage 一样，只缓存标签下面的第一页
+	GetTagFirstPage(ctx context.Context, tag string) ([]domain.Article, error)
+	SetTagFirstPage(ctx context.Context, tag string, arts []domain.Article) error
+	DelTagFirstPage(ctx context.Context, tags ...string) error
+
+	GetPopularTags(ctx context.Context) ([]domain.Tag, error)
+	SetPopularTags(ctx context.Context, tags []domain.Tag) error
 }
 
 type RedisArticleCache struct {
@@ -75,6 +88,21 @@ func (r *RedisArticleCache) Set(ctx context.Context, art domain.Article) error {
 	return r.client.Set(ctx, r.authorArtKey(art.Id), data, time.Minute).Err()
 }
 
+func (r *RedisArticleCache) Del(ctx context.Context, id int64) error {
+	return r.client.Del(ctx, r.authorArtKey(id), r.readerArtKey(id)).Err()
+}
+
+func (r *RedisArticleCache) DelPub(ctx context.Context, ids ...int64) error {
+	if len(ids) == 0 {
+		return nil
+	}
+	keys := make([]string, 0, len(ids))
+	for _, id := range ids {
+		keys = append(keys, r.readerArtKey(id))
+	}
+	return r.client.Del(ctx, keys...).Err()
+}
+
 func (r *RedisArticleCache) DelFirstPage(ctx context.Context, author int64) error {
 	return r.client.Del(ctx, r.firstPageKey(author)).Err()
 }
@@ -102,6 +130,59 @@ func (r *RedisArticleCache) SetFirstPage(ctx context.Context, author int64, arts
 		bs, time.Minute*10).Err()
 }
 
+func (r *RedisArticleCache) GetTagFirstPage(ctx context.Context, tag string) ([]domain.Article, error) {
+	bs, err := r.client.Get(ctx, r.tagFirstPageKey(tag)).Bytes()
+	if err != nil {
+		return nil, err
+	}
+	var arts []domain.Article
+	err = json.Unmarshal(bs, &arts)
+	return arts, err
+}
+
+func (r *RedisArticleCache) SetTagFirstPage(ctx context.Context, tag string, arts []domain.Article) error {
+	for i := range arts {
+		// 只缓存摘要部分
+		arts[i].Content = arts[i].Abstract()
+	}
+	bs, err := json.Marshal(arts)
+	if err != nil {
+		return err
+	}
+	return r.client.Set(ctx, r.tagFirstPageKey(tag),
+		bs, time.Minute*10).Err()
+}
+
+func (r *RedisArticleCache) DelTagFirstPage(ctx context.Context, tags ...string) error {
+	if len(tags) == 0 {
+		return nil
+	}
+	keys := make([]string, 0, len(tags))
+	for _, tag := range tags {
+		keys = append(keys, r.tagFirstPageKey(tag))
+	}
+	return r.client.Del(ctx, keys...).Err()
+}
+
+func (r *RedisArticleCache) GetPopularTags(ctx context.Context) ([]domain.Tag, error) {
+	bs, err := r.client.Get(ctx, r.popularTagsKey()).Bytes()
+	if err != nil {
+		return nil, err
+	}
+	var tags []domain.Tag
+	err = json.Unmarshal(bs, &tags)
+	return tags, err
+}
+
+func (r *RedisArticleCache) SetPopularTags(ctx context.Context, tags []domain.Tag) error {
+	bs, err := json.Marshal(tags)
+	if err != nil {
+		return err
+	}
+	// 热门标签不需要很准确，也没有主动失效
+	return r.client.Set(ctx, r.popularTagsKey(), bs, time.Minute*10).Err()
+}
+
 // 创作端的缓存设置
 func (r *RedisArticleCache) authorArtKey(id int64) string {
 	return fmt.Sprintf("article:author:%d", id)
@@ -115,3 +196,11 @@ func (r *RedisArticleCache) readerArtKey(id int64) string {
 func (r *RedisArticleCache) firstPageKey(author int64) string {
 	return fmt.Sprintf("article:first_page:%d", author)
 }
+
+func (r *RedisArticleCache) tagFirstPageKey(tag string) string {
+	return fmt.Sprintf("article:tag_first_page:%s", tag)
+}
+
+func (r *RedisArticleCache) popularTagsKey() string {
+	return "article:popular_tags"
+}
@@ -24,16 +24,18 @@ var (
 
 //go:generate mockgen -source=$GOFILE -destination=mocks/mock_$GOFILE --package=$GOPACKAGEmocks
 type CodeCache interface {
-	Set(ctx context.Context, biz string, phone string, code string) error
-	Verify(ctx context.Context, biz string, phone string, code string) (bool, error)
+	// Set 同一个渠道、业务和目标，一分钟之内只能发一次
+	Set(ctx context.Context, channel string, biz string, target string, code string) error
+	// Verify 一个验证码最多验证三次
+	Verify(ctx context.Context, channel string, biz string, target string, code string) (bool, error)
 }
 
 type CodeCacheImpl struct {
 	cmd redis.Cmdable
 }
 
-func (c *CodeCacheImpl) Verify(ctx context.Context, biz string, phone string, code string) (bool, error) {
-	res, err := c.cmd.Eval(ctx, luaVerifyCode, []string{c.genKey(biz, phone)}, code).Int()
+func (c *CodeCacheImpl) Verify(ctx context.Context, channel string, biz string, target string, code string) (bool, error) {
+	res, err := c.cmd.Eval(ctx, luaVerifyCode, []string{c.genKey(channel, biz, target)}, code).Int()
 	if err != nil {
 		return false, err
 	}
@@ -55,8 +57,8 @@ func NewCodeCacheImpl(cmd redis.Cmdable) CodeCache {
 	return &CodeCacheImpl{cmd: cmd}
 }
 
-func (c *CodeCacheImpl) Set(ctx context.Context, biz string, phone string, code string) error {
-	ret, err := c.cmd.Eval(ctx, luaSendCode, []string{c.genKey(biz, phone)}, code).Int()
+func (c *CodeCacheImpl) Set(ctx context.Context, channel string, biz string, target string, code string) error {
+	ret, err := c.cmd.Eval(ctx, luaSendCode, []string{c.genKey(channel, biz, target)}, code).Int()
 	if err != nil {
 		return err
 	}
@@ -70,6 +72,7 @@ func (c *CodeCacheImpl) Set(ctx context.Context, biz string, phone string, code
 	}
 }
 
-func (c *CodeCacheImpl) genKey(biz string, phone string) string {
-	return fmt.Sprintf("phone_code:%s:%s", biz, phone)
+// genKey 短信渠道是 phone_code:biz:phone，和以前的 key 保持一致
+func (c *CodeCacheImpl) genKey(channel string, biz string, target string) string {
+	return fmt.Sprintf("%s_code:%s:%s", channel, biz, target)
 }
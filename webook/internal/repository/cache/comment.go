@@ -0,0 +1,47 @@
+package cache
+
+import (
+	"context"
+	"fmt"
+	"github.com/redis/go-redis/v9"
+	"time"
+)
+
+type CommentCache interface {
+	// GetCnt 评论总数，缓存不存在的时候返回 ErrKeyNotExist
+	GetCnt(ctx context.Context, biz string, bizId int64) (int64, error)
+	SetCnt(ctx context.Context, biz string, bizId int64, cnt int64) error
+	DelCnt(ctx context.Context, biz string, bizId int64) error
+}
+
+type RedisCommentCache struct {
+	client     redis.Cmdable
+	expiration time.Duration
+}
+
+func NewRedisCommentCache(client redis.Cmdable) CommentCache {
+	return &RedisCommentCache{
+		client:     client,
+		expiration: time.Minute * 10,
+	}
+}
+
+func (r *RedisCommentCache) GetCnt(ctx context.Context, biz string, bizId int64) (int64, error) {
+	cnt, err := r.client.Get(ctx, r.cntKey(biz, bizId)).Int64()
+	if err == redis.Nil {
+		return 0, ErrKeyNotExist
+	}
+	return cnt, err
+}
+
+func (r *RedisCommentCache) SetCnt(ctx context.Context, biz string, bizId int64, cnt int64) error {
+	return r.client.Set(ctx, r.cntKey(biz, bizId), cnt, r.expiration).Err()
+}
+
+func (r *RedisCommentCache) DelCnt(ctx context.Context, biz string, bizId int64) error {
+	return r.client.Del(ctx, r.cntKey(biz, bizId)).Err()
+}
+
+func (r *RedisCommentCache) cntKey(biz string, bizId int64) string {
+	return fmt.Sprintf("comment:cnt:%s:%d", biz, bizId)
+}
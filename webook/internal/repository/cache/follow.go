@@ -0,0 +1,59 @@
+package cache
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"gitee.com/geekbang/basic-go/webook/internal/domain"
+	"github.com/redis/go-redis/v9"
+	"time"
+)
+
+type FollowCache interface {
+	// GetStatics 缓存不存在的时候返回 ErrKeyNotExist
+	GetStatics(ctx context.Context, uid int64) (domain.FollowStatics, error)
+	SetStatics(ctx context.Context, uid int64, statics domain.FollowStatics) error
+	DelStatics(ctx context.Context, uids ...int64) error
+}
+
+type RedisFollowCache struct {
+	client     redis.Cmdable
+	expiration time.Duration
+}
+
+func NewRedisFollowCache(client redis.Cmdable) FollowCache {
+	return &RedisFollowCache{
+		client:     client,
+		expiration: time.Minute * 15,
+	}
+}
+
+func (r *RedisFollowCache) GetStatics(ctx context.Context, uid int64) (domain.FollowStatics, error) {
+	bs, err := r.client.Get(ctx, r.staticsKey(uid)).Bytes()
+	if err != nil {
+		return domain.FollowStatics{}, err
+	}
+	var res domain.FollowStatics
+	err = json.Unmarshal(bs, &res)
+	return res, err
+}
+
+func (r *RedisFollowCache) SetStatics(ctx context.Context, uid int64, statics domain.FollowStatics) error {
+	bs, err := json.Marshal(statics)
+	if err != nil {
+		return err
+	}
+	return r.client.Set(ctx, r.staticsKey(uid), bs, r.expiration).Err()
+}
+
+func (r *RedisFollowCache) DelStatics(ctx context.Context, uids ...int64) error {
+	keys := make([]string, 0, len(uids))
+	for _, uid := range uids {
+		keys = append(keys, r.staticsKey(uid))
+	}
+	return r.client.Del(ctx, keys...).Err()
+}
+
+func (r *RedisFollowCache) staticsKey(uid int64) string {
+	return fmt.Sprintf("follow:statics:%d", uid)
+}
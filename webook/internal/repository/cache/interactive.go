@@ -0,0 +1,107 @@
+package cache
+
+import (
+	"context"
+	_ "embed"
+	"fmt"
+	"gitee.com/geekbang/basic-go/webook/internal/domain"
+	"github.com/redis/go-redis/v9"
+	"strconv"
+	"time"
+)
+
+var (
+	//go:embed lua/interactive_incr_cnt.lua
+	luaIncrCnt string
+)
+
+const (
+	fieldReadCnt    = "read_cnt"
+	fieldLikeCnt    = "like_cnt"
+	fieldCollectCnt = "collect_cnt"
+)
+
+type InteractiveCache interface {
+	// IncrReadCntIfPresent 只有缓存存在的时候才会自增
+	IncrReadCntIfPresent(ctx context.Context, biz string, bizId int64) error
+	IncrLikeCntIfPresent(ctx context.Context, biz string, bizId int64) error
+	DecrLikeCntIfPresent(ctx context.Context, biz string, bizId int64) error
+	IncrCollectCntIfPresent(ctx context.Context, biz string, bizId int64) error
+	DecrCollectCntIfPresent(ctx context.Context, biz string, bizId int64) error
+	Get(ctx context.Context, biz string, bizId int64) (domain.Interactive, error)
+	Set(ctx context.Context, intr domain.Interactive) error
+}
+
+type RedisInteractiveCache struct {
+	client     redis.Cmdable
+	expiration time.Duration
+}
+
+func NewRedisInteractiveCache(client redis.Cmdable) InteractiveCache {
+	return &RedisInteractiveCache{
+		client:     client,
+		expiration: time.Minute * 15,
+	}
+}
+
+func (r *RedisInteractiveCache) IncrReadCntIfPresent(ctx context.Context, biz string, bizId int64) error {
+	return r.incrCnt(ctx, biz, bizId, fieldReadCnt, 1)
+}
+
+func (r *RedisInteractiveCache) IncrLikeCntIfPresent(ctx context.Context, biz string, bizId int64) error {
+	return r.incrCnt(ctx, biz, bizId, fieldLikeCnt, 1)
+}
+
+func (r *RedisInteractiveCache) DecrLikeCntIfPresent(ctx context.Context, biz string, bizId int64) error {
+	return r.incrCnt(ctx, biz, bizId, fieldLikeCnt, -1)
+}
+
+func (r *RedisInteractiveCache) IncrCollectCntIfPresent(ctx context.Context, biz string, bizId int64) error {
+	return r.incrCnt(ctx, biz, bizId, fieldCollectCnt, 1)
+}
+
+func (r *RedisInteractiveCache) DecrCollectCntIfPresent(ctx context.Context, biz string, bizId int64) error {
+	return r.incrCnt(ctx, biz, bizId, fieldCollectCnt, -1)
+}
+
+func (r *RedisInteractiveCache) incrCnt(ctx context.Context, biz string, bizId int64, field string, delta int) error {
+	return r.client.Eval(ctx, luaIncrCnt, []string{r.key(biz, bizId)}, field, delta).Err()
+}
+
+func (r *RedisInteractiveCache) Get(ctx context.Context, biz string, bizId int64) (domain.Interactive, error) {
+	data, err := r.client.HGetAll(ctx, r.key(biz, bizId)).Result()
+	if err != nil {
+		return domain.Interactive{}, err
+	}
+	if len(data) == 0 {
+		// HGetAll 在 key 不存在的时候不会返回 redis.Nil
+		return domain.Interactive{}, ErrKeyNotExist
+	}
+	// 这里不处理转换的错误，缓存里面的数据都是我们自己写进去的
+	readCnt, _ := strconv.ParseInt(data[fieldReadCnt], 10, 64)
+	likeCnt, _ := strconv.ParseInt(data[fieldLikeCnt], 10, 64)
+	collectCnt, _ := strconv.ParseInt(data[fieldCollectCnt], 10, 64)
+	return domain.Interactive{
+		Biz:        biz,
+		BizId:      bizId,
+		ReadCnt:    readCnt,
+		LikeCnt:    likeCnt,
+		CollectCnt: collectCnt,
+	}, nil
+}
+
+func (r *RedisInteractiveCache) Set(ctx context.Context, intr domain.Interactive) error {
+	key := r.key(intr.Biz, intr.BizId)
+	err := r.client.HSet(ctx, key,
+		fieldReadCnt, intr.ReadCnt,
+		fieldLikeCnt, intr.LikeCnt,
+		fieldCollectCnt, intr.CollectCnt).Err()
+	if err != nil {
+		return err
+	}
+	return r.client.Expire(ctx, key, r.expiration).Err()
+}
+
+func (r *RedisInteractiveCache) key(biz string, bizId int64) string {
+	return fmt.Sprintf("interactive:%s:%d", biz, bizId)
+}
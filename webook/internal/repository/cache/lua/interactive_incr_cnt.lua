@@ -0,0 +1,17 @@
+-- 具体的业务，比如 interactive:article:1
+local key = KEYS[1]
+-- 是阅读数，点赞数还是收藏数
+local cntKey = ARGV[1]
+local delta = tonumber(ARGV[2])
+local exists = redis.call("EXISTS", key)
+if exists == 1 then
+    -- 缓存存在才自增，不存在的话等下一次读的时候从数据库加载
+    local res = redis.call("HINCRBY", key, cntKey, delta)
+    if res < 0 then
+        -- 缓存和数据库不一致的时候，不能减成负数
+        redis.call("HSET", key, cntKey, 0)
+    end
+    return 1
+else
+    return 0
+end
@@ -3,7 +3,7 @@
 //
 // Generated by this command:
 //
-//	mockgen -source=code.go -destination=mocks/mock_code.go --package=
+//	mockgen -source=code.go -destination=mocks/mock_code.go --package=mock_cache
 //
 
 // Package mock_cache is a generated GoMock package.
@@ -40,30 +40,30 @@ func (m *MockCodeCache) EXPECT() *MockCodeCacheMockRecorder {
 }
 
 // Set mocks base method.
-func (m *MockCodeCache) Set(ctx context.Context, biz, phone, code string) error {
+func (m *MockCodeCache) Set(ctx context.Context, channel, biz, target, code string) error {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "Set", ctx, biz, phone, code)
+	ret := m.ctrl.Call(m, "Set", ctx, channel, biz, target, code)
 	ret0, _ := ret[0].(error)
 	return ret0
 }
 
 // Set indicates an expected call of Set.
-func (mr *MockCodeCacheMockRecorder) Set(ctx, biz, phone, code any) *gomock.Call {
+func (mr *MockCodeCacheMockRecorder) Set(ctx, channel, biz, target, code any) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Set", reflect.TypeOf((*MockCodeCache)(nil).Set), ctx, biz, phone, code)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Set", reflect.TypeOf((*MockCodeCache)(nil).Set), ctx, channel, biz, target, code)
 }
 
 // Verify mocks base method.
-func (m *MockCodeCache) Verify(ctx context.Context, biz, phone, code string) (bool, error) {
+func (m *MockCodeCache) Verify(ctx context.Context, channel, biz, target, code string) (bool, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "Verify", ctx, biz, phone, code)
+	ret := m.ctrl.Call(m, "Verify", ctx, channel, biz, target, code)
 	ret0, _ := ret[0].(bool)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // Verify indicates an expected call of Verify.
-func (mr *MockCodeCacheMockRecorder) Verify(ctx, biz, phone, code any) *gomock.Call {
+func (mr *MockCodeCacheMockRecorder) Verify(ctx, channel, biz, target, code any) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Verify", reflect.TypeOf((*MockCodeCache)(nil).Verify), ctx, biz, phone, code)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Verify", reflect.TypeOf((*MockCodeCache)(nil).Verify), ctx, channel, biz, target, code)
 }
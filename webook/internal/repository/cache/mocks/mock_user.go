@@ -3,7 +3,7 @@
 //
 // Generated by this command:
 //
-//	mockgen -source=user.go -destination=mocks/mock_user.go --package=
+//	mockgen -source=user.go -destination=mocks/mock_user.go --package=mock_cache
 //
 
 // Package mock_cache is a generated GoMock package.
@@ -11,6 +11,7 @@ package mock_cache
 
 import (
 	reflect "reflect"
+	time "time"
 
 	domain "gitee.com/geekbang/basic-go/webook/internal/domain"
 	gomock "go.uber.org/mock/gomock"
@@ -40,6 +41,50 @@ func (m *MockUserCache) EXPECT() *MockUserCacheMockRecorder {
 	return m.recorder
 }
 
+// ConsumeResetTicket mocks base method.
+func (m *MockUserCache) ConsumeResetTicket(ctx context.Context, ticket string) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ConsumeResetTicket", ctx, ticket)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ConsumeResetTicket indicates an expected call of ConsumeResetTicket.
+func (mr *MockUserCacheMockRecorder) ConsumeResetTicket(ctx, ticket any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ConsumeResetTicket", reflect.TypeOf((*MockUserCache)(nil).ConsumeResetTicket), ctx, ticket)
+}
+
+// ConsumeSignUpPassword mocks base method.
+func (m *MockUserCache) ConsumeSignUpPassword(ctx context.Context, email string) (string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ConsumeSignUpPassword", ctx, email)
+	ret0, _ := ret[0].(string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ConsumeSignUpPassword indicates an expected call of ConsumeSignUpPassword.
+func (mr *MockUserCacheMockRecorder) ConsumeSignUpPassword(ctx, email any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ConsumeSignUpPassword", reflect.TypeOf((*MockUserCache)(nil).ConsumeSignUpPassword), ctx, email)
+}
+
+// Del mocks base method.
+func (m *MockUserCache) Del(ctx context.Context, id int64) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Del", ctx, id)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Del indicates an expected call of Del.
+func (mr *MockUserCacheMockRecorder) Del(ctx, id any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Del", reflect.TypeOf((*MockUserCache)(nil).Del), ctx, id)
+}
+
 // Get mocks base method.
 func (m *MockUserCache) Get(ctx context.Context, id int64) (domain.User, error) {
 	m.ctrl.T.Helper()
@@ -68,3 +113,31 @@ func (mr *MockUserCacheMockRecorder) Set(ctx, user any) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Set", reflect.TypeOf((*MockUserCache)(nil).Set), ctx, user)
 }
+
+// SetResetTicket mocks base method.
+func (m *MockUserCache) SetResetTicket(ctx context.Context, ticket string, uid int64, expiration time.Duration) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetResetTicket", ctx, ticket, uid, expiration)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SetResetTicket indicates an expected call of SetResetTicket.
+func (mr *MockUserCacheMockRecorder) SetResetTicket(ctx, ticket, uid, expiration any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetResetTicket", reflect.TypeOf((*MockUserCache)(nil).SetResetTicket), ctx, ticket, uid, expiration)
+}
+
+// SetSignUpPassword mocks base method.
+func (m *MockUserCache) SetSignUpPassword(ctx context.Context, email, password string, expiration time.Duration) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetSignUpPassword", ctx, email, password, expiration)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SetSignUpPassword indicates an expected call of SetSignUpPassword.
+func (mr *MockUserCacheMockRecorder) SetSignUpPassword(ctx, email, password, expiration any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetSignUpPassword", reflect.TypeOf((*MockUserCache)(nil).SetSignUpPassword), ctx, email, password, expiration)
+}
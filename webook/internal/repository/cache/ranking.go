@@ -0,0 +1,89 @@
+package cache
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"gitee.com/geekbang/basic-go/webook/internal/domain"
+	"github.com/redis/go-redis/v9"
+	"sync/atomic"
+	"time"
+)
+
+var ErrLocalCacheExpired = errors.New("本地缓存已经过期")
+
+type RankingCache interface {
+	Set(ctx context.Context, arts []domain.Article) error
+	Get(ctx context.Context) ([]domain.Article, error)
+}
+
+type RankingRedisCache struct {
+	client redis.Cmdable
+	key    string
+	// 过期时间要比计算热榜的间隔长，这样任务偶尔失败一次也不会没有热榜
+	expiration time.Duration
+}
+
+func NewRankingRedisCache(client redis.Cmdable) *RankingRedisCache {
+	return &RankingRedisCache{
+		client:     client,
+		key:        "ranking:top_n",
+		expiration: time.Minute * 10,
+	}
+}
+
+func (r *RankingRedisCache) Set(ctx context.Context, arts []domain.Article) error {
+	// arts 来自读者文章列表，Content 已经只有摘要了
+	val, err := json.Marshal(arts)
+	if err != nil {
+		return err
+	}
+	return r.client.Set(ctx, r.key, val, r.expiration).Err()
+}
+
+func (r *RankingRedisCache) Get(ctx context.Context) ([]domain.Article, error) {
+	val, err := r.client.Get(ctx, r.key).Bytes()
+	if err != nil {
+		return nil, err
+	}
+	var res []domain.Article
+	err = json.Unmarshal(val, &res)
+	return res, err
+}
+
+// RankingLocalCache 进程内的热榜缓存
+// 热榜所有人看到的都是同一份，非常适合放在本地
+type RankingLocalCache struct {
+	topN       atomic.Pointer[[]domain.Article]
+	ddl        atomic.Int64
+	expiration time.Duration
+}
+
+func NewRankingLocalCache() *RankingLocalCache {
+	return &RankingLocalCache{
+		expiration: time.Minute * 3,
+	}
+}
+
+func (r *RankingLocalCache) Set(ctx context.Context, arts []domain.Article) error {
+	r.topN.Store(&arts)
+	r.ddl.Store(time.Now().Add(r.expiration).UnixMilli())
+	return nil
+}
+
+func (r *RankingLocalCache) Get(ctx context.Context) ([]domain.Article, error) {
+	arts := r.topN.Load()
+	if arts == nil || r.ddl.Load() < time.Now().UnixMilli() {
+		return nil, ErrLocalCacheExpired
+	}
+	return *arts, nil
+}
+
+// ForceGet 不管有没有过期都返回，用于 Redis 也拿不到数据时候的兜底
+func (r *RankingLocalCache) ForceGet(ctx context.Context) ([]domain.Article, error) {
+	arts := r.topN.Load()
+	if arts == nil {
+		return nil, ErrLocalCacheExpired
+	}
+	return *arts, nil
+}
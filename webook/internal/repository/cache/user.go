@@ -15,6 +15,15 @@ import (
 type UserCache interface {
 	Get(ctx context.Context, id int64) (domain.User, error)
 	Set(ctx context.Context, user domain.User) error
+	Del(ctx context.Context, id int64) error
+	// SetResetTicket 重置密码的凭证，只能用一次
+	SetResetTicket(ctx context.Context, ticket string, uid int64, expiration time.Duration) error
+	// ConsumeResetTicket 取出凭证对应的用户并且删除凭证，不存在返回 ErrKeyNotExist
+	ConsumeResetTicket(ctx context.Context, ticket string) (int64, error)
+	// SetSignUpPassword 还没有激活的邮箱重新注册时的新密码，激活的时候才生效
+	SetSignUpPassword(ctx context.Context, email string, password string, expiration time.Duration) error
+	// ConsumeSignUpPassword 取出并且删除，不存在返回 ErrKeyNotExist
+	ConsumeSignUpPassword(ctx context.Context, email string) (string, error)
 }
 
 var ErrKeyNotExist = redis.Nil
@@ -52,6 +61,34 @@ func (r *RedisUserCache) Set(ctx context.Context, user domain.User) error {
 
 }
 
+func (r *RedisUserCache) Del(ctx context.Context, id int64) error {
+	return r.cmd.Del(ctx, r.genKey(id)).Err()
+}
+
+func (r *RedisUserCache) SetResetTicket(ctx context.Context, ticket string, uid int64, expiration time.Duration) error {
+	return r.cmd.Set(ctx, r.resetTicketKey(ticket), uid, expiration).Err()
+}
+
+func (r *RedisUserCache) ConsumeResetTicket(ctx context.Context, ticket string) (int64, error) {
+	return r.cmd.GetDel(ctx, r.resetTicketKey(ticket)).Int64()
+}
+
+func (r *RedisUserCache) SetSignUpPassword(ctx context.Context, email string, password string, expiration time.Duration) error {
+	return r.cmd.Set(ctx, r.signUpPasswordKey(email), password, expiration).Err()
+}
+
+func (r *RedisUserCache) ConsumeSignUpPassword(ctx context.Context, email string) (string, error) {
+	return r.cmd.GetDel(ctx, r.signUpPasswordKey(email)).Result()
+}
+
+func (r *RedisUserCache) signUpPasswordKey(email string) string {
+	return fmt.Sprintf("user:signup_password:%s", email)
+}
+
+func (r *RedisUserCache) resetTicketKey(ticket string) string {
+	return fmt.Sprintf("user:reset_ticket:%s", ticket)
+}
+
 func (r *RedisUserCache) genKey(id int64) string {
 	return fmt.Sprintf("user:info:%d", id)
 }
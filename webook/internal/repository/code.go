@@ -12,22 +12,22 @@ var (
 
 //go:generate mockgen -source=$GOFILE -destination=mocks/mock_$GOFILE --package=$GOPACKAGEmocks
 type CodeRepo interface {
-	Set(ctx context.Context, biz string, phone string, code string) error
-	Verify(ctx context.Context, biz string, phone string, code string) (bool, error)
+	Set(ctx context.Context, channel string, biz string, target string, code string) error
+	Verify(ctx context.Context, channel string, biz string, target string, code string) (bool, error)
 }
 
 type CodeRepoImpl struct {
 	cache cache.CodeCache
 }
 
-func (c *CodeRepoImpl) Verify(ctx context.Context, biz string, phone string, code string) (bool, error) {
-	return c.cache.Verify(ctx, biz, phone, code)
+func (c *CodeRepoImpl) Verify(ctx context.Context, channel string, biz string, target string, code string) (bool, error) {
+	return c.cache.Verify(ctx, channel, biz, target, code)
 }
 
 func NewCodeRepoImpl(cache cache.CodeCache) CodeRepo {
 	return &CodeRepoImpl{cache: cache}
 }
 
-func (c *CodeRepoImpl) Set(ctx context.Context, biz string, phone string, code string) error {
-	return c.cache.Set(ctx, biz, phone, code)
+func (c *CodeRepoImpl) Set(ctx context.Context, channel string, biz string, target string, code string) error {
+	return c.cache.Set(ctx, channel, biz, target, code)
 }
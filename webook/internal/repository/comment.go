@@ -0,0 +1,172 @@
+package repository
+
+import (
+	"context"
+	"gitee.com/geekbang/basic-go/webook/internal/domain"
+	"gitee.com/geekbang/basic-go/webook/internal/repository/cache"
+	"gitee.com/geekbang/basic-go/webook/internal/repository/dao/comment"
+	"gitee.com/geekbang/basic-go/webook/pkg/logger"
+	"time"
+)
+
+var ErrCommentNotFound = comment.ErrRecordNotFound
+
+//go:generate mockgen -source=$GOFILE -destination=mocks/mock_$GOFILE --package=$GOPACKAGEmocks
+type CommentRepository interface {
+	CreateComment(ctx context.Context, c domain.Comment) (int64, error)
+	// FindById 不查询评论人的信息
+	FindById(ctx context.Context, id int64) (domain.Comment, error)
+	// ListRoots 根评论，带上回复数量
+	ListRoots(ctx context.Context, biz string, bizId int64, maxId int64, limit int) ([]domain.Comment, error)
+	ListReplies(ctx context.Context, rootId int64, minId int64, limit int) ([]domain.Comment, error)
+	DeleteComment(ctx context.Context, c domain.Comment) error
+	Count(ctx context.Context, biz string, bizId int64) (int64, error)
+}
+
+type commentRepository struct {
+	dao      comment.CommentDao
+	cache    cache.CommentCache
+	userRepo UserRepo
+	l        logger.Logger
+}
+
+func NewCommentRepository(dao comment.CommentDao, c cache.CommentCache,
+	userRepo UserRepo, l logger.Logger) CommentRepository {
+	return &commentRepository{
+		dao:      dao,
+		cache:    c,
+		userRepo: userRepo,
+		l:        l,
+	}
+}
+
+func (repo *commentRepository) CreateComment(ctx context.Context, c domain.Comment) (int64, error) {
+	id, err := repo.dao.Insert(ctx, repo.toEntity(c))
+	if err != nil {
+		return 0, err
+	}
+	repo.delCnt(ctx, c.Biz, c.BizId)
+	return id, nil
+}
+
+func (repo *commentRepository) FindById(ctx context.Context, id int64) (domain.Comment, error) {
+	c, err := repo.dao.FindById(ctx, id)
+	if err != nil {
+		return domain.Comment{}, err
+	}
+	return repo.toDomain(c), nil
+}
+
+func (repo *commentRepository) ListRoots(ctx context.Context, biz string, bizId int64, maxId int64, limit int) ([]domain.Comment, error) {
+	data, err := repo.dao.FindRoots(ctx, biz, bizId, maxId, limit)
+	if err != nil {
+		return nil, err
+	}
+	ids := make([]int64, 0, len(data))
+	for _, c := range data {
+		ids = append(ids, c.ID)
+	}
+	cnts, err := repo.dao.CountReplies(ctx, ids)
+	if err != nil {
+		return nil, err
+	}
+	cntMap := make(map[int64]int64, len(cnts))
+	for _, cnt := range cnts {
+		cntMap[cnt.RootID] = cnt.Cnt
+	}
+	res := repo.toDomains(ctx, data)
+	for i := range res {
+		res[i].ReplyCnt = cntMap[res[i].Id]
+	}
+	return res, nil
+}
+
+func (repo *commentRepository) ListReplies(ctx context.Context, rootId int64, minId int64, limit int) ([]domain.Comment, error) {
+	data, err := repo.dao.FindReplies(ctx, rootId, minId, limit)
+	if err != nil {
+		return nil, err
+	}
+	return repo.toDomains(ctx, data), nil
+}
+
+func (repo *commentRepository) DeleteComment(ctx context.Context, c domain.Comment) error {
+	err := repo.dao.Delete(ctx, repo.toEntity(c))
+	if err != nil {
+		return err
+	}
+	repo.delCnt(ctx, c.Biz, c.BizId)
+	return nil
+}
+
+func (repo *commentRepository) Count(ctx context.Context, biz string, bizId int64) (int64, error) {
+	cnt, err := repo.cache.GetCnt(ctx, biz, bizId)
+	if err == nil {
+		return cnt, nil
+	}
+	cnt, err = repo.dao.Count(ctx, biz, bizId)
+	if err != nil {
+		return 0, err
+	}
+	if er := repo.cache.SetCnt(ctx, biz, bizId, cnt); er != nil {
+		repo.l.Error("设置评论数缓存失败", logger.Error(er),
+			logger.String("biz", biz), logger.Int64("bizId", bizId))
+	}
+	return cnt, nil
+}
+
+func (repo *commentRepository) delCnt(ctx context.Context, biz string, bizId int64) {
+	if err := repo.cache.DelCnt(ctx, biz, bizId); err != nil {
+		repo.l.Error("删除评论数缓存失败", logger.Error(err),
+			logger.String("biz", biz), logger.Int64("bizId", bizId))
+	}
+}
+
+// toDomains 同时查询评论人的昵称，同一个人只查一次
+func (repo *commentRepository) toDomains(ctx context.Context, data []comment.Comment) []domain.Comment {
+	names := make(map[int64]string, len(data))
+	res := make([]domain.Comment, 0, len(data))
+	for _, c := range data {
+		dc := repo.toDomain(c)
+		name, ok := names[c.Uid]
+		if !ok {
+			user, err := repo.userRepo.FindById(ctx, c.Uid)
+			if err != nil {
+				// 昵称拿不到，评论照样展示
+				repo.l.Error("查询评论人失败", logger.Error(err),
+					logger.Int64("uid", c.Uid))
+			}
+			name = user.Nickname
+			names[c.Uid] = name
+		}
+		dc.Commentator.Name = name
+		res = append(res, dc)
+	}
+	return res
+}
+
+func (repo *commentRepository) toEntity(c domain.Comment) comment.Comment {
+	return comment.Comment{
+		ID:      c.Id,
+		Uid:     c.Commentator.Id,
+		Biz:     c.Biz,
+		BizID:   c.BizId,
+		RootID:  c.RootId,
+		PID:     c.ParentId,
+		Content: c.Content,
+	}
+}
+
+func (repo *commentRepository) toDomain(c comment.Comment) domain.Comment {
+	return domain.Comment{
+		Id:    c.ID,
+		Biz:   c.Biz,
+		BizId: c.BizID,
+		Commentator: domain.Author{
+			Id: c.Uid,
+		},
+		Content:  c.Content,
+		RootId:   c.RootID,
+		ParentId: c.PID,
+		Ctime:    time.UnixMilli(c.Ctime),
+	}
+}
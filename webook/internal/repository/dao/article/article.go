@@ -11,8 +11,10 @@ import (
 var (
 	ErrArticleDuplicate        = gorm.ErrDuplicatedKey
 	ErrPossibleIncorrectAuthor = errors.New("用户在尝试操作非本人数据")
+	ErrRecordNotFound          = gorm.ErrRecordNotFound
 )
 
+//go:generate mockgen -source=$GOFILE -destination=mocks/mock_$GOFILE --package=$GOPACKAGEmocks
 type ArticleDao interface {
 	Insert(ctx context.Context, entity Article) (int64, error)
 	Update(ctx context.Context, entity Article) error
@@ -21,6 +23,37 @@ type ArticleDao interface {
 	GetByAuthor(ctx context.Context, uid int64, offset int, limit int) ([]Article, error)
 	GetById(ctx context.Context, id int64) (Article, error)
 	GetPubById(ctx context.Context, id int64) (PublishedArticle, error)
+	// ListPub 按照 utime, id 倒序的游标分页
+	// utime 为 0 表示从最新的开始查
+	ListPub(ctx context.Context, status uint8, utime int64, id int64, limit int) ([]PublishedArticle, error)
+	// ListRevisions 按照时间倒序，只返回 uid 自己的文章的版本
+	ListRevisions(ctx context.Context, uid int64, artId int64, offset int, limit int) ([]ArticleRevision, error)
+	GetRevision(ctx context.Context, id int64) (ArticleRevision, error)
+	// ListScheduled 找出 publishTime 之前就应该发表的定时文章
+	ListScheduled(ctx context.Context, status uint8, publishTime int64, limit int) ([]Article, error)
+	// TransitStatus 只有当前状态是 from 的时候才修改为 to，返回是否修改成功
+	TransitStatus(ctx context.Context, id int64, from uint8, to uint8) (bool, error)
+	// CancelSchedule 把定时发表的文章改回 to 状态，不是定时发表状态的不做任何修改
+	CancelSchedule(ctx context.Context, uid int64, id int64, from uint8, to uint8) error
+	// Delete 在同一个事务里面软删除制作库和线上库的数据
+	Delete(ctx context.Context, uid int64, id int64) error
+	// Recover 恢复 deadline 之后删除的文章，更早删除的返回 ErrRecordNotFound
+	Recover(ctx context.Context, uid int64, id int64, deadline int64) error
+	// ListDeleted 按照删除时间倒序，列出 deadline 之后删除的文章
+	ListDeleted(ctx context.Context, uid int64, deadline int64, offset int, limit int) ([]Article, error)
+	// GetTags 批量查询制作库里文章的标签，key 是文章 ID
+	GetTags(ctx context.Context, artIds []int64) (map[int64][]string, error)
+	// GetPubTags 批量查询线上文章的标签，只有 Sync 会修改
+	GetPubTags(ctx context.Context, artIds []int64) (map[int64][]string, error)
+	// ListByTag 某个标签下面已经发表的文章，按照 utime 倒序，用的是线上的标签
+	ListByTag(ctx context.Context, tag string, status uint8, offset int, limit int) ([]PublishedArticle, error)
+	// PopularTags 已经发表的文章最多的 n 个标签，用的是线上的标签
+	PopularTags(ctx context.Context, status uint8, n int) ([]TagCount, error)
+	// ListPubIds 作者所有线上的文章 ID，包括已经撤回的
+	ListPubIds(ctx context.Context, author int64) ([]int64, error)
+	// WithdrawByAuthor 在同一个事务里面把作者线上 published 状态的文章和制作库里 scheduled 状态的文章都改成 to，
+	// 返回修改了的文章 ID
+	WithdrawByAuthor(ctx context.Context, author int64, published uint8, scheduled uint8, to uint8) ([]int64, error)
 }
 
 type articleDaoGORM struct {
@@ -29,19 +62,62 @@ type articleDaoGORM struct {
 
 func (d *articleDaoGORM) GetPubById(ctx context.Context, id int64) (PublishedArticle, error) {
 	var res PublishedArticle
-	err := d.db.WithContext(ctx).Where("id = ?", id).First(&res).Error
+	err := d.db.WithContext(ctx).Where("id = ? AND deleted_at = ?", id, 0).First(&res).Error
+	return res, err
+}
+
+func (d *articleDaoGORM) ListPub(ctx context.Context, status uint8, utime int64, id int64, limit int) ([]PublishedArticle, error) {
+	var res []PublishedArticle
+	query := d.db.WithContext(ctx).Where("status = ? AND deleted_at = ?", status, 0)
+	if utime > 0 {
+		// 不用 offset，翻到后面的时候 offset 会越来越慢
+		query = query.Where("utime < ? OR (utime = ? AND id < ?)", utime, utime, id)
+	}
+	err := query.Order("utime DESC, id DESC").
+		Limit(limit).
+		Find(&res).Error
+	return res, err
+}
+
+func (d *articleDaoGORM) ListScheduled(ctx context.Context, status uint8, publishTime int64, limit int) ([]Article, error) {
+	var res []Article
+	err := d.db.WithContext(ctx).
+		Where("status = ? AND publish_time <= ? AND deleted_at = ?", status, publishTime, 0).
+		Order("publish_time ASC").
+		Limit(limit).
+		Find(&res).Error
 	return res, err
 }
 
+func (d *articleDaoGORM) TransitStatus(ctx context.Context, id int64, from uint8, to uint8) (bool, error) {
+	res := d.db.WithContext(ctx).Model(&Article{}).
+		Where("id = ? AND status = ?", id, from).
+		Updates(map[string]any{
+			"status": to,
+			"utime":  time.Now().UnixMilli(),
+		})
+	return res.RowsAffected > 0, res.Error
+}
+
+func (d *articleDaoGORM) CancelSchedule(ctx context.Context, uid int64, id int64, from uint8, to uint8) error {
+	return d.db.WithContext(ctx).Model(&Article{}).
+		Where("id = ? AND author_id = ? AND status = ?", id, uid, from).
+		Updates(map[string]any{
+			"status":       to,
+			"publish_time": 0,
+			"utime":        time.Now().UnixMilli(),
+		}).Error
+}
+
 func (d *articleDaoGORM) GetById(ctx context.Context, id int64) (Article, error) {
 	var res Article
-	err := d.db.WithContext(ctx).Where("id = ?", id).First(&res).Error
+	err := d.db.WithContext(ctx).Where("id = ? AND deleted_at = ?", id, 0).First(&res).Error
 	return res, err
 }
 
 func (d *articleDaoGORM) GetByAuthor(ctx context.Context, uid int64, offset int, limit int) ([]Article, error) {
 	var res []Article
-	err := d.db.WithContext(ctx).Where("author_id = ?", uid).
+	err := d.db.WithContext(ctx).Where("author_id = ? AND deleted_at = ?", uid, 0).
 		Order("utime DESC").
 		Offset(offset).
 		Limit(limit).
@@ -54,7 +130,7 @@ func (d *articleDaoGORM) SyncStatus(ctx context.Context, uid int64, id int64, st
 	err := d.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
 		now := time.Now().UnixMilli()
 		res := tx.Model(&Article{}).
-			Where("id = ? and author_id = ?", id, uid).
+			Where("id = ? and author_id = ? and deleted_at = ?", id, uid, 0).
 			Updates(map[string]any{
 				"status": status,
 				"Utime":  now,
@@ -83,6 +159,66 @@ func (d *articleDaoGORM) SyncStatus(ctx context.Context, uid int64, id int64, st
 
 }
 
+func (d *articleDaoGORM) Delete(ctx context.Context, uid int64, id int64) error {
+	return d.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		now := time.Now().UnixMilli()
+		res := tx.Model(&Article{}).
+			Where("id = ? AND author_id = ? AND deleted_at = ?", id, uid, 0).
+			Updates(map[string]any{
+				"deleted_at": now,
+				"utime":      now,
+			})
+		if res.Error != nil {
+			return res.Error
+		}
+		if res.RowsAffected == 0 {
+			return ErrPossibleIncorrectAuthor
+		}
+		// 没有发表过的文章，线上库里面没有数据，所以这里不检查 RowsAffected
+		return tx.Model(&PublishedArticle{}).
+			Where("id = ? AND author_id = ?", id, uid).
+			Updates(map[string]any{
+				"deleted_at": now,
+				"utime":      now,
+			}).Error
+	})
+}
+
+func (d *articleDaoGORM) Recover(ctx context.Context, uid int64, id int64, deadline int64) error {
+	return d.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		now := time.Now().UnixMilli()
+		res := tx.Model(&Article{}).
+			Where("id = ? AND author_id = ? AND deleted_at > ?", id, uid, deadline).
+			Updates(map[string]any{
+				"deleted_at": 0,
+				"utime":      now,
+			})
+		if res.Error != nil {
+			return res.Error
+		}
+		if res.RowsAffected == 0 {
+			return ErrRecordNotFound
+		}
+		return tx.Model(&PublishedArticle{}).
+			Where("id = ? AND author_id = ? AND deleted_at > ?", id, uid, 0).
+			Updates(map[string]any{
+				"deleted_at": 0,
+				"utime":      now,
+			}).Error
+	})
+}
+
+func (d *articleDaoGORM) ListDeleted(ctx context.Context, uid int64, deadline int64, offset int, limit int) ([]Article, error) {
+	var res []Article
+	err := d.db.WithContext(ctx).
+		Where("author_id = ? AND deleted_at > ?", uid, deadline).
+		Order("deleted_at DESC").
+		Offset(offset).
+		Limit(limit).
+		Find(&res).Error
+	return res, err
+}
+
 func (d *articleDaoGORM) Sync(ctx context.Context, entity Article) (int64, error) {
 	var (
 		id  = entity.ID
@@ -101,6 +237,10 @@ func (d *articleDaoGORM) Sync(ctx context.Context, entity Article) (int64, error
 		}
 
 		entity.ID = id
+		// 草稿之前保存过的标签也要一起发表
+		if err = d.syncPubTags(tx, id); err != nil {
+			return err
+		}
 		publishArt := PublishedArticle(entity)
 		now := time.Now().UnixMilli()
 		publishArt.Utime = now
@@ -122,28 +262,250 @@ func (d *articleDaoGORM) Sync(ctx context.Context, entity Article) (int64, error
 func (d *articleDaoGORM) Update(ctx context.Context, entity Article) error {
 	now := time.Now().UnixMilli()
 	entity.Utime = now
-	res := d.db.WithContext(ctx).Model(&entity).
-		Where("id = ? and author_id = ?", entity.ID, entity.AuthorID).
-		Updates(entity)
-	err := res.Error
-	if err != nil {
-		return err
-	}
-	if res.RowsAffected == 0 {
-		return ErrPossibleIncorrectAuthor
-	}
-
-	return nil
+	return d.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		// 删除了的文章要先恢复才能修改
+		res := tx.Model(&entity).
+			Where("id = ? and author_id = ? and deleted_at = ?", entity.ID, entity.AuthorID, 0).
+			Updates(entity)
+		err := res.Error
+		if err != nil {
+			return err
+		}
+		if res.RowsAffected == 0 {
+			return ErrPossibleIncorrectAuthor
+		}
+		if err = d.setTags(tx, entity.ID, entity.Tags); err != nil {
+			return err
+		}
+		return d.insertRevision(tx, entity)
+	})
 }
 
 func (d *articleDaoGORM) Insert(ctx context.Context, entity Article) (int64, error) {
 	now := time.Now().UnixMilli()
 	entity.Utime = now
 	entity.Ctime = now
-	err := d.db.WithContext(ctx).Create(&entity).Error
+	err := d.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		err := tx.Create(&entity).Error
+		if err != nil {
+			return err
+		}
+		if err = d.setTags(tx, entity.ID, entity.Tags); err != nil {
+			return err
+		}
+		return d.insertRevision(tx, entity)
+	})
 	return entity.ID, err
 }
 
+// insertRevision 每次修改文章内容都记录一个版本，必须和修改在同一个事务里面
+func (d *articleDaoGORM) insertRevision(tx *gorm.DB, entity Article) error {
+	return tx.Create(&ArticleRevision{
+		ArticleID: entity.ID,
+		AuthorID:  entity.AuthorID,
+		Title:     entity.Title,
+		Content:   entity.Content,
+		Status:    entity.Status,
+		Ctime:     entity.Utime,
+	}).Error
+}
+
+// setTags 用 tags 覆盖文章原本的标签，tags 为 nil 的时候什么也不做
+func (d *articleDaoGORM) setTags(tx *gorm.DB, artId int64, tags []string) error {
+	if tags == nil {
+		return nil
+	}
+	if len(tags) == 0 {
+		return tx.Where("article_id = ?", artId).Delete(&ArticleTag{}).Error
+	}
+	now := time.Now().UnixMilli()
+	newTags := make([]Tag, 0, len(tags))
+	for _, name := range tags {
+		newTags = append(newTags, Tag{Name: name, Ctime: now})
+	}
+	// 已经有的标签不需要再插入
+	err := tx.Clauses(clause.OnConflict{DoNothing: true}).Create(&newTags).Error
+	if err != nil {
+		return err
+	}
+	var tagIds []int64
+	err = tx.Model(&Tag{}).Where("name IN ?", tags).Pluck("id", &tagIds).Error
+	if err != nil {
+		return err
+	}
+	err = tx.Where("article_id = ? AND tag_id NOT IN ?", artId, tagIds).
+		Delete(&ArticleTag{}).Error
+	if err != nil {
+		return err
+	}
+	artTags := make([]ArticleTag, 0, len(tagIds))
+	for _, tid := range tagIds {
+		artTags = append(artTags, ArticleTag{ArticleID: artId, TagID: tid, Ctime: now})
+	}
+	return tx.Clauses(clause.OnConflict{DoNothing: true}).Create(&artTags).Error
+}
+
+// syncPubTags 用制作库的标签覆盖线上的标签
+func (d *articleDaoGORM) syncPubTags(tx *gorm.DB, artId int64) error {
+	var tagIds []int64
+	err := tx.Model(&ArticleTag{}).Where("article_id = ?", artId).
+		Order("id").Pluck("tag_id", &tagIds).Error
+	if err != nil {
+		return err
+	}
+	err = tx.Where("article_id = ?", artId).Delete(&PublishedArticleTag{}).Error
+	if err != nil || len(tagIds) == 0 {
+		return err
+	}
+	now := time.Now().UnixMilli()
+	pubTags := make([]PublishedArticleTag, 0, len(tagIds))
+	for _, tid := range tagIds {
+		pubTags = append(pubTags, PublishedArticleTag{ArticleID: artId, TagID: tid, Ctime: now})
+	}
+	return tx.Create(&pubTags).Error
+}
+
+func (d *articleDaoGORM) GetTags(ctx context.Context, artIds []int64) (map[int64][]string, error) {
+	return d.getTags(ctx, "article_tags", artIds)
+}
+
+func (d *articleDaoGORM) GetPubTags(ctx context.Context, artIds []int64) (map[int64][]string, error) {
+	return d.getTags(ctx, "published_article_tags", artIds)
+}
+
+// getTags table 是 article_tags 或者 published_article_tags
+func (d *articleDaoGORM) getTags(ctx context.Context, table string, artIds []int64) (map[int64][]string, error) {
+	res := make(map[int64][]string, len(artIds))
+	if len(artIds) == 0 {
+		return res, nil
+	}
+	var rows []struct {
+		ArticleID int64
+		Name      string
+	}
+	err := d.db.WithContext(ctx).Table(table).
+		Select(table+".article_id, tags.name").
+		Joins("JOIN tags ON tags.id = "+table+".tag_id").
+		Where(table+".article_id IN ?", artIds).
+		Order(table + ".id").
+		Scan(&rows).Error
+	if err != nil {
+		return nil, err
+	}
+	for _, row := range rows {
+		res[row.ArticleID] = append(res[row.ArticleID], row.Name)
+	}
+	return res, nil
+}
+
+func (d *articleDaoGORM) ListByTag(ctx context.Context, tag string, status uint8, offset int, limit int) ([]PublishedArticle, error) {
+	var res []PublishedArticle
+	err := d.db.WithContext(ctx).Model(&PublishedArticle{}).
+		Select("published_articles.*").
+		Joins("JOIN published_article_tags ON published_article_tags.article_id = published_articles.id").
+		Joins("JOIN tags ON tags.id = published_article_tags.tag_id").
+		Where("tags.name = ? AND published_articles.status = ? AND published_articles.deleted_at = ?",
+			tag, status, 0).
+		Order("published_articles.utime DESC").
+		Offset(offset).
+		Limit(limit).
+		Find(&res).Error
+	return res, err
+}
+
+func (d *articleDaoGORM) PopularTags(ctx context.Context, status uint8, n int) ([]TagCount, error) {
+	var res []TagCount
+	err := d.db.WithContext(ctx).Model(&PublishedArticleTag{}).
+		Select("tags.name AS name, COUNT(*) AS cnt").
+		Joins("JOIN tags ON tags.id = published_article_tags.tag_id").
+		Joins("JOIN published_articles ON published_articles.id = published_article_tags.article_id").
+		Where("published_articles.status = ? AND published_articles.deleted_at = ?", status, 0).
+		Group("tags.id, tags.name").
+		Order("cnt DESC").
+		Limit(n).
+		Scan(&res).Error
+	return res, err
+}
+
+func (d *articleDaoGORM) ListRevisions(ctx context.Context, uid int64, artId int64, offset int, limit int) ([]ArticleRevision, error) {
+	var res []ArticleRevision
+	err := d.db.WithContext(ctx).
+		Where("article_id = ? AND author_id = ?", artId, uid).
+		Order("ctime DESC, id DESC").
+		Offset(offset).
+		Limit(limit).
+		Find(&res).Error
+	return res, err
+}
+
+func (d *articleDaoGORM) GetRevision(ctx context.Context, id int64) (ArticleRevision, error) {
+	var res ArticleRevision
+	err := d.db.WithContext(ctx).Where("id = ?", id).First(&res).Error
+	return res, err
+}
+
 func NewArticleDaoGORM(db *gorm.DB) ArticleDao {
 	return &articleDaoGORM{db: db}
 }
+
+func (d *articleDaoGORM) ListPubIds(ctx context.Context, author int64) ([]int64, error) {
+	var res []int64
+	err := d.db.WithContext(ctx).Model(&PublishedArticle{}).
+		Where("author_id = ? AND deleted_at = ?", author, 0).
+		Pluck("id", &res).Error
+	return res, err
+}
+
+func (d *articleDaoGORM) WithdrawByAuthor(ctx context.Context, author int64, published uint8, scheduled uint8, to uint8) ([]int64, error) {
+	var ids []int64
+	err := d.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		// 线上的状态要看线上库，制作库的草稿可能已经改回了未发表，但是线上那篇还在
+		var pubIds []int64
+		err := tx.Model(&PublishedArticle{}).
+			Where("author_id = ? AND status = ? AND deleted_at = ?", author, published, 0).
+			Pluck("id", &pubIds).Error
+		if err != nil {
+			return err
+		}
+		// 定时发表的还没到线上库，只能看制作库
+		var draftIds []int64
+		err = tx.Model(&Article{}).
+			Where("author_id = ? AND status = ? AND deleted_at = ?", author, scheduled, 0).
+			Pluck("id", &draftIds).Error
+		if err != nil {
+			return err
+		}
+		ids = mergeIds(pubIds, draftIds)
+		if len(ids) == 0 {
+			return nil
+		}
+		now := time.Now().UnixMilli()
+		err = tx.Model(&Article{}).Where("id IN ?", ids).
+			Updates(map[string]any{
+				"status": to,
+				"utime":  now,
+			}).Error
+		if err != nil {
+			return err
+		}
+		return tx.Model(&PublishedArticle{}).Where("id IN ?", ids).
+			Updates(map[string]any{
+				"status": to,
+				"utime":  now,
+			}).Error
+	})
+	return ids, err
+}
+
+func mergeIds(a, b []int64) []int64 {
+	seen := make(map[int64]struct{}, len(a)+len(b))
+	res := make([]int64, 0, len(a)+len(b))
+	for _, id := range append(a, b...) {
+		if _, ok := seen[id]; ok {
+			continue
+		}
+		seen[id] = struct{}{}
+		res = append(res, id)
+	}
+	return res
+}
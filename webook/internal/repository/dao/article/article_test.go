@@ -0,0 +1,423 @@
+package article
+
+import (
+	"context"
+	"database/sql"
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	gormMysql "gorm.io/driver/mysql"
+	"gorm.io/gorm"
+	"regexp"
+	"testing"
+)
+
+func Test_articleDaoGORM_WithdrawByAuthor(t *testing.T) {
+	testCases := []struct {
+		name string
+		mock func(t *testing.T) *sql.DB
+
+		wantIds []int64
+		wantErr error
+	}{
+		{
+			name: "草稿改回了未发表，线上的也要撤回",
+			mock: func(t *testing.T) *sql.DB {
+				mockDB, mock, err := sqlmock.New()
+				require.NoError(t, err)
+				mock.ExpectBegin()
+				// 1 在线上库是已发表，制作库的草稿已经是未发表了
+				mock.ExpectQuery(regexp.QuoteMeta("SELECT `id` FROM `published_articles` WHERE author_id = ? AND status = ? AND deleted_at = ?")).
+					WithArgs(123, 2, 0).
+					WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(1))
+				// 2 是定时发表的
+				mock.ExpectQuery(regexp.QuoteMeta("SELECT `id` FROM `articles` WHERE author_id = ? AND status = ? AND deleted_at = ?")).
+					WithArgs(123, 4, 0).
+					WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(2))
+				mock.ExpectExec(regexp.QuoteMeta("UPDATE `articles` SET `status`=?,`utime`=? WHERE id IN (?,?)")).
+					WithArgs(3, sqlmock.AnyArg(), 1, 2).
+					WillReturnResult(sqlmock.NewResult(0, 2))
+				mock.ExpectExec(regexp.QuoteMeta("UPDATE `published_articles` SET `status`=?,`utime`=? WHERE id IN (?,?)")).
+					WithArgs(3, sqlmock.AnyArg(), 1, 2).
+					WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectCommit()
+				return mockDB
+			},
+			wantIds: []int64{1, 2},
+		},
+		{
+			name: "两边都有，不会重复",
+			mock: func(t *testing.T) *sql.DB {
+				mockDB, mock, err := sqlmock.New()
+				require.NoError(t, err)
+				mock.ExpectBegin()
+				mock.ExpectQuery("SELECT `id` FROM `published_articles` .*").
+					WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(1))
+				mock.ExpectQuery("SELECT `id` FROM `articles` .*").
+					WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(1))
+				mock.ExpectExec(regexp.QuoteMeta("UPDATE `articles` SET `status`=?,`utime`=? WHERE id IN (?)")).
+					WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectExec(regexp.QuoteMeta("UPDATE `published_articles` SET `status`=?,`utime`=? WHERE id IN (?)")).
+					WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectCommit()
+				return mockDB
+			},
+			wantIds: []int64{1},
+		},
+		{
+			name: "没有要撤回的",
+			mock: func(t *testing.T) *sql.DB {
+				mockDB, mock, err := sqlmock.New()
+				require.NoError(t, err)
+				mock.ExpectBegin()
+				mock.ExpectQuery("SELECT `id` FROM `published_articles` .*").
+					WillReturnRows(sqlmock.NewRows([]string{"id"}))
+				mock.ExpectQuery("SELECT `id` FROM `articles` .*").
+					WillReturnRows(sqlmock.NewRows([]string{"id"}))
+				mock.ExpectCommit()
+				return mockDB
+			},
+			wantIds: []int64{},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			d := NewArticleDaoGORM(newMockDB(t, tc.mock(t)))
+			ids, err := d.WithdrawByAuthor(context.Background(), 123, 2, 4, 3)
+			assert.Equal(t, tc.wantErr, err)
+			assert.Equal(t, tc.wantIds, ids)
+		})
+	}
+}
+
+func Test_articleDaoGORM_TransitStatus(t *testing.T) {
+	testCases := []struct {
+		name string
+		mock func(t *testing.T) *sql.DB
+
+		wantOk bool
+	}{
+		{
+			name: "状态对得上，抢到了",
+			mock: func(t *testing.T) *sql.DB {
+				mockDB, mock, err := sqlmock.New()
+				require.NoError(t, err)
+				mock.ExpectExec(regexp.QuoteMeta("UPDATE `articles` SET `status`=?,`utime`=? WHERE id = ? AND status = ?")).
+					WithArgs(2, sqlmock.AnyArg(), 1, 4).
+					WillReturnResult(sqlmock.NewResult(0, 1))
+				return mockDB
+			},
+			wantOk: true,
+		},
+		{
+			name: "别的实例已经改了状态",
+			mock: func(t *testing.T) *sql.DB {
+				mockDB, mock, err := sqlmock.New()
+				require.NoError(t, err)
+				mock.ExpectExec(regexp.QuoteMeta("UPDATE `articles` SET `status`=?,`utime`=? WHERE id = ? AND status = ?")).
+					WithArgs(2, sqlmock.AnyArg(), 1, 4).
+					WillReturnResult(sqlmock.NewResult(0, 0))
+				return mockDB
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			d := NewArticleDaoGORM(newMockDB(t, tc.mock(t)))
+			ok, err := d.TransitStatus(context.Background(), 1, 4, 2)
+			assert.NoError(t, err)
+			assert.Equal(t, tc.wantOk, ok)
+		})
+	}
+}
+
+func Test_articleDaoGORM_ListScheduled(t *testing.T) {
+	mockDB, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	// 只查发表时间已经到了的
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT * FROM `articles` WHERE status = ? AND publish_time <= ? AND deleted_at = ? ORDER BY publish_time ASC LIMIT 10")).
+		WithArgs(4, 1000, 0).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "status", "publish_time"}).AddRow(1, 4, 900))
+
+	d := NewArticleDaoGORM(newMockDB(t, mockDB))
+	arts, err := d.ListScheduled(context.Background(), 4, 1000, 10)
+	require.NoError(t, err)
+	assert.Equal(t, []Article{{ID: 1, Status: 4, PublishTime: 900}}, arts)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func Test_articleDaoGORM_ListPub(t *testing.T) {
+	mockDB, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	cols := []string{"id", "status", "utime"}
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT * FROM `published_articles` WHERE status = ? AND deleted_at = ? ORDER BY utime DESC, id DESC LIMIT 2")).
+		WithArgs(2, 0).
+		WillReturnRows(sqlmock.NewRows(cols).AddRow(3, 2, 1000).AddRow(2, 2, 1000))
+	// 游标停在两篇 utime 相同的文章中间，utime 相同的要按照 id 接着往下翻
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT * FROM `published_articles` WHERE (status = ? AND deleted_at = ?) AND (utime < ? OR (utime = ? AND id < ?)) ORDER BY utime DESC, id DESC LIMIT 2")).
+		WithArgs(2, 0, 1000, 1000, 2).
+		WillReturnRows(sqlmock.NewRows(cols).AddRow(1, 2, 1000).AddRow(4, 2, 900))
+
+	d := NewArticleDaoGORM(newMockDB(t, mockDB))
+	arts, err := d.ListPub(context.Background(), 2, 0, 0, 2)
+	require.NoError(t, err)
+	assert.Equal(t, []PublishedArticle{{ID: 3, Status: 2, Utime: 1000}, {ID: 2, Status: 2, Utime: 1000}}, arts)
+	last := arts[len(arts)-1]
+	arts, err = d.ListPub(context.Background(), 2, last.Utime, last.ID, 2)
+	require.NoError(t, err)
+	assert.Equal(t, []PublishedArticle{{ID: 1, Status: 2, Utime: 1000}, {ID: 4, Status: 2, Utime: 900}}, arts)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func Test_articleDaoGORM_Revision(t *testing.T) {
+	testCases := []struct {
+		name string
+		mock func(t *testing.T) *sql.DB
+		save func(d ArticleDao) error
+
+		wantErr error
+	}{
+		{
+			name: "新建文章，记录第一个版本",
+			mock: func(t *testing.T) *sql.DB {
+				mockDB, mock, err := sqlmock.New()
+				require.NoError(t, err)
+				mock.ExpectBegin()
+				mock.ExpectExec("INSERT INTO `articles` .*").
+					WillReturnResult(sqlmock.NewResult(1, 1))
+				mock.ExpectExec("INSERT INTO `article_revisions` .*").
+					WithArgs(1, 123, "标题", "内容", 1, sqlmock.AnyArg()).
+					WillReturnResult(sqlmock.NewResult(1, 1))
+				mock.ExpectCommit()
+				return mockDB
+			},
+			save: func(d ArticleDao) error {
+				_, err := d.Insert(context.Background(), Article{AuthorID: 123, Title: "标题", Content: "内容", Status: 1})
+				return err
+			},
+		},
+		{
+			name: "修改文章，记录一个新的版本",
+			mock: func(t *testing.T) *sql.DB {
+				mockDB, mock, err := sqlmock.New()
+				require.NoError(t, err)
+				mock.ExpectBegin()
+				mock.ExpectExec("UPDATE `articles` SET .*").
+					WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectExec("INSERT INTO `article_revisions` .*").
+					WithArgs(1, 123, "新的标题", "新的内容", 1, sqlmock.AnyArg()).
+					WillReturnResult(sqlmock.NewResult(2, 1))
+				mock.ExpectCommit()
+				return mockDB
+			},
+			save: func(d ArticleDao) error {
+				return d.Update(context.Background(), Article{ID: 1, AuthorID: 123, Title: "新的标题", Content: "新的内容", Status: 1})
+			},
+		},
+		{
+			name: "修改别人的文章，不记录版本",
+			mock: func(t *testing.T) *sql.DB {
+				mockDB, mock, err := sqlmock.New()
+				require.NoError(t, err)
+				mock.ExpectBegin()
+				mock.ExpectExec("UPDATE `articles` SET .*").
+					WillReturnResult(sqlmock.NewResult(0, 0))
+				mock.ExpectRollback()
+				return mockDB
+			},
+			save: func(d ArticleDao) error {
+				return d.Update(context.Background(), Article{ID: 1, AuthorID: 234, Title: "新的标题", Content: "新的内容", Status: 1})
+			},
+			wantErr: ErrPossibleIncorrectAuthor,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			d := NewArticleDaoGORM(newMockDB(t, tc.mock(t)))
+			err := tc.save(d)
+			assert.Equal(t, tc.wantErr, err)
+		})
+	}
+}
+
+func Test_articleDaoGORM_SyncPubTags(t *testing.T) {
+	mockDB, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	mock.ExpectBegin()
+	mock.ExpectExec("SAVEPOINT .*").WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectExec("UPDATE `articles` SET .*").WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec("INSERT INTO `article_revisions` .*").WillReturnResult(sqlmock.NewResult(1, 1))
+	// 发表的时候才把制作库的标签复制到线上
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT `tag_id` FROM `article_tags` WHERE article_id = ? ORDER BY id")).
+		WithArgs(1).
+		WillReturnRows(sqlmock.NewRows([]string{"tag_id"}).AddRow(10).AddRow(11))
+	mock.ExpectExec(regexp.QuoteMeta("DELETE FROM `published_article_tags` WHERE article_id = ?")).
+		WithArgs(1).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec(regexp.QuoteMeta("INSERT INTO `published_article_tags` (`article_id`,`tag_id`,`ctime`) VALUES (?,?,?),(?,?,?)")).
+		WithArgs(1, 10, sqlmock.AnyArg(), 1, 11, sqlmock.AnyArg()).
+		WillReturnResult(sqlmock.NewResult(1, 2))
+	mock.ExpectExec("INSERT INTO `published_articles` .*").WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectCommit()
+
+	d := NewArticleDaoGORM(newMockDB(t, mockDB))
+	id, err := d.Sync(context.Background(), Article{ID: 1, AuthorID: 123, Title: "标题", Status: 2})
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), id)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func Test_articleDaoGORM_ListByTag(t *testing.T) {
+	mockDB, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	// 标签页只看线上的标签，草稿改了标签不影响
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT published_articles.* FROM `published_articles` "+
+		"JOIN published_article_tags ON published_article_tags.article_id = published_articles.id "+
+		"JOIN tags ON tags.id = published_article_tags.tag_id "+
+		"WHERE tags.name = ? AND published_articles.status = ? AND published_articles.deleted_at = ? "+
+		"ORDER BY published_articles.utime DESC LIMIT 10")).
+		WithArgs("go", 2, 0).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "status"}).AddRow(1, 2))
+
+	d := NewArticleDaoGORM(newMockDB(t, mockDB))
+	arts, err := d.ListByTag(context.Background(), "go", 2, 0, 10)
+	require.NoError(t, err)
+	assert.Equal(t, []PublishedArticle{{ID: 1, Status: 2}}, arts)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func Test_articleDaoGORM_DeletedHidden(t *testing.T) {
+	// 删除了的文章，读者、作者、标签下面都查不到
+	testCases := []struct {
+		name  string
+		mock  func(mock sqlmock.Sqlmock)
+		query func(d ArticleDao) error
+
+		wantErr error
+	}{
+		{
+			name: "读者",
+			mock: func(mock sqlmock.Sqlmock) {
+				mock.ExpectQuery(regexp.QuoteMeta("SELECT * FROM `published_articles` WHERE id = ? AND deleted_at = ?")).
+					WithArgs(1, 0).
+					WillReturnRows(sqlmock.NewRows([]string{"id"}))
+			},
+			query: func(d ArticleDao) error {
+				_, err := d.GetPubById(context.Background(), 1)
+				return err
+			},
+			wantErr: ErrRecordNotFound,
+		},
+		{
+			name: "作者",
+			mock: func(mock sqlmock.Sqlmock) {
+				mock.ExpectQuery(regexp.QuoteMeta("SELECT * FROM `articles` WHERE author_id = ? AND deleted_at = ?")).
+					WithArgs(123, 0).
+					WillReturnRows(sqlmock.NewRows([]string{"id"}))
+			},
+			query: func(d ArticleDao) error {
+				_, err := d.GetByAuthor(context.Background(), 123, 0, 10)
+				return err
+			},
+		},
+		{
+			name: "作者查看详情",
+			mock: func(mock sqlmock.Sqlmock) {
+				mock.ExpectQuery(regexp.QuoteMeta("SELECT * FROM `articles` WHERE id = ? AND deleted_at = ?")).
+					WithArgs(1, 0).
+					WillReturnRows(sqlmock.NewRows([]string{"id"}))
+			},
+			query: func(d ArticleDao) error {
+				_, err := d.GetById(context.Background(), 1)
+				return err
+			},
+			wantErr: ErrRecordNotFound,
+		},
+		{
+			name: "标签",
+			mock: func(mock sqlmock.Sqlmock) {
+				mock.ExpectQuery(regexp.QuoteMeta("WHERE tags.name = ? AND published_articles.status = ? AND published_articles.deleted_at = ?")).
+					WithArgs("go", 2, 0).
+					WillReturnRows(sqlmock.NewRows([]string{"id"}))
+			},
+			query: func(d ArticleDao) error {
+				_, err := d.ListByTag(context.Background(), "go", 2, 0, 10)
+				return err
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			mockDB, mock, err := sqlmock.New()
+			require.NoError(t, err)
+			tc.mock(mock)
+			err = tc.query(NewArticleDaoGORM(newMockDB(t, mockDB)))
+			assert.Equal(t, tc.wantErr, err)
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
+
+func Test_articleDaoGORM_Recover(t *testing.T) {
+	testCases := []struct {
+		name string
+		mock func(t *testing.T) *sql.DB
+
+		wantErr error
+	}{
+		{
+			name: "恢复成功，两个库都恢复",
+			mock: func(t *testing.T) *sql.DB {
+				mockDB, mock, err := sqlmock.New()
+				require.NoError(t, err)
+				mock.ExpectBegin()
+				mock.ExpectExec(regexp.QuoteMeta("UPDATE `articles` SET `deleted_at`=?,`utime`=? WHERE id = ? AND author_id = ? AND deleted_at > ?")).
+					WithArgs(0, sqlmock.AnyArg(), 1, 123, 1000).
+					WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectExec(regexp.QuoteMeta("UPDATE `published_articles` SET `deleted_at`=?,`utime`=? WHERE id = ? AND author_id = ? AND deleted_at > ?")).
+					WithArgs(0, sqlmock.AnyArg(), 1, 123, 0).
+					WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectCommit()
+				return mockDB
+			},
+		},
+		{
+			name: "超过保留期或者不是作者",
+			mock: func(t *testing.T) *sql.DB {
+				mockDB, mock, err := sqlmock.New()
+				require.NoError(t, err)
+				mock.ExpectBegin()
+				mock.ExpectExec("UPDATE `articles` SET .*").
+					WillReturnResult(sqlmock.NewResult(0, 0))
+				mock.ExpectRollback()
+				return mockDB
+			},
+			wantErr: ErrRecordNotFound,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			d := NewArticleDaoGORM(newMockDB(t, tc.mock(t)))
+			err := d.Recover(context.Background(), 123, 1, 1000)
+			assert.Equal(t, tc.wantErr, err)
+		})
+	}
+}
+
+func newMockDB(t *testing.T, conn *sql.DB) *gorm.DB {
+	db, err := gorm.Open(gormMysql.New(gormMysql.Config{
+		Conn:                      conn,
+		SkipInitializeWithVersion: true,
+	}), &gorm.Config{
+		DisableAutomaticPing:   true,
+		SkipDefaultTransaction: true,
+		TranslateError:         true,
+	})
+	require.NoError(t, err)
+	return db
+}
@@ -8,7 +8,69 @@ type Article struct {
 	Status   uint8
 	Utime    int64 `gorm:"index:idx_authorID_utime, priority:2"`
 	Ctime    int64
+	// 定时发表的时间，毫秒数
+	PublishTime int64 `gorm:"index"`
+	// 软删除的时间，毫秒数，0 表示没有删除
+	DeletedAt int64 `gorm:"index"`
+	// Tags 不是数据库的列，不为 nil 的时候，保存文章的同时覆盖文章的标签
+	Tags []string `gorm:"-"`
 }
 
-// PublishedArticle 衍生类型，偷个懒
-type PublishedArticle Article
+// PublishedArticle 线上库，字段和 Article 一模一样，可以直接互相转换。
+// 单独定义是为了加上按照 status + utime 翻页的索引
+type PublishedArticle struct {
+	ID       int64  `gorm:"primaryKey,autoIncrement;index:idx_status_utime, priority:3"`
+	Title    string `gorm:"type:varchar(255),size:255"`
+	Content  string `gorm:"type:BLOB"`
+	AuthorID int64  `gorm:"index:idx_authorID_utime, priority:1"`
+	Status   uint8  `gorm:"index:idx_status_utime, priority:1"`
+	Utime    int64  `gorm:"index:idx_authorID_utime, priority:2;index:idx_status_utime, priority:2"`
+	Ctime    int64
+	// 定时发表的时间，毫秒数
+	PublishTime int64 `gorm:"index"`
+	// 软删除的时间，毫秒数，0 表示没有删除
+	DeletedAt int64 `gorm:"index"`
+	// Tags 不是数据库的列
+	Tags []string `gorm:"-"`
+}
+
+// ArticleRevision 文章的历史版本，只插入不修改
+type ArticleRevision struct {
+	ID        int64 `gorm:"primaryKey,autoIncrement"`
+	ArticleID int64 `gorm:"index:idx_articleID_ctime, priority:1"`
+	AuthorID  int64
+	Title     string `gorm:"type:varchar(255),size:255"`
+	Content   string `gorm:"type:BLOB"`
+	Status    uint8
+	Ctime     int64 `gorm:"index:idx_articleID_ctime, priority:2"`
+}
+
+// Tag 标签本身，同名标签只有一个
+type Tag struct {
+	ID    int64  `gorm:"primaryKey,autoIncrement"`
+	Name  string `gorm:"type:varchar(64);uniqueIndex"`
+	Ctime int64
+}
+
+// ArticleTag 文章和标签的关联关系，是作者正在编辑的标签
+type ArticleTag struct {
+	ID        int64 `gorm:"primaryKey,autoIncrement"`
+	ArticleID int64 `gorm:"uniqueIndex:uk_article_tag"`
+	TagID     int64 `gorm:"uniqueIndex:uk_article_tag;index"`
+	Ctime     int64
+}
+
+// PublishedArticleTag 线上文章的标签，发表的时候从 ArticleTag 复制过来，
+// 这样修改草稿的标签不会影响线上的标签页
+type PublishedArticleTag struct {
+	ID        int64 `gorm:"primaryKey,autoIncrement"`
+	ArticleID int64 `gorm:"uniqueIndex:uk_article_tag"`
+	TagID     int64 `gorm:"uniqueIndex:uk_article_tag;index"`
+	Ctime     int64
+}
+
+// TagCount 按照标签统计的文章数量
+type TagCount struct {
+	Name string
+	Cnt  int64
+}
@@ -0,0 +1,367 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: article.go
+//
+// Generated by this command:
+//
+//	mockgen -source=article.go -destination=mocks/mock_article.go --package=mock_article
+//
+
+// Package mock_article is a generated GoMock package.
+package mock_article
+
+import (
+	context "context"
+	reflect "reflect"
+
+	article "gitee.com/geekbang/basic-go/webook/internal/repository/dao/article"
+	gomock "go.uber.org/mock/gomock"
+)
+
+// MockArticleDao is a mock of ArticleDao interface.
+type MockArticleDao struct {
+	ctrl     *gomock.Controller
+	recorder *MockArticleDaoMockRecorder
+}
+
+// MockArticleDaoMockRecorder is the mock recorder for MockArticleDao.
+type MockArticleDaoMockRecorder struct {
+	mock *MockArticleDao
+}
+
+// NewMockArticleDao creates a new mock instance.
+func NewMockArticleDao(ctrl *gomock.Controller) *MockArticleDao {
+	mock := &MockArticleDao{ctrl: ctrl}
+	mock.recorder = &MockArticleDaoMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockArticleDao) EXPECT() *MockArticleDaoMockRecorder {
+	return m.recorder
+}
+
+// CancelSchedule mocks base method.
+func (m *MockArticleDao) CancelSchedule(ctx context.Context, uid, id int64, from, to uint8) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CancelSchedule", ctx, uid, id, from, to)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// CancelSchedule indicates an expected call of CancelSchedule.
+func (mr *MockArticleDaoMockRecorder) CancelSchedule(ctx, uid, id, from, to any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CancelSchedule", reflect.TypeOf((*MockArticleDao)(nil).CancelSchedule), ctx, uid, id, from, to)
+}
+
+// Delete mocks base method.
+func (m *MockArticleDao) Delete(ctx context.Context, uid, id int64) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Delete", ctx, uid, id)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Delete indicates an expected call of Delete.
+func (mr *MockArticleDaoMockRecorder) Delete(ctx, uid, id any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Delete", reflect.TypeOf((*MockArticleDao)(nil).Delete), ctx, uid, id)
+}
+
+// GetByAuthor mocks base method.
+func (m *MockArticleDao) GetByAuthor(ctx context.Context, uid int64, offset, limit int) ([]article.Article, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetByAuthor", ctx, uid, offset, limit)
+	ret0, _ := ret[0].([]article.Article)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetByAuthor indicates an expected call of GetByAuthor.
+func (mr *MockArticleDaoMockRecorder) GetByAuthor(ctx, uid, offset, limit any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetByAuthor", reflect.TypeOf((*MockArticleDao)(nil).GetByAuthor), ctx, uid, offset, limit)
+}
+
+// GetById mocks base method.
+func (m *MockArticleDao) GetById(ctx context.Context, id int64) (article.Article, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetById", ctx, id)
+	ret0, _ := ret[0].(article.Article)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetById indicates an expected call of GetById.
+func (mr *MockArticleDaoMockRecorder) GetById(ctx, id any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetById", reflect.TypeOf((*MockArticleDao)(nil).GetById), ctx, id)
+}
+
+// GetPubById mocks base method.
+func (m *MockArticleDao) GetPubById(ctx context.Context, id int64) (article.PublishedArticle, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetPubById", ctx, id)
+	ret0, _ := ret[0].(article.PublishedArticle)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetPubById indicates an expected call of GetPubById.
+func (mr *MockArticleDaoMockRecorder) GetPubById(ctx, id any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetPubById", reflect.TypeOf((*MockArticleDao)(nil).GetPubById), ctx, id)
+}
+
+// GetPubTags mocks base method.
+func (m *MockArticleDao) GetPubTags(ctx context.Context, artIds []int64) (map[int64][]string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetPubTags", ctx, artIds)
+	ret0, _ := ret[0].(map[int64][]string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetPubTags indicates an expected call of GetPubTags.
+func (mr *MockArticleDaoMockRecorder) GetPubTags(ctx, artIds any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetPubTags", reflect.TypeOf((*MockArticleDao)(nil).GetPubTags), ctx, artIds)
+}
+
+// GetRevision mocks base method.
+func (m *MockArticleDao) GetRevision(ctx context.Context, id int64) (article.ArticleRevision, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetRevision", ctx, id)
+	ret0, _ := ret[0].(article.ArticleRevision)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetRevision indicates an expected call of GetRevision.
+func (mr *MockArticleDaoMockRecorder) GetRevision(ctx, id any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetRevision", reflect.TypeOf((*MockArticleDao)(nil).GetRevision), ctx, id)
+}
+
+// GetTags mocks base method.
+func (m *MockArticleDao) GetTags(ctx context.Context, artIds []int64) (map[int64][]string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetTags", ctx, artIds)
+	ret0, _ := ret[0].(map[int64][]string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetTags indicates an expected call of GetTags.
+func (mr *MockArticleDaoMockRecorder) GetTags(ctx, artIds any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetTags", reflect.TypeOf((*MockArticleDao)(nil).GetTags), ctx, artIds)
+}
+
+// Insert mocks base method.
+func (m *MockArticleDao) Insert(ctx context.Context, entity article.Article) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Insert", ctx, entity)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Insert indicates an expected call of Insert.
+func (mr *MockArticleDaoMockRecorder) Insert(ctx, entity any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Insert", reflect.TypeOf((*MockArticleDao)(nil).Insert), ctx, entity)
+}
+
+// ListByTag mocks base method.
+func (m *MockArticleDao) ListByTag(ctx context.Context, tag string, status uint8, offset, limit int) ([]article.PublishedArticle, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListByTag", ctx, tag, status, offset, limit)
+	ret0, _ := ret[0].([]article.PublishedArticle)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListByTag indicates an expected call of ListByTag.
+func (mr *MockArticleDaoMockRecorder) ListByTag(ctx, tag, status, offset, limit any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListByTag", reflect.TypeOf((*MockArticleDao)(nil).ListByTag), ctx, tag, status, offset, limit)
+}
+
+// ListDeleted mocks base method.
+func (m *MockArticleDao) ListDeleted(ctx context.Context, uid, deadline int64, offset, limit int) ([]article.Article, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListDeleted", ctx, uid, deadline, offset, limit)
+	ret0, _ := ret[0].([]article.Article)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListDeleted indicates an expected call of ListDeleted.
+func (mr *MockArticleDaoMockRecorder) ListDeleted(ctx, uid, deadline, offset, limit any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListDeleted", reflect.TypeOf((*MockArticleDao)(nil).ListDeleted), ctx, uid, deadline, offset, limit)
+}
+
+// ListPub mocks base method.
+func (m *MockArticleDao) ListPub(ctx context.Context, status uint8, utime, id int64, limit int) ([]article.PublishedArticle, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListPub", ctx, status, utime, id, limit)
+	ret0, _ := ret[0].([]article.PublishedArticle)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListPub indicates an expected call of ListPub.
+func (mr *MockArticleDaoMockRecorder) ListPub(ctx, status, utime, id, limit any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListPub", reflect.TypeOf((*MockArticleDao)(nil).ListPub), ctx, status, utime, id, limit)
+}
+
+// ListPubIds mocks base method.
+func (m *MockArticleDao) ListPubIds(ctx context.Context, author int64) ([]int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListPubIds", ctx, author)
+	ret0, _ := ret[0].([]int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListPubIds indicates an expected call of ListPubIds.
+func (mr *MockArticleDaoMockRecorder) ListPubIds(ctx, author any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListPubIds", reflect.TypeOf((*MockArticleDao)(nil).ListPubIds), ctx, author)
+}
+
+// ListRevisions mocks base method.
+func (m *MockArticleDao) ListRevisions(ctx context.Context, uid, artId int64, offset, limit int) ([]article.ArticleRevision, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListRevisions", ctx, uid, artId, offset, limit)
+	ret0, _ := ret[0].([]article.ArticleRevision)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListRevisions indicates an expected call of ListRevisions.
+func (mr *MockArticleDaoMockRecorder) ListRevisions(ctx, uid, artId, offset, limit any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListRevisions", reflect.TypeOf((*MockArticleDao)(nil).ListRevisions), ctx, uid, artId, offset, limit)
+}
+
+// ListScheduled mocks base method.
+func (m *MockArticleDao) ListScheduled(ctx context.Context, status uint8, publishTime int64, limit int) ([]article.Article, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListScheduled", ctx, status, publishTime, limit)
+	ret0, _ := ret[0].([]article.Article)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListScheduled indicates an expected call of ListScheduled.
+func (mr *MockArticleDaoMockRecorder) ListScheduled(ctx, status, publishTime, limit any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListScheduled", reflect.TypeOf((*MockArticleDao)(nil).ListScheduled), ctx, status, publishTime, limit)
+}
+
+// PopularTags mocks base method.
+func (m *MockArticleDao) PopularTags(ctx context.Context, status uint8, n int) ([]article.TagCount, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "PopularTags", ctx, status, n)
+	ret0, _ := ret[0].([]article.TagCount)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// PopularTags indicates an expected call of PopularTags.
+func (mr *MockArticleDaoMockRecorder) PopularTags(ctx, status, n any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PopularTags", reflect.TypeOf((*MockArticleDao)(nil).PopularTags), ctx, status, n)
+}
+
+// Recover mocks base method.
+func (m *MockArticleDao) Recover(ctx context.Context, uid, id, deadline int64) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Recover", ctx, uid, id, deadline)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Recover indicates an expected call of Recover.
+func (mr *MockArticleDaoMockRecorder) Recover(ctx, uid, id, deadline any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Recover", reflect.TypeOf((*MockArticleDao)(nil).Recover), ctx, uid, id, deadline)
+}
+
+// Sync mocks base method.
+func (m *MockArticleDao) Sync(ctx context.Context, entity article.Article) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Sync", ctx, entity)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Sync indicates an expected call of Sync.
+func (mr *MockArticleDaoMockRecorder) Sync(ctx, entity any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Sync", reflect.TypeOf((*MockArticleDao)(nil).Sync), ctx, entity)
+}
+
+// SyncStatus mocks base method.
+func (m *MockArticleDao) SyncStatus(ctx context.Context, uid, id int64, status uint8) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SyncStatus", ctx, uid, id, status)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SyncStatus indicates an expected call of SyncStatus.
+func (mr *MockArticleDaoMockRecorder) SyncStatus(ctx, uid, id, status any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SyncStatus", reflect.TypeOf((*MockArticleDao)(nil).SyncStatus), ctx, uid, id, status)
+}
+
+// TransitStatus mocks base method.
+func (m *MockArticleDao) TransitStatus(ctx context.Context, id int64, from, to uint8) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "TransitStatus", ctx, id, from, to)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// TransitStatus indicates an expected call of TransitStatus.
+func (mr *MockArticleDaoMockRecorder) TransitStatus(ctx, id, from, to any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "TransitStatus", reflect.TypeOf((*MockArticleDao)(nil).TransitStatus), ctx, id, from, to)
+}
+
+// Update mocks base method.
+func (m *MockArticleDao) Update(ctx context.Context, entity article.Article) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Update", ctx, entity)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Update indicates an expected call of Update.
+func (mr *MockArticleDaoMockRecorder) Update(ctx, entity any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Update", reflect.TypeOf((*MockArticleDao)(nil).Update), ctx, entity)
+}
+
+// WithdrawByAuthor mocks base method.
+func (m *MockArticleDao) WithdrawByAuthor(ctx context.Context, author int64, published, scheduled, to uint8) ([]int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "WithdrawByAuthor", ctx, author, published, scheduled, to)
+	ret0, _ := ret[0].([]int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// WithdrawByAuthor indicates an expected call of WithdrawByAuthor.
+func (mr *MockArticleDaoMockRecorder) WithdrawByAuthor(ctx, author, published, scheduled, to any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "WithdrawByAuthor", reflect.TypeOf((*MockArticleDao)(nil).WithdrawByAuthor), ctx, author, published, scheduled, to)
+}
@@ -0,0 +1,101 @@
+package comment
+
+import (
+	"context"
+	"gorm.io/gorm"
+	"time"
+)
+
+var ErrRecordNotFound = gorm.ErrRecordNotFound
+
+type CommentDao interface {
+	Insert(ctx context.Context, c Comment) (int64, error)
+	FindById(ctx context.Context, id int64) (Comment, error)
+	// FindRoots 按照 ID 倒序查根评论，maxId 为 0 表示从最新的开始
+	FindRoots(ctx context.Context, biz string, bizId int64, maxId int64, limit int) ([]Comment, error)
+	// FindReplies 按照 ID 正序查根评论下面的回复，只返回 minId 之后的
+	FindReplies(ctx context.Context, rootId int64, minId int64, limit int) ([]Comment, error)
+	// CountReplies 批量统计根评论下面的回复数量，没有回复的不会返回
+	CountReplies(ctx context.Context, rootIds []int64) ([]ReplyCount, error)
+	Count(ctx context.Context, biz string, bizId int64) (int64, error)
+	// Delete 删除评论，删除根评论的时候连下面的回复一起删除
+	Delete(ctx context.Context, c Comment) error
+}
+
+type commentDaoGORM struct {
+	db *gorm.DB
+}
+
+func NewCommentDaoGORM(db *gorm.DB) CommentDao {
+	return &commentDaoGORM{db: db}
+}
+
+func (d *commentDaoGORM) Insert(ctx context.Context, c Comment) (int64, error) {
+	now := time.Now().UnixMilli()
+	c.Ctime = now
+	c.Utime = now
+	err := d.db.WithContext(ctx).Create(&c).Error
+	return c.ID, err
+}
+
+func (d *commentDaoGORM) FindById(ctx context.Context, id int64) (Comment, error) {
+	var res Comment
+	err := d.db.WithContext(ctx).Where("id = ?", id).First(&res).Error
+	return res, err
+}
+
+func (d *commentDaoGORM) FindRoots(ctx context.Context, biz string, bizId int64, maxId int64, limit int) ([]Comment, error) {
+	var res []Comment
+	query := d.db.WithContext(ctx).
+		Where("biz = ? AND biz_id = ? AND root_id = ?", biz, bizId, 0)
+	if maxId > 0 {
+		query = query.Where("id < ?", maxId)
+	}
+	err := query.Order("id DESC").
+		Limit(limit).
+		Find(&res).Error
+	return res, err
+}
+
+func (d *commentDaoGORM) FindReplies(ctx context.Context, rootId int64, minId int64, limit int) ([]Comment, error) {
+	var res []Comment
+	err := d.db.WithContext(ctx).
+		Where("root_id = ? AND id > ?", rootId, minId).
+		Order("id ASC").
+		Limit(limit).
+		Find(&res).Error
+	return res, err
+}
+
+func (d *commentDaoGORM) CountReplies(ctx context.Context, rootIds []int64) ([]ReplyCount, error) {
+	var res []ReplyCount
+	if len(rootIds) == 0 {
+		return res, nil
+	}
+	err := d.db.WithContext(ctx).Model(&Comment{}).
+		Select("root_id, COUNT(*) AS cnt").
+		Where("root_id IN ?", rootIds).
+		Group("root_id").
+		Scan(&res).Error
+	return res, err
+}
+
+func (d *commentDaoGORM) Count(ctx context.Context, biz string, bizId int64) (int64, error) {
+	var res int64
+	err := d.db.WithContext(ctx).Model(&Comment{}).
+		Where("biz = ? AND biz_id = ?", biz, bizId).
+		Count(&res).Error
+	return res, err
+}
+
+func (d *commentDaoGORM) Delete(ctx context.Context, c Comment) error {
+	return d.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if c.RootID == 0 {
+			err := tx.Where("root_id = ?", c.ID).Delete(&Comment{}).Error
+			if err != nil {
+				return err
+			}
+		}
+		return tx.Where("id = ?", c.ID).Delete(&Comment{}).Error
+	})
+}
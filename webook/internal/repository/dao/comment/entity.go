@@ -0,0 +1,22 @@
+package comment
+
+// Comment 根评论和回复放在同一张表里面
+type Comment struct {
+	// 按照 root_id 找回复，用 id 做游标
+	ID  int64 `gorm:"primaryKey,autoIncrement;index:idx_root_id, priority:2"`
+	Uid int64
+	// 按照 biz + biz_id 找根评论
+	Biz     string `gorm:"type:varchar(128);index:idx_biz_root, priority:1"`
+	BizID   int64  `gorm:"index:idx_biz_root, priority:2"`
+	RootID  int64  `gorm:"index:idx_biz_root, priority:3;index:idx_root_id, priority:1"`
+	PID     int64  `gorm:"column:pid;index"`
+	Content string `gorm:"type:text"`
+	Ctime   int64
+	Utime   int64
+}
+
+// ReplyCount 根评论下面的回复数量
+type ReplyCount struct {
+	RootID int64
+	Cnt    int64
+}
@@ -0,0 +1,18 @@
+package feed
+
+// FeedPushEvent 推模型，写到每个粉丝的收件箱里面
+type FeedPushEvent struct {
+	ID        int64 `gorm:"primaryKey,autoIncrement"`
+	Uid       int64 `gorm:"uniqueIndex:uk_uid_article;index:idx_uid_ctime, priority:1"`
+	ArticleID int64 `gorm:"uniqueIndex:uk_uid_article"`
+	AuthorID  int64
+	Ctime     int64 `gorm:"index:idx_uid_ctime, priority:2"`
+}
+
+// FeedPullEvent 拉模型，只写作者自己的发件箱，读的时候再去拉
+type FeedPullEvent struct {
+	ID        int64 `gorm:"primaryKey,autoIncrement"`
+	AuthorID  int64 `gorm:"index:idx_author_ctime, priority:1"`
+	ArticleID int64 `gorm:"unique"`
+	Ctime     int64 `gorm:"index:idx_author_ctime, priority:2"`
+}
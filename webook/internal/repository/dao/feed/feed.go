@@ -0,0 +1,62 @@
+package feed
+
+import (
+	"context"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type FeedDao interface {
+	// CreatePushEvents 已经推送过的会被忽略
+	CreatePushEvents(ctx context.Context, events []FeedPushEvent) error
+	// CreatePullEvent 已经存在的会被忽略，所以重新发表不会改变时间
+	CreatePullEvent(ctx context.Context, event FeedPullEvent) error
+	// FindPushEvents 按照 ctime, article_id 倒序的游标分页，ctime 为 0 表示从最新的开始
+	FindPushEvents(ctx context.Context, uid int64, ctime int64, artId int64, limit int) ([]FeedPushEvent, error)
+	FindPullEvents(ctx context.Context, authorIds []int64, ctime int64, artId int64, limit int) ([]FeedPullEvent, error)
+}
+
+type feedDaoGORM struct {
+	db *gorm.DB
+}
+
+func NewFeedDaoGORM(db *gorm.DB) FeedDao {
+	return &feedDaoGORM{db: db}
+}
+
+func (d *feedDaoGORM) CreatePushEvents(ctx context.Context, events []FeedPushEvent) error {
+	if len(events) == 0 {
+		return nil
+	}
+	return d.db.WithContext(ctx).Clauses(clause.OnConflict{DoNothing: true}).
+		Create(&events).Error
+}
+
+func (d *feedDaoGORM) CreatePullEvent(ctx context.Context, event FeedPullEvent) error {
+	return d.db.WithContext(ctx).Clauses(clause.OnConflict{DoNothing: true}).
+		Create(&event).Error
+}
+
+func (d *feedDaoGORM) FindPushEvents(ctx context.Context, uid int64, ctime int64, artId int64, limit int) ([]FeedPushEvent, error) {
+	var res []FeedPushEvent
+	query := d.db.WithContext(ctx).Where("uid = ?", uid)
+	err := d.page(query, ctime, artId, limit).Find(&res).Error
+	return res, err
+}
+
+func (d *feedDaoGORM) FindPullEvents(ctx context.Context, authorIds []int64, ctime int64, artId int64, limit int) ([]FeedPullEvent, error) {
+	var res []FeedPullEvent
+	if len(authorIds) == 0 {
+		return res, nil
+	}
+	query := d.db.WithContext(ctx).Where("author_id IN ?", authorIds)
+	err := d.page(query, ctime, artId, limit).Find(&res).Error
+	return res, err
+}
+
+func (d *feedDaoGORM) page(query *gorm.DB, ctime int64, artId int64, limit int) *gorm.DB {
+	if ctime > 0 {
+		query = query.Where("ctime < ? OR (ctime = ? AND article_id < ?)", ctime, ctime, artId)
+	}
+	return query.Order("ctime DESC, article_id DESC").Limit(limit)
+}
@@ -0,0 +1,26 @@
+package follow
+
+const (
+	StatusInvalid uint8 = iota
+	StatusValid
+)
+
+// FollowRelation 取消关注的时候只修改状态，不删除
+type FollowRelation struct {
+	ID       int64 `gorm:"primaryKey,autoIncrement"`
+	Follower int64 `gorm:"uniqueIndex:uk_follower_followee;index:idx_follower_utime, priority:1"`
+	Followee int64 `gorm:"uniqueIndex:uk_follower_followee;index:idx_followee_utime, priority:1"`
+	Status   uint8
+	Ctime    int64
+	Utime    int64 `gorm:"index:idx_follower_utime, priority:2;index:idx_followee_utime, priority:2"`
+}
+
+// FollowStatics 每个用户的关注数和粉丝数，和关注关系在同一个事务里面修改
+type FollowStatics struct {
+	ID        int64 `gorm:"primaryKey,autoIncrement"`
+	Uid       int64 `gorm:"unique"`
+	Followers int64
+	Followees int64
+	Ctime     int64
+	Utime     int64
+}
@@ -0,0 +1,143 @@
+package follow
+
+import (
+	"context"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"time"
+)
+
+var ErrRecordNotFound = gorm.ErrRecordNotFound
+
+type FollowDao interface {
+	// SetFollow 设置关注状态，changed 表示状态是否真的变了，只有变了计数才会跟着变
+	SetFollow(ctx context.Context, follower int64, followee int64, follow bool) (changed bool, err error)
+	// Get 只返回有效的关注关系
+	Get(ctx context.Context, follower int64, followee int64) (FollowRelation, error)
+	// ListFollowees 按照 utime, id 倒序的游标分页，utime 为 0 表示从最新的开始
+	ListFollowees(ctx context.Context, follower int64, utime int64, id int64, limit int) ([]FollowRelation, error)
+	ListFollowers(ctx context.Context, followee int64, utime int64, id int64, limit int) ([]FollowRelation, error)
+	GetStatics(ctx context.Context, uid int64) (FollowStatics, error)
+	// GetStaticsByIds 批量查询，没有统计数据的不会返回
+	GetStaticsByIds(ctx context.Context, uids []int64) ([]FollowStatics, error)
+}
+
+type followDaoGORM struct {
+	db *gorm.DB
+}
+
+func NewFollowDaoGORM(db *gorm.DB) FollowDao {
+	return &followDaoGORM{db: db}
+}
+
+func (d *followDaoGORM) SetFollow(ctx context.Context, follower int64, followee int64, follow bool) (bool, error) {
+	var changed bool
+	err := d.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		now := time.Now().UnixMilli()
+		status := StatusInvalid
+		if follow {
+			status = StatusValid
+		}
+		res := tx.Model(&FollowRelation{}).
+			Where("follower = ? AND followee = ? AND status <> ?", follower, followee, status).
+			Updates(map[string]any{
+				"status": status,
+				"utime":  now,
+			})
+		if res.Error != nil {
+			return res.Error
+		}
+		changed = res.RowsAffected > 0
+		if !changed && follow {
+			// 要么没有记录，要么本来就关注了
+			res = tx.Clauses(clause.OnConflict{DoNothing: true}).Create(&FollowRelation{
+				Follower: follower,
+				Followee: followee,
+				Status:   status,
+				Ctime:    now,
+				Utime:    now,
+			})
+			if res.Error != nil {
+				return res.Error
+			}
+			changed = res.RowsAffected > 0
+		}
+		if !changed {
+			return nil
+		}
+		delta := int64(1)
+		if !follow {
+			delta = -1
+		}
+		if err := d.incrStatics(tx, follower, "followees", delta); err != nil {
+			return err
+		}
+		return d.incrStatics(tx, followee, "followers", delta)
+	})
+	return changed, err
+}
+
+func (d *followDaoGORM) incrStatics(tx *gorm.DB, uid int64, col string, delta int64) error {
+	now := time.Now().UnixMilli()
+	statics := FollowStatics{
+		Uid:   uid,
+		Ctime: now,
+		Utime: now,
+	}
+	// 第一次插入的时候也不能是负数
+	switch col {
+	case "followers":
+		statics.Followers = max(delta, 0)
+	case "followees":
+		statics.Followees = max(delta, 0)
+	}
+	return tx.Clauses(clause.OnConflict{
+		DoUpdates: clause.Assignments(map[string]any{
+			// 防止数据不一致的时候减成负数
+			col:     gorm.Expr("GREATEST(`"+col+"` + ?, 0)", delta),
+			"utime": now,
+		}),
+	}).Create(&statics).Error
+}
+
+func (d *followDaoGORM) Get(ctx context.Context, follower int64, followee int64) (FollowRelation, error) {
+	var res FollowRelation
+	err := d.db.WithContext(ctx).
+		Where("follower = ? AND followee = ? AND status = ?", follower, followee, StatusValid).
+		First(&res).Error
+	return res, err
+}
+
+func (d *followDaoGORM) ListFollowees(ctx context.Context, follower int64, utime int64, id int64, limit int) ([]FollowRelation, error) {
+	return d.list(ctx, "follower", follower, utime, id, limit)
+}
+
+func (d *followDaoGORM) ListFollowers(ctx context.Context, followee int64, utime int64, id int64, limit int) ([]FollowRelation, error) {
+	return d.list(ctx, "followee", followee, utime, id, limit)
+}
+
+// list col 只能是 follower 或者 followee
+func (d *followDaoGORM) list(ctx context.Context, col string, uid int64, utime int64, id int64, limit int) ([]FollowRelation, error) {
+	var res []FollowRelation
+	query := d.db.WithContext(ctx).
+		Where(col+" = ? AND status = ?", uid, StatusValid)
+	if utime > 0 {
+		query = query.Where("utime < ? OR (utime = ? AND id < ?)", utime, utime, id)
+	}
+	err := query.Order("utime DESC, id DESC").
+		Limit(limit).
+		Find(&res).Error
+	return res, err
+}
+
+func (d *followDaoGORM) GetStatics(ctx context.Context, uid int64) (FollowStatics, error) {
+	var res FollowStatics
+	err := d.db.WithContext(ctx).Where("uid = ?", uid).First(&res).Error
+	return res, err
+}
+
+func (d *followDaoGORM) GetStaticsByIds(ctx context.Context, uids []int64) ([]FollowStatics, error) {
+	var res []FollowStatics
+	err := d.db.WithContext(ctx).Where("uid IN ?", uids).Find(&res).Error
+	return res, err
+}
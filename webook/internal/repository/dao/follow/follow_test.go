@@ -0,0 +1,114 @@
+package follow
+
+import (
+	"context"
+	"database/sql"
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	gormMysql "gorm.io/driver/mysql"
+	"gorm.io/gorm"
+	"regexp"
+	"testing"
+)
+
+func Test_followDaoGORM_SetFollow(t *testing.T) {
+	testCases := []struct {
+		name   string
+		mock   func(t *testing.T) *sql.DB
+		follow bool
+
+		wantChanged bool
+	}{
+		{
+			name: "第一次关注，计数加一",
+			mock: func(t *testing.T) *sql.DB {
+				mockDB, mock, err := sqlmock.New()
+				require.NoError(t, err)
+				mock.ExpectBegin()
+				mock.ExpectExec("UPDATE `follow_relations` SET .*").
+					WillReturnResult(sqlmock.NewResult(0, 0))
+				mock.ExpectExec("INSERT INTO `follow_relations` .*").
+					WillReturnResult(sqlmock.NewResult(1, 1))
+				mock.ExpectExec(regexp.QuoteMeta("ON DUPLICATE KEY UPDATE `followees`=GREATEST(`followees` + ?, 0)")).
+					WithArgs(1, 0, 1, sqlmock.AnyArg(), sqlmock.AnyArg(), 1, sqlmock.AnyArg()).
+					WillReturnResult(sqlmock.NewResult(1, 1))
+				mock.ExpectExec(regexp.QuoteMeta("ON DUPLICATE KEY UPDATE `followers`=GREATEST(`followers` + ?, 0)")).
+					WithArgs(2, 1, 0, sqlmock.AnyArg(), sqlmock.AnyArg(), 1, sqlmock.AnyArg()).
+					WillReturnResult(sqlmock.NewResult(1, 1))
+				mock.ExpectCommit()
+				return mockDB
+			},
+			follow:      true,
+			wantChanged: true,
+		},
+		{
+			name: "重复关注，计数不变",
+			mock: func(t *testing.T) *sql.DB {
+				mockDB, mock, err := sqlmock.New()
+				require.NoError(t, err)
+				mock.ExpectBegin()
+				// 已经是关注状态，UPDATE 改不到，INSERT 冲突
+				mock.ExpectExec("UPDATE `follow_relations` SET .*").
+					WillReturnResult(sqlmock.NewResult(0, 0))
+				mock.ExpectExec("INSERT INTO `follow_relations` .*").
+					WillReturnResult(sqlmock.NewResult(0, 0))
+				mock.ExpectCommit()
+				return mockDB
+			},
+			follow: true,
+		},
+		{
+			name: "取消关注，计数减一，不会减成负数",
+			mock: func(t *testing.T) *sql.DB {
+				mockDB, mock, err := sqlmock.New()
+				require.NoError(t, err)
+				mock.ExpectBegin()
+				mock.ExpectExec(regexp.QuoteMeta("UPDATE `follow_relations` SET `status`=?,`utime`=? WHERE follower = ? AND followee = ? AND status <> ?")).
+					WithArgs(StatusInvalid, sqlmock.AnyArg(), 1, 2, StatusInvalid).
+					WillReturnResult(sqlmock.NewResult(0, 1))
+				// 插入的时候是 0，已经有了的话用 GREATEST 兜底
+				mock.ExpectExec(regexp.QuoteMeta("ON DUPLICATE KEY UPDATE `followees`=GREATEST(`followees` + ?, 0)")).
+					WithArgs(1, 0, 0, sqlmock.AnyArg(), sqlmock.AnyArg(), -1, sqlmock.AnyArg()).
+					WillReturnResult(sqlmock.NewResult(0, 2))
+				mock.ExpectExec(regexp.QuoteMeta("ON DUPLICATE KEY UPDATE `followers`=GREATEST(`followers` + ?, 0)")).
+					WithArgs(2, 0, 0, sqlmock.AnyArg(), sqlmock.AnyArg(), -1, sqlmock.AnyArg()).
+					WillReturnResult(sqlmock.NewResult(0, 2))
+				mock.ExpectCommit()
+				return mockDB
+			},
+			wantChanged: true,
+		},
+		{
+			name: "重复取消关注，计数不变",
+			mock: func(t *testing.T) *sql.DB {
+				mockDB, mock, err := sqlmock.New()
+				require.NoError(t, err)
+				mock.ExpectBegin()
+				mock.ExpectExec("UPDATE `follow_relations` SET .*").
+					WillReturnResult(sqlmock.NewResult(0, 0))
+				mock.ExpectCommit()
+				return mockDB
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			conn := tc.mock(t)
+			db, err := gorm.Open(gormMysql.New(gormMysql.Config{
+				Conn:                      conn,
+				SkipInitializeWithVersion: true,
+			}), &gorm.Config{
+				DisableAutomaticPing:   true,
+				SkipDefaultTransaction: true,
+				TranslateError:         true,
+			})
+			require.NoError(t, err)
+			d := NewFollowDaoGORM(db)
+			changed, err := d.SetFollow(context.Background(), 1, 2, tc.follow)
+			require.NoError(t, err)
+			assert.Equal(t, tc.wantChanged, changed)
+		})
+	}
+}
@@ -2,12 +2,30 @@ package dao
 
 import (
 	"gitee.com/geekbang/basic-go/webook/internal/repository/dao/article"
+	"gitee.com/geekbang/basic-go/webook/internal/repository/dao/comment"
+	"gitee.com/geekbang/basic-go/webook/internal/repository/dao/feed"
+	"gitee.com/geekbang/basic-go/webook/internal/repository/dao/follow"
+	"gitee.com/geekbang/basic-go/webook/internal/repository/dao/interactive"
 	"gorm.io/gorm"
 )
 
 func InitTables(db *gorm.DB) error {
 	return db.AutoMigrate(
 		&User{},
+		&UserRole{},
+		&Job{},
 		&article.Article{},
-		&article.PublishedArticle{})
+		&article.PublishedArticle{},
+		&article.ArticleRevision{},
+		&article.Tag{},
+		&article.ArticleTag{},
+		&article.PublishedArticleTag{},
+		&interactive.Interactive{},
+		&interactive.UserLikeBiz{},
+		&interactive.UserCollectionBiz{},
+		&comment.Comment{},
+		&follow.FollowRelation{},
+		&follow.FollowStatics{},
+		&feed.FeedPushEvent{},
+		&feed.FeedPullEvent{})
 }
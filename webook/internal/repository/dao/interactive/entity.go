@@ -0,0 +1,42 @@
+package interactive
+
+// Interactive 互动计数，一个 biz + biz_id 一行
+type Interactive struct {
+	ID         int64  `gorm:"primaryKey,autoIncrement"`
+	BizId      int64  `gorm:"uniqueIndex:idx_biz_type_id"`
+	Biz        string `gorm:"type:varchar(128);uniqueIndex:idx_biz_type_id"`
+	ReadCnt    int64
+	LikeCnt    int64
+	CollectCnt int64
+	Utime      int64
+	Ctime      int64
+}
+
+// UserLikeBiz 用户点赞记录
+type UserLikeBiz struct {
+	ID    int64  `gorm:"primaryKey,autoIncrement"`
+	Uid   int64  `gorm:"uniqueIndex:idx_uid_biz_type_id"`
+	BizId int64  `gorm:"uniqueIndex:idx_uid_biz_type_id"`
+	Biz   string `gorm:"type:varchar(128);uniqueIndex:idx_uid_biz_type_id"`
+	// 软删除，1 表示有效，0 表示取消了
+	Status uint8
+	Utime  int64
+	Ctime  int64
+}
+
+// UserCollectionBiz 用户收藏记录
+type UserCollectionBiz struct {
+	ID    int64  `gorm:"primaryKey,autoIncrement"`
+	Uid   int64  `gorm:"uniqueIndex:idx_uid_biz_type_id"`
+	BizId int64  `gorm:"uniqueIndex:idx_uid_biz_type_id"`
+	Biz   string `gorm:"type:varchar(128);uniqueIndex:idx_uid_biz_type_id"`
+	// 软删除，1 表示有效，0 表示取消了
+	Status uint8
+	Utime  int64
+	Ctime  int64
+}
+
+const (
+	StatusInvalid uint8 = iota
+	StatusValid
+)
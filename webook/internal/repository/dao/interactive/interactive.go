@@ -0,0 +1,176 @@
+package interactive
+
+import (
+	"context"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"time"
+)
+
+var ErrRecordNotFound = gorm.ErrRecordNotFound
+
+type InteractiveDao interface {
+	IncrReadCnt(ctx context.Context, biz string, bizId int64) error
+	// SetLike 把用户的点赞状态设置为 liked
+	// changed 表示状态是否真的发生了变化，只有变化了，点赞数才会跟着变
+	SetLike(ctx context.Context, biz string, bizId int64, uid int64, liked bool) (changed bool, err error)
+	// SetCollect 同 SetLike
+	SetCollect(ctx context.Context, biz string, bizId int64, uid int64, collected bool) (changed bool, err error)
+	Get(ctx context.Context, biz string, bizId int64) (Interactive, error)
+	GetByIds(ctx context.Context, biz string, bizIds []int64) ([]Interactive, error)
+	GetLikeInfo(ctx context.Context, biz string, bizId int64, uid int64) (UserLikeBiz, error)
+	GetCollectInfo(ctx context.Context, biz string, bizId int64, uid int64) (UserCollectionBiz, error)
+}
+
+type interactiveDaoGORM struct {
+	db *gorm.DB
+}
+
+func NewInteractiveDaoGORM(db *gorm.DB) InteractiveDao {
+	return &interactiveDaoGORM{db: db}
+}
+
+func (d *interactiveDaoGORM) IncrReadCnt(ctx context.Context, biz string, bizId int64) error {
+	return d.incrCnt(d.db.WithContext(ctx), biz, bizId, "read_cnt", 1)
+}
+
+func (d *interactiveDaoGORM) SetLike(ctx context.Context, biz string, bizId int64, uid int64, liked bool) (bool, error) {
+	var changed bool
+	err := d.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var err error
+		changed, err = d.setStatus(tx, &UserLikeBiz{
+			Uid:   uid,
+			Biz:   biz,
+			BizId: bizId,
+		}, liked)
+		if err != nil || !changed {
+			return err
+		}
+		return d.incrCnt(tx, biz, bizId, "like_cnt", d.delta(liked))
+	})
+	return changed, err
+}
+
+func (d *interactiveDaoGORM) SetCollect(ctx context.Context, biz string, bizId int64, uid int64, collected bool) (bool, error) {
+	var changed bool
+	err := d.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var err error
+		changed, err = d.setStatus(tx, &UserCollectionBiz{
+			Uid:   uid,
+			Biz:   biz,
+			BizId: bizId,
+		}, collected)
+		if err != nil || !changed {
+			return err
+		}
+		return d.incrCnt(tx, biz, bizId, "collect_cnt", d.delta(collected))
+	})
+	return changed, err
+}
+
+// setStatus 修改用户记录的状态，model 必须是 *UserLikeBiz 或者 *UserCollectionBiz
+// 只有状态真的变了，才返回 true，这样重复点赞、重复取消都不会影响计数
+func (d *interactiveDaoGORM) setStatus(tx *gorm.DB, model any, valid bool) (bool, error) {
+	now := time.Now().UnixMilli()
+	status := StatusInvalid
+	if valid {
+		status = StatusValid
+	}
+	var uid, bizId int64
+	var biz string
+	switch m := model.(type) {
+	case *UserLikeBiz:
+		uid, biz, bizId = m.Uid, m.Biz, m.BizId
+		m.Status, m.Ctime, m.Utime = status, now, now
+	case *UserCollectionBiz:
+		uid, biz, bizId = m.Uid, m.Biz, m.BizId
+		m.Status, m.Ctime, m.Utime = status, now, now
+	}
+	res := tx.Model(model).
+		Where("uid = ? AND biz = ? AND biz_id = ? AND status <> ?", uid, biz, bizId, status).
+		Updates(map[string]any{
+			"status": status,
+			"utime":  now,
+		})
+	if res.Error != nil {
+		return false, res.Error
+	}
+	if res.RowsAffected > 0 {
+		return true, nil
+	}
+	if !valid {
+		// 没有记录，或者本来就是取消状态
+		return false, nil
+	}
+	// 要么记录不存在，要么本来就是有效状态
+	res = tx.Clauses(clause.OnConflict{DoNothing: true}).Create(model)
+	if res.Error != nil {
+		return false, res.Error
+	}
+	return res.RowsAffected > 0, nil
+}
+
+func (d *interactiveDaoGORM) incrCnt(tx *gorm.DB, biz string, bizId int64, col string, delta int64) error {
+	now := time.Now().UnixMilli()
+	intr := Interactive{
+		Biz:   biz,
+		BizId: bizId,
+		Utime: now,
+		Ctime: now,
+	}
+	// 第一次插入的时候也不能是负数
+	switch col {
+	case "read_cnt":
+		intr.ReadCnt = max(delta, 0)
+	case "like_cnt":
+		intr.LikeCnt = max(delta, 0)
+	case "collect_cnt":
+		intr.CollectCnt = max(delta, 0)
+	}
+	return tx.Clauses(clause.OnConflict{
+		DoUpdates: clause.Assignments(map[string]any{
+			// 防止数据不一致的时候减成负数
+			col:     gorm.Expr("GREATEST(`"+col+"` + ?, 0)", delta),
+			"utime": now,
+		}),
+	}).Create(&intr).Error
+}
+
+func (d *interactiveDaoGORM) delta(incr bool) int64 {
+	if incr {
+		return 1
+	}
+	return -1
+}
+
+func (d *interactiveDaoGORM) Get(ctx context.Context, biz string, bizId int64) (Interactive, error) {
+	var res Interactive
+	err := d.db.WithContext(ctx).
+		Where("biz = ? AND biz_id = ?", biz, bizId).
+		First(&res).Error
+	return res, err
+}
+
+func (d *interactiveDaoGORM) GetByIds(ctx context.Context, biz string, bizIds []int64) ([]Interactive, error) {
+	var res []Interactive
+	err := d.db.WithContext(ctx).
+		Where("biz = ? AND biz_id IN ?", biz, bizIds).
+		Find(&res).Error
+	return res, err
+}
+
+func (d *interactiveDaoGORM) GetLikeInfo(ctx context.Context, biz string, bizId int64, uid int64) (UserLikeBiz, error) {
+	var res UserLikeBiz
+	err := d.db.WithContext(ctx).
+		Where("uid = ? AND biz = ? AND biz_id = ? AND status = ?", uid, biz, bizId, StatusValid).
+		First(&res).Error
+	return res, err
+}
+
+func (d *interactiveDaoGORM) GetCollectInfo(ctx context.Context, biz string, bizId int64, uid int64) (UserCollectionBiz, error) {
+	var res UserCollectionBiz
+	err := d.db.WithContext(ctx).
+		Where("uid = ? AND biz = ? AND biz_id = ? AND status = ?", uid, biz, bizId, StatusValid).
+		First(&res).Error
+	return res, err
+}
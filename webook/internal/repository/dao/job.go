@@ -0,0 +1,164 @@
+package dao
+
+import (
+	"errors"
+	"golang.org/x/net/context"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"time"
+)
+
+var (
+	ErrNoJobToPreempt = errors.New("没有可以抢占的任务")
+	ErrJobNotHold     = errors.New("任务已经被别的实例抢走了")
+)
+
+const (
+	// JobStatusWaiting 等待被调度
+	JobStatusWaiting = iota
+	// JobStatusRunning 有实例正在运行
+	JobStatusRunning
+	// JobStatusPaused 不再调度
+	JobStatusPaused
+)
+
+type JobDao interface {
+	// Upsert 按照 Name 注册任务，已经存在的话只更新定义，不影响调度状态。
+	// cron 表达式变了的时候，下一次执行时间也换成 j.NextTime
+	Upsert(ctx context.Context, j Job) error
+	// Preempt 抢占一个到期的任务，或者一个心跳已经超时的任务
+	// heartbeatDeadline 之前没有心跳的运行中任务，认为持有者已经挂了
+	Preempt(ctx context.Context, owner string, heartbeatDeadline int64) (Job, error)
+	// Heartbeat 续约，任务已经不是 owner 的了返回 ErrJobNotHold
+	Heartbeat(ctx context.Context, id int64, owner string) error
+	// Release 释放任务，同时设置下一次执行时间
+	Release(ctx context.Context, id int64, owner string, nextTime int64) error
+	// Pause 停止调度，比如说 cron 表达式有问题
+	Pause(ctx context.Context, id int64) error
+}
+
+type jobDaoGORM struct {
+	db *gorm.DB
+}
+
+func NewJobDaoGORM(db *gorm.DB) JobDao {
+	return &jobDaoGORM{db: db}
+}
+
+func (d *jobDaoGORM) Upsert(ctx context.Context, j Job) error {
+	now := time.Now().UnixMilli()
+	j.Ctime = now
+	j.Utime = now
+	j.Status = JobStatusWaiting
+	return d.db.WithContext(ctx).Clauses(clause.OnConflict{
+		Columns: []clause.Column{{Name: "name"}},
+		// MySQL 是按照顺序赋值的，next_time 要在 expression 之前，不然比较的就是新的表达式了。
+		// 表达式变了的话，按照旧表达式算出来的下一次执行时间就不对了
+		DoUpdates: clause.Set{
+			{Column: clause.Column{Name: "next_time"}, Value: gorm.Expr("IF(`expression` <> ?, ?, `next_time`)", j.Expression, j.NextTime)},
+			{Column: clause.Column{Name: "executor"}, Value: j.Executor},
+			{Column: clause.Column{Name: "cfg"}, Value: j.Cfg},
+			{Column: clause.Column{Name: "expression"}, Value: j.Expression},
+		},
+	}).Create(&j).Error
+}
+
+func (d *jobDaoGORM) Preempt(ctx context.Context, owner string, heartbeatDeadline int64) (Job, error) {
+	db := d.db.WithContext(ctx)
+	for {
+		now := time.Now().UnixMilli()
+		var j Job
+		err := db.Where("(status = ? AND next_time <= ?) OR (status = ? AND utime < ?)",
+			JobStatusWaiting, now, JobStatusRunning, heartbeatDeadline).
+			First(&j).Error
+		if err == gorm.ErrRecordNotFound {
+			return Job{}, ErrNoJobToPreempt
+		}
+		if err != nil {
+			return Job{}, err
+		}
+		// 乐观锁，version 没变才说明在我们查询之后没有别人抢到
+		res := db.Model(&Job{}).
+			Where("id = ? AND version = ?", j.Id, j.Version).
+			Updates(map[string]any{
+				"status":  JobStatusRunning,
+				"owner":   owner,
+				"version": j.Version + 1,
+				"utime":   now,
+			})
+		if res.Error != nil {
+			return Job{}, res.Error
+		}
+		if res.RowsAffected == 1 {
+			j.Status = JobStatusRunning
+			j.Owner = owner
+			j.Version = j.Version + 1
+			j.Utime = now
+			return j, nil
+		}
+		// 被别人抢走了，继续找下一个
+	}
+}
+
+func (d *jobDaoGORM) Heartbeat(ctx context.Context, id int64, owner string) error {
+	res := d.db.WithContext(ctx).Model(&Job{}).
+		Where("id = ? AND owner = ? AND status = ?", id, owner, JobStatusRunning).
+		Updates(map[string]any{
+			"utime": time.Now().UnixMilli(),
+		})
+	if res.Error != nil {
+		return res.Error
+	}
+	if res.RowsAffected == 0 {
+		return ErrJobNotHold
+	}
+	return nil
+}
+
+func (d *jobDaoGORM) Release(ctx context.Context, id int64, owner string, nextTime int64) error {
+	res := d.db.WithContext(ctx).Model(&Job{}).
+		Where("id = ? AND owner = ? AND status = ?", id, owner, JobStatusRunning).
+		Updates(map[string]any{
+			"status":    JobStatusWaiting,
+			"owner":     "",
+			"next_time": nextTime,
+			"utime":     time.Now().UnixMilli(),
+		})
+	if res.Error != nil {
+		return res.Error
+	}
+	if res.RowsAffected == 0 {
+		return ErrJobNotHold
+	}
+	return nil
+}
+
+func (d *jobDaoGORM) Pause(ctx context.Context, id int64) error {
+	return d.db.WithContext(ctx).Model(&Job{}).
+		Where("id = ?", id).
+		Updates(map[string]any{
+			"status": JobStatusPaused,
+			"owner":  "",
+			"utime":  time.Now().UnixMilli(),
+		}).Error
+}
+
+type Job struct {
+	Id       int64  `gorm:"primaryKey,autoIncrement"`
+	Name     string `gorm:"type:varchar(128);unique"`
+	Executor string
+	Cfg      string
+	// cron 表达式
+	Expression string
+	// 乐观锁的版本号，每次抢占成功都会 +1
+	Version int64
+	// 下一次执行时间，毫秒数
+	NextTime int64 `gorm:"index:idx_status_next_time,priority:2"`
+	Status   int   `gorm:"index:idx_status_next_time,priority:1"`
+	// 持有任务的实例
+	Owner string `gorm:"type:varchar(128)"`
+
+	Ctime int64
+	// 运行中的任务会不断更新 Utime 作为心跳
+	Utime int64
+}
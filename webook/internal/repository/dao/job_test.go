@@ -0,0 +1,177 @@
+package dao
+
+import (
+	"context"
+	"database/sql"
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	gormMysql "gorm.io/driver/mysql"
+	"gorm.io/gorm"
+	"regexp"
+	"testing"
+)
+
+var jobColumns = []string{"id", "name", "executor", "cfg", "expression",
+	"version", "next_time", "status", "owner", "ctime", "utime"}
+
+func Test_jobDaoGORM_Upsert(t *testing.T) {
+	mockDB, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	// 表达式变了才更新 next_time，而且要在更新 expression 之前比较
+	mock.ExpectExec(regexp.QuoteMeta("ON DUPLICATE KEY UPDATE `next_time`=IF(`expression` <> ?, ?, `next_time`),`executor`=?,`cfg`=?,`expression`=?")).
+		WillReturnResult(sqlmock.NewResult(1, 1))
+
+	d := NewJobDaoGORM(newMockDB(t, mockDB))
+	err = d.Upsert(context.Background(), Job{
+		Name:       "publish",
+		Executor:   "local",
+		Expression: "0 * * * * ?",
+		NextTime:   1000,
+	})
+	assert.NoError(t, err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func Test_jobDaoGORM_Preempt(t *testing.T) {
+	testCases := []struct {
+		name string
+		mock func(t *testing.T) *sql.DB
+
+		wantJob Job
+		wantErr error
+	}{
+		{
+			name: "抢占成功",
+			mock: func(t *testing.T) *sql.DB {
+				mockDB, mock, err := sqlmock.New()
+				require.NoError(t, err)
+				mock.ExpectQuery("SELECT \\* FROM `jobs` WHERE .*").
+					WillReturnRows(sqlmock.NewRows(jobColumns).
+						AddRow(1, "publish", "local", "", "", 3, 0, JobStatusWaiting, "", 0, 0))
+				mock.ExpectExec(regexp.QuoteMeta("UPDATE `jobs` SET `owner`=?,`status`=?,`utime`=?,`version`=? WHERE id = ? AND version = ?")).
+					WithArgs("me", JobStatusRunning, sqlmock.AnyArg(), 4, 1, 3).
+					WillReturnResult(sqlmock.NewResult(0, 1))
+				return mockDB
+			},
+			wantJob: Job{Id: 1, Name: "publish", Executor: "local",
+				Version: 4, Status: JobStatusRunning, Owner: "me"},
+		},
+		{
+			name: "version 变了，被别人抢走了，找下一个",
+			mock: func(t *testing.T) *sql.DB {
+				mockDB, mock, err := sqlmock.New()
+				require.NoError(t, err)
+				mock.ExpectQuery("SELECT \\* FROM `jobs` WHERE .*").
+					WillReturnRows(sqlmock.NewRows(jobColumns).
+						AddRow(1, "publish", "local", "", "", 3, 0, JobStatusWaiting, "", 0, 0))
+				mock.ExpectExec("UPDATE `jobs` SET .*").
+					WithArgs("me", JobStatusRunning, sqlmock.AnyArg(), 4, 1, 3).
+					WillReturnResult(sqlmock.NewResult(0, 0))
+				mock.ExpectQuery("SELECT \\* FROM `jobs` WHERE .*").
+					WillReturnRows(sqlmock.NewRows(jobColumns).
+						AddRow(2, "ranking", "local", "", "", 7, 0, JobStatusWaiting, "", 0, 0))
+				mock.ExpectExec("UPDATE `jobs` SET .*").
+					WithArgs("me", JobStatusRunning, sqlmock.AnyArg(), 8, 2, 7).
+					WillReturnResult(sqlmock.NewResult(0, 1))
+				return mockDB
+			},
+			wantJob: Job{Id: 2, Name: "ranking", Executor: "local",
+				Version: 8, Status: JobStatusRunning, Owner: "me"},
+		},
+		{
+			name: "心跳超时，别的实例接手",
+			mock: func(t *testing.T) *sql.DB {
+				mockDB, mock, err := sqlmock.New()
+				require.NoError(t, err)
+				// 运行中，但是 utime 早于 heartbeatDeadline
+				mock.ExpectQuery(regexp.QuoteMeta("SELECT * FROM `jobs` WHERE (status = ? AND next_time <= ?) OR (status = ? AND utime < ?)")).
+					WithArgs(JobStatusWaiting, sqlmock.AnyArg(), JobStatusRunning, 5000).
+					WillReturnRows(sqlmock.NewRows(jobColumns).
+						AddRow(1, "publish", "local", "", "", 3, 0, JobStatusRunning, "dead", 0, 1000))
+				mock.ExpectExec("UPDATE `jobs` SET .*").
+					WithArgs("me", JobStatusRunning, sqlmock.AnyArg(), 4, 1, 3).
+					WillReturnResult(sqlmock.NewResult(0, 1))
+				return mockDB
+			},
+			wantJob: Job{Id: 1, Name: "publish", Executor: "local",
+				Version: 4, Status: JobStatusRunning, Owner: "me"},
+		},
+		{
+			name: "没有可以抢占的",
+			mock: func(t *testing.T) *sql.DB {
+				mockDB, mock, err := sqlmock.New()
+				require.NoError(t, err)
+				mock.ExpectQuery("SELECT \\* FROM `jobs` WHERE .*").
+					WillReturnRows(sqlmock.NewRows(jobColumns))
+				return mockDB
+			},
+			wantErr: ErrNoJobToPreempt,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			d := NewJobDaoGORM(newMockDB(t, tc.mock(t)))
+			j, err := d.Preempt(context.Background(), "me", 5000)
+			assert.Equal(t, tc.wantErr, err)
+			// utime 是抢占的时间
+			j.Utime = 0
+			assert.Equal(t, tc.wantJob, j)
+		})
+	}
+}
+
+func Test_jobDaoGORM_Heartbeat(t *testing.T) {
+	testCases := []struct {
+		name string
+		mock func(t *testing.T) *sql.DB
+
+		wantErr error
+	}{
+		{
+			name: "续约成功",
+			mock: func(t *testing.T) *sql.DB {
+				mockDB, mock, err := sqlmock.New()
+				require.NoError(t, err)
+				mock.ExpectExec(regexp.QuoteMeta("UPDATE `jobs` SET `utime`=? WHERE id = ? AND owner = ? AND status = ?")).
+					WithArgs(sqlmock.AnyArg(), 1, "me", JobStatusRunning).
+					WillReturnResult(sqlmock.NewResult(0, 1))
+				return mockDB
+			},
+		},
+		{
+			name: "心跳断了之后被别人接手",
+			mock: func(t *testing.T) *sql.DB {
+				mockDB, mock, err := sqlmock.New()
+				require.NoError(t, err)
+				mock.ExpectExec("UPDATE `jobs` SET .*").
+					WithArgs(sqlmock.AnyArg(), 1, "me", JobStatusRunning).
+					WillReturnResult(sqlmock.NewResult(0, 0))
+				return mockDB
+			},
+			wantErr: ErrJobNotHold,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			d := NewJobDaoGORM(newMockDB(t, tc.mock(t)))
+			err := d.Heartbeat(context.Background(), 1, "me")
+			assert.Equal(t, tc.wantErr, err)
+		})
+	}
+}
+
+func newMockDB(t *testing.T, conn *sql.DB) *gorm.DB {
+	db, err := gorm.Open(gormMysql.New(gormMysql.Config{
+		Conn:                      conn,
+		SkipInitializeWithVersion: true,
+	}), &gorm.Config{
+		DisableAutomaticPing:   true,
+		SkipDefaultTransaction: true,
+		TranslateError:         true,
+	})
+	require.NoError(t, err)
+	return db
+}
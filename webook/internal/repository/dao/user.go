@@ -3,14 +3,23 @@ package dao
 import (
 	"database/sql"
 	"errors"
+	"fmt"
 	"golang.org/x/net/context"
 	"gorm.io/gorm"
 	"time"
 )
 
 var (
-	ErrUserDuplicate = errors.New("邮箱冲突")
-	ErrUserNotFound  = gorm.ErrRecordNotFound
+	ErrUserDuplicate   = errors.New("邮箱或手机号冲突")
+	ErrUserNotFound    = gorm.ErrRecordNotFound
+	ErrLastLoginMethod = errors.New("至少要保留一种登录方式")
+)
+
+// 登录方式对应的列，邮箱要有密码才能登录
+const (
+	loginMethodEmail  = "email"
+	loginMethodPhone  = "phone"
+	loginMethodWechat = "wechat"
 )
 
 //go:generate mockgen -source=$GOFILE -destination=mocks/mock_$GOFILE --package=$GOPACKAGEmocks
@@ -20,6 +29,24 @@ type UserDao interface {
 	FindById(ctx context.Context, id int64) (User, error)
 	FindByPhone(ctx context.Context, phone string) (User, error)
 	FindByWechat(ctx context.Context, id string) (User, error)
+	// UpdateProfile 只更新昵称、生日和个人简介
+	UpdateProfile(ctx context.Context, user User) error
+	UpdatePassword(ctx context.Context, id int64, password string) error
+	// UpdatePhone 手机号已经被别人用了返回 ErrUserDuplicate
+	UpdatePhone(ctx context.Context, id int64, phone string) error
+	// UpdateEmail 邮箱已经被别人用了返回 ErrUserDuplicate
+	UpdateEmail(ctx context.Context, id int64, email string) error
+	UpdateStatus(ctx context.Context, id int64, status uint8) error
+	// UpdateWechat 微信已经被别人绑定了返回 ErrUserDuplicate
+	UpdateWechat(ctx context.Context, id int64, openID string, unionID string) error
+	// RemoveLoginMethod method 是 email、phone 或者 wechat，
+	// 只有还剩下别的登录方式的时候才会清空，否则返回 ErrLastLoginMethod
+	RemoveLoginMethod(ctx context.Context, id int64, method string) error
+	// Deactivate 软删除，同时清空个人信息，已经注销过的返回 ErrUserNotFound
+	Deactivate(ctx context.Context, id int64, status uint8, nickname string) error
+	// List 按照 ID 倒序，管理后台用
+	List(ctx context.Context, offset int, limit int) ([]User, error)
+	FindRoles(ctx context.Context, uid int64) ([]string, error)
 }
 
 type userDaoGorm struct {
@@ -72,6 +99,167 @@ func (u *userDaoGorm) Insert(ctx context.Context, user User) error {
 
 }
 
+func (u *userDaoGorm) UpdateProfile(ctx context.Context, user User) error {
+	res := u.db.WithContext(ctx).Model(&User{}).Where("id = ?", user.Id).
+		Updates(map[string]any{
+			"nickname": user.Nickname,
+			"birthday": user.Birthday,
+			"about_me": user.AboutMe,
+			"utime":    time.Now().UnixMilli(),
+		})
+	if res.Error != nil {
+		return res.Error
+	}
+	if res.RowsAffected == 0 {
+		return ErrUserNotFound
+	}
+	return nil
+}
+
+func (u *userDaoGorm) UpdatePassword(ctx context.Context, id int64, password string) error {
+	res := u.db.WithContext(ctx).Model(&User{}).Where("id = ?", id).
+		Updates(map[string]any{
+			"password": password,
+			"utime":    time.Now().UnixMilli(),
+		})
+	if res.Error != nil {
+		return res.Error
+	}
+	if res.RowsAffected == 0 {
+		return ErrUserNotFound
+	}
+	return nil
+}
+
+func (u *userDaoGorm) UpdatePhone(ctx context.Context, id int64, phone string) error {
+	return u.updateUnique(ctx, id, map[string]any{
+		"phone": sql.NullString{String: phone, Valid: phone != ""},
+	})
+}
+
+func (u *userDaoGorm) UpdateEmail(ctx context.Context, id int64, email string) error {
+	return u.updateUnique(ctx, id, map[string]any{
+		"email": sql.NullString{String: email, Valid: email != ""},
+	})
+}
+
+func (u *userDaoGorm) UpdateWechat(ctx context.Context, id int64, openID string, unionID string) error {
+	return u.updateUnique(ctx, id, map[string]any{
+		"wechat_open_id":  sql.NullString{String: openID, Valid: openID != ""},
+		"wechat_union_id": sql.NullString{String: unionID, Valid: unionID != ""},
+	})
+}
+
+func (u *userDaoGorm) RemoveLoginMethod(ctx context.Context, id int64, method string) error {
+	const (
+		hasEmail  = "(email IS NOT NULL AND password <> '')"
+		hasPhone  = "phone IS NOT NULL"
+		hasWechat = "wechat_open_id IS NOT NULL"
+	)
+	var (
+		cols   map[string]any
+		others string
+	)
+	switch method {
+	case loginMethodEmail:
+		cols = map[string]any{"email": nil}
+		others = hasPhone + " OR " + hasWechat
+	case loginMethodPhone:
+		cols = map[string]any{"phone": nil}
+		others = hasEmail + " OR " + hasWechat
+	case loginMethodWechat:
+		cols = map[string]any{"wechat_open_id": nil, "wechat_union_id": nil}
+		others = hasEmail + " OR " + hasPhone
+	default:
+		return fmt.Errorf("未知的登录方式 %s", method)
+	}
+	cols["utime"] = time.Now().UnixMilli()
+	// 把检查放在 WHERE 里面，并发解绑的时候也不会把登录方式全部解绑了
+	res := u.db.WithContext(ctx).Model(&User{}).
+		Where("id = ?", id).Where(others).
+		Updates(cols)
+	if res.Error != nil {
+		return res.Error
+	}
+	if res.RowsAffected == 0 {
+		return ErrLastLoginMethod
+	}
+	return nil
+}
+
+func (u *userDaoGorm) UpdateStatus(ctx context.Context, id int64, status uint8) error {
+	res := u.db.WithContext(ctx).Model(&User{}).Where("id = ?", id).
+		Updates(map[string]any{
+			"status": status,
+			"utime":  time.Now().UnixMilli(),
+		})
+	if res.Error != nil {
+		return res.Error
+	}
+	if res.RowsAffected == 0 {
+		return ErrUserNotFound
+	}
+	return nil
+}
+
+func (u *userDaoGorm) Deactivate(ctx context.Context, id int64, status uint8, nickname string) error {
+	now := time.Now().UnixMilli()
+	// 行不删，评论之类的还要能查到这个用户，只是看不到个人信息了
+	res := u.db.WithContext(ctx).Model(&User{}).
+		Where("id = ? AND deleted_at = ?", id, 0).
+		Updates(map[string]any{
+			"email":           nil,
+			"phone":           nil,
+			"password":        "",
+			"wechat_open_id":  nil,
+			"wechat_union_id": nil,
+			"nickname":        nickname,
+			"birthday":        nil,
+			"about_me":        "",
+			"status":          status,
+			"deleted_at":      now,
+			"utime":           now,
+		})
+	if res.Error != nil {
+		return res.Error
+	}
+	if res.RowsAffected == 0 {
+		return ErrUserNotFound
+	}
+	return nil
+}
+
+func (u *userDaoGorm) List(ctx context.Context, offset int, limit int) ([]User, error) {
+	var res []User
+	err := u.db.WithContext(ctx).Order("id DESC").
+		Offset(offset).Limit(limit).
+		Find(&res).Error
+	return res, err
+}
+
+func (u *userDaoGorm) FindRoles(ctx context.Context, uid int64) ([]string, error) {
+	var res []string
+	err := u.db.WithContext(ctx).Model(&UserRole{}).
+		Where("uid = ?", uid).Pluck("role", &res).Error
+	return res, err
+}
+
+// updateUnique 更新带有唯一索引的列
+func (u *userDaoGorm) updateUnique(ctx context.Context, id int64, cols map[string]any) error {
+	cols["utime"] = time.Now().UnixMilli()
+	res := u.db.WithContext(ctx).Model(&User{}).Where("id = ?", id).Updates(cols)
+	switch {
+	case errors.Is(res.Error, gorm.ErrDuplicatedKey):
+		return ErrUserDuplicate
+	case res.Error != nil:
+		return res.Error
+	case res.RowsAffected == 0:
+		return ErrUserNotFound
+	default:
+		return nil
+	}
+}
+
 func NewUserDaoGorm(db *gorm.DB) UserDao {
 	return &userDaoGorm{db: db}
 }
@@ -82,6 +270,12 @@ type User struct {
 	Phone    sql.NullString `gorm:"unique"`
 	Password string
 
+	Nickname string `gorm:"type:varchar(128)"`
+	// 生日，毫秒数
+	Birthday sql.NullInt64
+	AboutMe  string `gorm:"type:varchar(1024)"`
+	Status   uint8
+
 	WechatUnionID sql.NullString
 	WechatOpenID  sql.NullString `gorm:"unique"`
 
@@ -89,4 +283,16 @@ type User struct {
 	Ctime int64
 	// 更新时间，毫秒数
 	Utime int64
+	// 注销的时间，毫秒数，0 表示没有注销
+	DeletedAt int64 `gorm:"index"`
+}
+
+// UserRole 用户的角色，一个用户可以有多个角色。
+// 目前还没有授予角色的接口，直接插入数据库
+type UserRole struct {
+	Id   int64  `gorm:"primaryKey;autoIncrement"`
+	Uid  int64  `gorm:"uniqueIndex:uid_role"`
+	Role string `gorm:"type:varchar(32);uniqueIndex:uid_role"`
+	// 创建时间，毫秒数
+	Ctime int64
 }
@@ -10,6 +10,7 @@ import (
 	"github.com/stretchr/testify/require"
 	gormMysql "gorm.io/driver/mysql"
 	"gorm.io/gorm"
+	"regexp"
 	"testing"
 )
 
@@ -101,3 +102,54 @@ func Test_userDaoGorm_Insert(t *testing.T) {
 	}
 
 }
+
+func Test_userDaoGorm_RemoveLoginMethod(t *testing.T) {
+	testCases := []struct {
+		name    string
+		mock    func(t *testing.T) *sql.DB
+		method  string
+		wantErr error
+	}{
+		{
+			name: "解绑手机号",
+			mock: func(t *testing.T) *sql.DB {
+				mockDB, mock, err := sqlmock.New()
+				require.NoError(t, err)
+				// 还要有别的登录方式才能解绑
+				mock.ExpectExec(regexp.QuoteMeta("UPDATE `users` SET `phone`=?,`utime`=? WHERE id = ? AND ((email IS NOT NULL AND password <> '') OR wechat_open_id IS NOT NULL)")).
+					WillReturnResult(sqlmock.NewResult(0, 1))
+				return mockDB
+			},
+			method: loginMethodPhone,
+		},
+		{
+			name: "最后一种登录方式",
+			mock: func(t *testing.T) *sql.DB {
+				mockDB, mock, err := sqlmock.New()
+				require.NoError(t, err)
+				mock.ExpectExec("UPDATE `users` SET .*").
+					WillReturnResult(sqlmock.NewResult(0, 0))
+				return mockDB
+			},
+			method:  loginMethodWechat,
+			wantErr: ErrLastLoginMethod,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			db, err := gorm.Open(gormMysql.New(gormMysql.Config{
+				Conn:                      tc.mock(t),
+				SkipInitializeWithVersion: true,
+			}), &gorm.Config{
+				DisableAutomaticPing:   true,
+				SkipDefaultTransaction: true,
+				TranslateError:         true,
+			})
+			require.NoError(t, err)
+			d := NewUserDaoGorm(db)
+			err = d.RemoveLoginMethod(context.Background(), 123, tc.method)
+			assert.Equal(t, tc.wantErr, err)
+		})
+	}
+}
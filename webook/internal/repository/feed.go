@@ -0,0 +1,88 @@
+package repository
+
+import (
+	"context"
+	"gitee.com/geekbang/basic-go/webook/internal/domain"
+	"gitee.com/geekbang/basic-go/webook/internal/repository/dao/feed"
+	"time"
+)
+
+//go:generate mockgen -source=$GOFILE -destination=mocks/mock_$GOFILE --package=$GOPACKAGEmocks
+type FeedRepository interface {
+	// CreatePushEvents 把 evt 推送到 uids 的收件箱
+	CreatePushEvents(ctx context.Context, uids []int64, evt domain.FeedEvent) error
+	CreatePullEvent(ctx context.Context, evt domain.FeedEvent) error
+	// FindPushEvents 游标分页，ctime 和 artId 是上一页最后一条的
+	FindPushEvents(ctx context.Context, uid int64, ctime time.Time, artId int64, limit int) ([]domain.FeedEvent, error)
+	FindPullEvents(ctx context.Context, authorIds []int64, ctime time.Time, artId int64, limit int) ([]domain.FeedEvent, error)
+}
+
+type feedRepository struct {
+	dao feed.FeedDao
+}
+
+func NewFeedRepository(dao feed.FeedDao) FeedRepository {
+	return &feedRepository{dao: dao}
+}
+
+func (repo *feedRepository) CreatePushEvents(ctx context.Context, uids []int64, evt domain.FeedEvent) error {
+	events := make([]feed.FeedPushEvent, 0, len(uids))
+	for _, uid := range uids {
+		events = append(events, feed.FeedPushEvent{
+			Uid:       uid,
+			ArticleID: evt.ArticleId,
+			AuthorID:  evt.AuthorId,
+			Ctime:     evt.Ctime.UnixMilli(),
+		})
+	}
+	return repo.dao.CreatePushEvents(ctx, events)
+}
+
+func (repo *feedRepository) CreatePullEvent(ctx context.Context, evt domain.FeedEvent) error {
+	return repo.dao.CreatePullEvent(ctx, feed.FeedPullEvent{
+		AuthorID:  evt.AuthorId,
+		ArticleID: evt.ArticleId,
+		Ctime:     evt.Ctime.UnixMilli(),
+	})
+}
+
+func (repo *feedRepository) FindPushEvents(ctx context.Context, uid int64, ctime time.Time,
+	artId int64, limit int) ([]domain.FeedEvent, error) {
+	data, err := repo.dao.FindPushEvents(ctx, uid, repo.cursor(ctime), artId, limit)
+	if err != nil {
+		return nil, err
+	}
+	res := make([]domain.FeedEvent, 0, len(data))
+	for _, evt := range data {
+		res = append(res, domain.FeedEvent{
+			ArticleId: evt.ArticleID,
+			AuthorId:  evt.AuthorID,
+			Ctime:     time.UnixMilli(evt.Ctime),
+		})
+	}
+	return res, nil
+}
+
+func (repo *feedRepository) FindPullEvents(ctx context.Context, authorIds []int64, ctime time.Time,
+	artId int64, limit int) ([]domain.FeedEvent, error) {
+	data, err := repo.dao.FindPullEvents(ctx, authorIds, repo.cursor(ctime), artId, limit)
+	if err != nil {
+		return nil, err
+	}
+	res := make([]domain.FeedEvent, 0, len(data))
+	for _, evt := range data {
+		res = append(res, domain.FeedEvent{
+			ArticleId: evt.ArticleID,
+			AuthorId:  evt.AuthorID,
+			Ctime:     time.UnixMilli(evt.Ctime),
+		})
+	}
+	return res, nil
+}
+
+func (repo *feedRepository) cursor(ctime time.Time) int64 {
+	if ctime.IsZero() {
+		return 0
+	}
+	return ctime.UnixMilli()
+}
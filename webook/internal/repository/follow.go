@@ -0,0 +1,151 @@
+package repository
+
+import (
+	"context"
+	"gitee.com/geekbang/basic-go/webook/internal/domain"
+	"gitee.com/geekbang/basic-go/webook/internal/repository/cache"
+	"gitee.com/geekbang/basic-go/webook/internal/repository/dao/follow"
+	"gitee.com/geekbang/basic-go/webook/pkg/logger"
+	"time"
+)
+
+//go:generate mockgen -source=$GOFILE -destination=mocks/mock_$GOFILE --package=$GOPACKAGEmocks
+type FollowRepository interface {
+	AddFollowRelation(ctx context.Context, follower int64, followee int64) error
+	InactiveFollowRelation(ctx context.Context, follower int64, followee int64) error
+	// Followed follower 是否关注了 followee
+	Followed(ctx context.Context, follower int64, followee int64) (bool, error)
+	// ListFollowees 游标分页，utime 和 id 是上一页最后一条的
+	ListFollowees(ctx context.Context, follower int64, utime time.Time, id int64, limit int) ([]domain.FollowRelation, error)
+	ListFollowers(ctx context.Context, followee int64, utime time.Time, id int64, limit int) ([]domain.FollowRelation, error)
+	GetStatics(ctx context.Context, uid int64) (domain.FollowStatics, error)
+	// GetStaticsByIds 批量查询，不走缓存，key 是 uid，没有统计数据的是零值
+	GetStaticsByIds(ctx context.Context, uids []int64) (map[int64]domain.FollowStatics, error)
+}
+
+type followRepository struct {
+	dao   follow.FollowDao
+	cache cache.FollowCache
+	l     logger.Logger
+}
+
+func NewFollowRepository(dao follow.FollowDao, c cache.FollowCache, l logger.Logger) FollowRepository {
+	return &followRepository{
+		dao:   dao,
+		cache: c,
+		l:     l,
+	}
+}
+
+func (repo *followRepository) AddFollowRelation(ctx context.Context, follower int64, followee int64) error {
+	return repo.setFollow(ctx, follower, followee, true)
+}
+
+func (repo *followRepository) InactiveFollowRelation(ctx context.Context, follower int64, followee int64) error {
+	return repo.setFollow(ctx, follower, followee, false)
+}
+
+func (repo *followRepository) setFollow(ctx context.Context, follower int64, followee int64, follow bool) error {
+	changed, err := repo.dao.SetFollow(ctx, follower, followee, follow)
+	if err != nil || !changed {
+		return err
+	}
+	// 两个人的统计数据都变了
+	if err = repo.cache.DelStatics(ctx, follower, followee); err != nil {
+		repo.l.Error("删除关注统计缓存失败", logger.Error(err),
+			logger.Int64("follower", follower), logger.Int64("followee", followee))
+	}
+	return nil
+}
+
+func (repo *followRepository) Followed(ctx context.Context, follower int64, followee int64) (bool, error) {
+	_, err := repo.dao.Get(ctx, follower, followee)
+	switch err {
+	case nil:
+		return true, nil
+	case follow.ErrRecordNotFound:
+		return false, nil
+	default:
+		return false, err
+	}
+}
+
+func (repo *followRepository) ListFollowees(ctx context.Context, follower int64, utime time.Time,
+	id int64, limit int) ([]domain.FollowRelation, error) {
+	data, err := repo.dao.ListFollowees(ctx, follower, repo.cursor(utime), id, limit)
+	if err != nil {
+		return nil, err
+	}
+	return repo.toDomains(data), nil
+}
+
+func (repo *followRepository) ListFollowers(ctx context.Context, followee int64, utime time.Time,
+	id int64, limit int) ([]domain.FollowRelation, error) {
+	data, err := repo.dao.ListFollowers(ctx, followee, repo.cursor(utime), id, limit)
+	if err != nil {
+		return nil, err
+	}
+	return repo.toDomains(data), nil
+}
+
+func (repo *followRepository) GetStatics(ctx context.Context, uid int64) (domain.FollowStatics, error) {
+	res, err := repo.cache.GetStatics(ctx, uid)
+	if err == nil {
+		return res, nil
+	}
+	statics, err := repo.dao.GetStatics(ctx, uid)
+	switch err {
+	case nil:
+		res = domain.FollowStatics{
+			Followers: statics.Followers,
+			Followees: statics.Followees,
+		}
+	case follow.ErrRecordNotFound:
+		// 没有关注过别人，也没有被人关注过
+		res = domain.FollowStatics{}
+	default:
+		return domain.FollowStatics{}, err
+	}
+	if er := repo.cache.SetStatics(ctx, uid, res); er != nil {
+		repo.l.Error("设置关注统计缓存失败", logger.Error(er), logger.Int64("uid", uid))
+	}
+	return res, nil
+}
+
+func (repo *followRepository) GetStaticsByIds(ctx context.Context, uids []int64) (map[int64]domain.FollowStatics, error) {
+	res := make(map[int64]domain.FollowStatics, len(uids))
+	if len(uids) == 0 {
+		return res, nil
+	}
+	data, err := repo.dao.GetStaticsByIds(ctx, uids)
+	if err != nil {
+		return nil, err
+	}
+	for _, statics := range data {
+		res[statics.Uid] = domain.FollowStatics{
+			Followers: statics.Followers,
+			Followees: statics.Followees,
+		}
+	}
+	return res, nil
+}
+
+func (repo *followRepository) cursor(utime time.Time) int64 {
+	if utime.IsZero() {
+		return 0
+	}
+	return utime.UnixMilli()
+}
+
+func (repo *followRepository) toDomains(data []follow.FollowRelation) []domain.FollowRelation {
+	res := make([]domain.FollowRelation, 0, len(data))
+	for _, r := range data {
+		res = append(res, domain.FollowRelation{
+			Id:       r.ID,
+			Follower: r.Follower,
+			Followee: r.Followee,
+			Utime:    time.UnixMilli(r.Utime),
+		})
+	}
+	return res
+}
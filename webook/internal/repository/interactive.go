@@ -0,0 +1,171 @@
+package repository
+
+import (
+	"context"
+	"gitee.com/geekbang/basic-go/webook/internal/domain"
+	"gitee.com/geekbang/basic-go/webook/internal/repository/cache"
+	"gitee.com/geekbang/basic-go/webook/internal/repository/dao/interactive"
+	"gitee.com/geekbang/basic-go/webook/pkg/logger"
+	"time"
+)
+
+type InteractiveRepository interface {
+	IncrReadCnt(ctx context.Context, biz string, bizId int64) error
+	IncrLike(ctx context.Context, biz string, bizId int64, uid int64) error
+	DecrLike(ctx context.Context, biz string, bizId int64, uid int64) error
+	AddCollectionItem(ctx context.Context, biz string, bizId int64, uid int64) error
+	DelCollectionItem(ctx context.Context, biz string, bizId int64, uid int64) error
+	Get(ctx context.Context, biz string, bizId int64) (domain.Interactive, error)
+	// GetByIds 批量查询，不走缓存，没有互动数据的不会返回
+	GetByIds(ctx context.Context, biz string, bizIds []int64) ([]domain.Interactive, error)
+	Liked(ctx context.Context, biz string, bizId int64, uid int64) (bool, error)
+	Collected(ctx context.Context, biz string, bizId int64, uid int64) (bool, error)
+}
+
+type interactiveRepository struct {
+	dao   interactive.InteractiveDao
+	cache cache.InteractiveCache
+	l     logger.Logger
+}
+
+func NewInteractiveRepository(dao interactive.InteractiveDao,
+	c cache.InteractiveCache, log logger.Logger) InteractiveRepository {
+	return &interactiveRepository{
+		dao:   dao,
+		cache: c,
+		l:     log,
+	}
+}
+
+func (repo *interactiveRepository) IncrReadCnt(ctx context.Context, biz string, bizId int64) error {
+	err := repo.dao.IncrReadCnt(ctx, biz, bizId)
+	if err != nil {
+		return err
+	}
+	// 数据库已经成功了，缓存失败只记录日志，等缓存过期就好了
+	if err = repo.cache.IncrReadCntIfPresent(ctx, biz, bizId); err != nil {
+		repo.l.Error("更新阅读数缓存失败", logger.Error(err),
+			logger.String("biz", biz), logger.Int64("bizId", bizId))
+	}
+	return nil
+}
+
+func (repo *interactiveRepository) IncrLike(ctx context.Context, biz string, bizId int64, uid int64) error {
+	changed, err := repo.dao.SetLike(ctx, biz, bizId, uid, true)
+	if err != nil || !changed {
+		return err
+	}
+	if err = repo.cache.IncrLikeCntIfPresent(ctx, biz, bizId); err != nil {
+		repo.l.Error("更新点赞数缓存失败", logger.Error(err),
+			logger.String("biz", biz), logger.Int64("bizId", bizId))
+	}
+	return nil
+}
+
+func (repo *interactiveRepository) DecrLike(ctx context.Context, biz string, bizId int64, uid int64) error {
+	changed, err := repo.dao.SetLike(ctx, biz, bizId, uid, false)
+	if err != nil || !changed {
+		return err
+	}
+	if err = repo.cache.DecrLikeCntIfPresent(ctx, biz, bizId); err != nil {
+		repo.l.Error("更新点赞数缓存失败", logger.Error(err),
+			logger.String("biz", biz), logger.Int64("bizId", bizId))
+	}
+	return nil
+}
+
+func (repo *interactiveRepository) AddCollectionItem(ctx context.Context, biz string, bizId int64, uid int64) error {
+	changed, err := repo.dao.SetCollect(ctx, biz, bizId, uid, true)
+	if err != nil || !changed {
+		return err
+	}
+	if err = repo.cache.IncrCollectCntIfPresent(ctx, biz, bizId); err != nil {
+		repo.l.Error("更新收藏数缓存失败", logger.Error(err),
+			logger.String("biz", biz), logger.Int64("bizId", bizId))
+	}
+	return nil
+}
+
+func (repo *interactiveRepository) DelCollectionItem(ctx context.Context, biz string, bizId int64, uid int64) error {
+	changed, err := repo.dao.SetCollect(ctx, biz, bizId, uid, false)
+	if err != nil || !changed {
+		return err
+	}
+	if err = repo.cache.DecrCollectCntIfPresent(ctx, biz, bizId); err != nil {
+		repo.l.Error("更新收藏数缓存失败", logger.Error(err),
+			logger.String("biz", biz), logger.Int64("bizId", bizId))
+	}
+	return nil
+}
+
+func (repo *interactiveRepository) Get(ctx context.Context, biz string, bizId int64) (domain.Interactive, error) {
+	intr, err := repo.cache.Get(ctx, biz, bizId)
+	if err == nil {
+		return intr, nil
+	}
+	ie, err := repo.dao.Get(ctx, biz, bizId)
+	switch err {
+	case nil:
+		intr = repo.toDomain(ie)
+	case interactive.ErrRecordNotFound:
+		// 还没有人看过，全部都是 0
+		intr = domain.Interactive{Biz: biz, BizId: bizId}
+	default:
+		return domain.Interactive{}, err
+	}
+	go func() {
+		ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+		defer cancel()
+		if er := repo.cache.Set(ctx, intr); er != nil {
+			repo.l.Error("回写互动缓存失败", logger.Error(er),
+				logger.String("biz", biz), logger.Int64("bizId", bizId))
+		}
+	}()
+	return intr, nil
+}
+
+func (repo *interactiveRepository) GetByIds(ctx context.Context, biz string, bizIds []int64) ([]domain.Interactive, error) {
+	data, err := repo.dao.GetByIds(ctx, biz, bizIds)
+	if err != nil {
+		return nil, err
+	}
+	res := make([]domain.Interactive, 0, len(data))
+	for _, ie := range data {
+		res = append(res, repo.toDomain(ie))
+	}
+	return res, nil
+}
+
+func (repo *interactiveRepository) Liked(ctx context.Context, biz string, bizId int64, uid int64) (bool, error) {
+	_, err := repo.dao.GetLikeInfo(ctx, biz, bizId, uid)
+	switch err {
+	case nil:
+		return true, nil
+	case interactive.ErrRecordNotFound:
+		return false, nil
+	default:
+		return false, err
+	}
+}
+
+func (repo *interactiveRepository) Collected(ctx context.Context, biz string, bizId int64, uid int64) (bool, error) {
+	_, err := repo.dao.GetCollectInfo(ctx, biz, bizId, uid)
+	switch err {
+	case nil:
+		return true, nil
+	case interactive.ErrRecordNotFound:
+		return false, nil
+	default:
+		return false, err
+	}
+}
+
+func (repo *interactiveRepository) toDomain(ie interactive.Interactive) domain.Interactive {
+	return domain.Interactive{
+		Biz:        ie.Biz,
+		BizId:      ie.BizId,
+		ReadCnt:    ie.ReadCnt,
+		LikeCnt:    ie.LikeCnt,
+		CollectCnt: ie.CollectCnt,
+	}
+}
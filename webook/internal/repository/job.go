@@ -0,0 +1,66 @@
+package repository
+
+import (
+	"gitee.com/geekbang/basic-go/webook/internal/domain"
+	"gitee.com/geekbang/basic-go/webook/internal/repository/dao"
+	"golang.org/x/net/context"
+	"time"
+)
+
+var (
+	ErrNoJobToPreempt = dao.ErrNoJobToPreempt
+	ErrJobNotHold     = dao.ErrJobNotHold
+)
+
+type CronJobRepository interface {
+	AddJob(ctx context.Context, j domain.Job) error
+	Preempt(ctx context.Context, owner string, heartbeatDeadline time.Time) (domain.Job, error)
+	Heartbeat(ctx context.Context, id int64, owner string) error
+	Release(ctx context.Context, id int64, owner string, nextTime time.Time) error
+	Pause(ctx context.Context, id int64) error
+}
+
+type cronJobRepository struct {
+	dao dao.JobDao
+}
+
+func NewCronJobRepository(dao dao.JobDao) CronJobRepository {
+	return &cronJobRepository{dao: dao}
+}
+
+func (repo *cronJobRepository) AddJob(ctx context.Context, j domain.Job) error {
+	return repo.dao.Upsert(ctx, dao.Job{
+		Name:       j.Name,
+		Executor:   j.Executor,
+		Cfg:        j.Cfg,
+		Expression: j.Expression,
+		NextTime:   j.NextTime.UnixMilli(),
+	})
+}
+
+func (repo *cronJobRepository) Preempt(ctx context.Context, owner string, heartbeatDeadline time.Time) (domain.Job, error) {
+	j, err := repo.dao.Preempt(ctx, owner, heartbeatDeadline.UnixMilli())
+	if err != nil {
+		return domain.Job{}, err
+	}
+	return domain.Job{
+		Id:         j.Id,
+		Name:       j.Name,
+		Executor:   j.Executor,
+		Cfg:        j.Cfg,
+		Expression: j.Expression,
+		NextTime:   time.UnixMilli(j.NextTime),
+	}, nil
+}
+
+func (repo *cronJobRepository) Heartbeat(ctx context.Context, id int64, owner string) error {
+	return repo.dao.Heartbeat(ctx, id, owner)
+}
+
+func (repo *cronJobRepository) Release(ctx context.Context, id int64, owner string, nextTime time.Time) error {
+	return repo.dao.Release(ctx, id, owner, nextTime.UnixMilli())
+}
+
+func (repo *cronJobRepository) Pause(ctx context.Context, id int64) error {
+	return repo.dao.Pause(ctx, id)
+}
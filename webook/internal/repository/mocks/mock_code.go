@@ -3,7 +3,7 @@
 //
 // Generated by this command:
 //
-//	mockgen -source=code.go -destination=mocks/mock_code.go --package=
+//	mockgen -source=code.go -destination=mocks/mock_code.go --package=mock_repository
 //
 
 // Package mock_repository is a generated GoMock package.
@@ -40,30 +40,30 @@ func (m *MockCodeRepo) EXPECT() *MockCodeRepoMockRecorder {
 }
 
 // Set mocks base method.
-func (m *MockCodeRepo) Set(ctx context.Context, biz, phone, code string) error {
+func (m *MockCodeRepo) Set(ctx context.Context, channel, biz, target, code string) error {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "Set", ctx, biz, phone, code)
+	ret := m.ctrl.Call(m, "Set", ctx, channel, biz, target, code)
 	ret0, _ := ret[0].(error)
 	return ret0
 }
 
 // Set indicates an expected call of Set.
-func (mr *MockCodeRepoMockRecorder) Set(ctx, biz, phone, code any) *gomock.Call {
+func (mr *MockCodeRepoMockRecorder) Set(ctx, channel, biz, target, code any) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Set", reflect.TypeOf((*MockCodeRepo)(nil).Set), ctx, biz, phone, code)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Set", reflect.TypeOf((*MockCodeRepo)(nil).Set), ctx, channel, biz, target, code)
 }
 
 // Verify mocks base method.
-func (m *MockCodeRepo) Verify(ctx context.Context, biz, phone, code string) (bool, error) {
+func (m *MockCodeRepo) Verify(ctx context.Context, channel, biz, target, code string) (bool, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "Verify", ctx, biz, phone, code)
+	ret := m.ctrl.Call(m, "Verify", ctx, channel, biz, target, code)
 	ret0, _ := ret[0].(bool)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // Verify indicates an expected call of Verify.
-func (mr *MockCodeRepoMockRecorder) Verify(ctx, biz, phone, code any) *gomock.Call {
+func (mr *MockCodeRepoMockRecorder) Verify(ctx, channel, biz, target, code any) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Verify", reflect.TypeOf((*MockCodeRepo)(nil).Verify), ctx, biz, phone, code)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Verify", reflect.TypeOf((*MockCodeRepo)(nil).Verify), ctx, channel, biz, target, code)
 }
@@ -0,0 +1,100 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: feed.go
+//
+// Generated by this command:
+//
+//	mockgen -source=feed.go -destination=mocks/mock_feed.go --package=mock_repository
+//
+
+// Package mock_repository is a generated GoMock package.
+package mock_repository
+
+import (
+	context "context"
+	reflect "reflect"
+	time "time"
+
+	domain "gitee.com/geekbang/basic-go/webook/internal/domain"
+	gomock "go.uber.org/mock/gomock"
+)
+
+// MockFeedRepository is a mock of FeedRepository interface.
+type MockFeedRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockFeedRepositoryMockRecorder
+}
+
+// MockFeedRepositoryMockRecorder is the mock recorder for MockFeedRepository.
+type MockFeedRepositoryMockRecorder struct {
+	mock *MockFeedRepository
+}
+
+// NewMockFeedRepository creates a new mock instance.
+func NewMockFeedRepository(ctrl *gomock.Controller) *MockFeedRepository {
+	mock := &MockFeedRepository{ctrl: ctrl}
+	mock.recorder = &MockFeedRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockFeedRepository) EXPECT() *MockFeedRepositoryMockRecorder {
+	return m.recorder
+}
+
+// CreatePullEvent mocks base method.
+func (m *MockFeedRepository) CreatePullEvent(ctx context.Context, evt domain.FeedEvent) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreatePullEvent", ctx, evt)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// CreatePullEvent indicates an expected call of CreatePullEvent.
+func (mr *MockFeedRepositoryMockRecorder) CreatePullEvent(ctx, evt any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreatePullEvent", reflect.TypeOf((*MockFeedRepository)(nil).CreatePullEvent), ctx, evt)
+}
+
+// CreatePushEvents mocks base method.
+func (m *MockFeedRepository) CreatePushEvents(ctx context.Context, uids []int64, evt domain.FeedEvent) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreatePushEvents", ctx, uids, evt)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// CreatePushEvents indicates an expected call of CreatePushEvents.
+func (mr *MockFeedRepositoryMockRecorder) CreatePushEvents(ctx, uids, evt any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreatePushEvents", reflect.TypeOf((*MockFeedRepository)(nil).CreatePushEvents), ctx, uids, evt)
+}
+
+// FindPullEvents mocks base method.
+func (m *MockFeedRepository) FindPullEvents(ctx context.Context, authorIds []int64, ctime time.Time, artId int64, limit int) ([]domain.FeedEvent, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FindPullEvents", ctx, authorIds, ctime, artId, limit)
+	ret0, _ := ret[0].([]domain.FeedEvent)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FindPullEvents indicates an expected call of FindPullEvents.
+func (mr *MockFeedRepositoryMockRecorder) FindPullEvents(ctx, authorIds, ctime, artId, limit any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindPullEvents", reflect.TypeOf((*MockFeedRepository)(nil).FindPullEvents), ctx, authorIds, ctime, artId, limit)
+}
+
+// FindPushEvents mocks base method.
+func (m *MockFeedRepository) FindPushEvents(ctx context.Context, uid int64, ctime time.Time, artId int64, limit int) ([]domain.FeedEvent, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FindPushEvents", ctx, uid, ctime, artId, limit)
+	ret0, _ := ret[0].([]domain.FeedEvent)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FindPushEvents indicates an expected call of FindPushEvents.
+func (mr *MockFeedRepositoryMockRecorder) FindPushEvents(ctx, uid, ctime, artId, limit any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindPushEvents", reflect.TypeOf((*MockFeedRepository)(nil).FindPushEvents), ctx, uid, ctime, artId, limit)
+}
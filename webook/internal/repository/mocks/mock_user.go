@@ -3,7 +3,7 @@
 //
 // Generated by this command:
 //
-//	mockgen -source=user.go -destination=mocks/mock_user.go --package=
+//	mockgen -source=user.go -destination=mocks/mock_user.go --package=mock_repository
 //
 
 // Package mock_repository is a generated GoMock package.
@@ -11,6 +11,7 @@ package mock_repository
 
 import (
 	reflect "reflect"
+	time "time"
 
 	domain "gitee.com/geekbang/basic-go/webook/internal/domain"
 	gomock "go.uber.org/mock/gomock"
@@ -40,6 +41,36 @@ func (m *MockUserRepo) EXPECT() *MockUserRepoMockRecorder {
 	return m.recorder
 }
 
+// ConsumeResetTicket mocks base method.
+func (m *MockUserRepo) ConsumeResetTicket(ctx context.Context, ticket string) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ConsumeResetTicket", ctx, ticket)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ConsumeResetTicket indicates an expected call of ConsumeResetTicket.
+func (mr *MockUserRepoMockRecorder) ConsumeResetTicket(ctx, ticket any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ConsumeResetTicket", reflect.TypeOf((*MockUserRepo)(nil).ConsumeResetTicket), ctx, ticket)
+}
+
+// ConsumeSignUpPassword mocks base method.
+func (m *MockUserRepo) ConsumeSignUpPassword(ctx context.Context, email string) (string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ConsumeSignUpPassword", ctx, email)
+	ret0, _ := ret[0].(string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ConsumeSignUpPassword indicates an expected call of ConsumeSignUpPassword.
+func (mr *MockUserRepoMockRecorder) ConsumeSignUpPassword(ctx, email any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ConsumeSignUpPassword", reflect.TypeOf((*MockUserRepo)(nil).ConsumeSignUpPassword), ctx, email)
+}
+
 // Create mocks base method.
 func (m *MockUserRepo) Create(ctx context.Context, user domain.User) error {
 	m.ctrl.T.Helper()
@@ -54,6 +85,34 @@ func (mr *MockUserRepoMockRecorder) Create(ctx, user any) *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Create", reflect.TypeOf((*MockUserRepo)(nil).Create), ctx, user)
 }
 
+// CreateResetTicket mocks base method.
+func (m *MockUserRepo) CreateResetTicket(ctx context.Context, ticket string, uid int64, expiration time.Duration) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateResetTicket", ctx, ticket, uid, expiration)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// CreateResetTicket indicates an expected call of CreateResetTicket.
+func (mr *MockUserRepoMockRecorder) CreateResetTicket(ctx, ticket, uid, expiration any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateResetTicket", reflect.TypeOf((*MockUserRepo)(nil).CreateResetTicket), ctx, ticket, uid, expiration)
+}
+
+// Deactivate mocks base method.
+func (m *MockUserRepo) Deactivate(ctx context.Context, id int64) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Deactivate", ctx, id)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Deactivate indicates an expected call of Deactivate.
+func (mr *MockUserRepoMockRecorder) Deactivate(ctx, id any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Deactivate", reflect.TypeOf((*MockUserRepo)(nil).Deactivate), ctx, id)
+}
+
 // FindByEmail mocks base method.
 func (m *MockUserRepo) FindByEmail(ctx context.Context, email string) (domain.User, error) {
 	m.ctrl.T.Helper()
@@ -98,3 +157,160 @@ func (mr *MockUserRepoMockRecorder) FindByPhone(ctx, phone any) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindByPhone", reflect.TypeOf((*MockUserRepo)(nil).FindByPhone), ctx, phone)
 }
+
+// FindByWechat mocks base method.
+func (m *MockUserRepo) FindByWechat(ctx context.Context, openID string) (domain.User, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FindByWechat", ctx, openID)
+	ret0, _ := ret[0].(domain.User)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FindByWechat indicates an expected call of FindByWechat.
+func (mr *MockUserRepoMockRecorder) FindByWechat(ctx, openID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindByWechat", reflect.TypeOf((*MockUserRepo)(nil).FindByWechat), ctx, openID)
+}
+
+// FindRoles mocks base method.
+func (m *MockUserRepo) FindRoles(ctx context.Context, uid int64) ([]domain.Role, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FindRoles", ctx, uid)
+	ret0, _ := ret[0].([]domain.Role)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FindRoles indicates an expected call of FindRoles.
+func (mr *MockUserRepoMockRecorder) FindRoles(ctx, uid any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindRoles", reflect.TypeOf((*MockUserRepo)(nil).FindRoles), ctx, uid)
+}
+
+// List mocks base method.
+func (m *MockUserRepo) List(ctx context.Context, offset, limit int) ([]domain.User, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "List", ctx, offset, limit)
+	ret0, _ := ret[0].([]domain.User)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// List indicates an expected call of List.
+func (mr *MockUserRepoMockRecorder) List(ctx, offset, limit any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "List", reflect.TypeOf((*MockUserRepo)(nil).List), ctx, offset, limit)
+}
+
+// RemoveLoginMethod mocks base method.
+func (m *MockUserRepo) RemoveLoginMethod(ctx context.Context, id int64, method domain.LoginMethod) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RemoveLoginMethod", ctx, id, method)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RemoveLoginMethod indicates an expected call of RemoveLoginMethod.
+func (mr *MockUserRepoMockRecorder) RemoveLoginMethod(ctx, id, method any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RemoveLoginMethod", reflect.TypeOf((*MockUserRepo)(nil).RemoveLoginMethod), ctx, id, method)
+}
+
+// SetSignUpPassword mocks base method.
+func (m *MockUserRepo) SetSignUpPassword(ctx context.Context, email, password string, expiration time.Duration) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetSignUpPassword", ctx, email, password, expiration)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SetSignUpPassword indicates an expected call of SetSignUpPassword.
+func (mr *MockUserRepoMockRecorder) SetSignUpPassword(ctx, email, password, expiration any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetSignUpPassword", reflect.TypeOf((*MockUserRepo)(nil).SetSignUpPassword), ctx, email, password, expiration)
+}
+
+// UpdateEmail mocks base method.
+func (m *MockUserRepo) UpdateEmail(ctx context.Context, id int64, email string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateEmail", ctx, id, email)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdateEmail indicates an expected call of UpdateEmail.
+func (mr *MockUserRepoMockRecorder) UpdateEmail(ctx, id, email any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateEmail", reflect.TypeOf((*MockUserRepo)(nil).UpdateEmail), ctx, id, email)
+}
+
+// UpdateNonSensitiveInfo mocks base method.
+func (m *MockUserRepo) UpdateNonSensitiveInfo(ctx context.Context, user domain.User) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateNonSensitiveInfo", ctx, user)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdateNonSensitiveInfo indicates an expected call of UpdateNonSensitiveInfo.
+func (mr *MockUserRepoMockRecorder) UpdateNonSensitiveInfo(ctx, user any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateNonSensitiveInfo", reflect.TypeOf((*MockUserRepo)(nil).UpdateNonSensitiveInfo), ctx, user)
+}
+
+// UpdatePassword mocks base method.
+func (m *MockUserRepo) UpdatePassword(ctx context.Context, id int64, password string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdatePassword", ctx, id, password)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdatePassword indicates an expected call of UpdatePassword.
+func (mr *MockUserRepoMockRecorder) UpdatePassword(ctx, id, password any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdatePassword", reflect.TypeOf((*MockUserRepo)(nil).UpdatePassword), ctx, id, password)
+}
+
+// UpdatePhone mocks base method.
+func (m *MockUserRepo) UpdatePhone(ctx context.Context, id int64, phone string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdatePhone", ctx, id, phone)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdatePhone indicates an expected call of UpdatePhone.
+func (mr *MockUserRepoMockRecorder) UpdatePhone(ctx, id, phone any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdatePhone", reflect.TypeOf((*MockUserRepo)(nil).UpdatePhone), ctx, id, phone)
+}
+
+// UpdateStatus mocks base method.
+func (m *MockUserRepo) UpdateStatus(ctx context.Context, id int64, status domain.UserStatus) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateStatus", ctx, id, status)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdateStatus indicates an expected call of UpdateStatus.
+func (mr *MockUserRepoMockRecorder) UpdateStatus(ctx, id, status any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateStatus", reflect.TypeOf((*MockUserRepo)(nil).UpdateStatus), ctx, id, status)
+}
+
+// UpdateWechat mocks base method.
+func (m *MockUserRepo) UpdateWechat(ctx context.Context, id int64, info domain.WechatInfo) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateWechat", ctx, id, info)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdateWechat indicates an expected call of UpdateWechat.
+func (mr *MockUserRepoMockRecorder) UpdateWechat(ctx, id, info any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateWechat", reflect.TypeOf((*MockUserRepo)(nil).UpdateWechat), ctx, id, info)
+}
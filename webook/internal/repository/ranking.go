@@ -0,0 +1,49 @@
+package repository
+
+import (
+	"context"
+	"gitee.com/geekbang/basic-go/webook/internal/domain"
+	"gitee.com/geekbang/basic-go/webook/internal/repository/cache"
+	"gitee.com/geekbang/basic-go/webook/pkg/logger"
+)
+
+type RankingRepository interface {
+	ReplaceTopN(ctx context.Context, arts []domain.Article) error
+	GetTopN(ctx context.Context) ([]domain.Article, error)
+}
+
+// CachedRankingRepository 热榜只存在缓存里面，本地缓存 + Redis 两级
+type CachedRankingRepository struct {
+	redis *cache.RankingRedisCache
+	local *cache.RankingLocalCache
+	l     logger.Logger
+}
+
+func NewCachedRankingRepository(redis *cache.RankingRedisCache,
+	local *cache.RankingLocalCache, log logger.Logger) RankingRepository {
+	return &CachedRankingRepository{
+		redis: redis,
+		local: local,
+		l:     log,
+	}
+}
+
+func (repo *CachedRankingRepository) ReplaceTopN(ctx context.Context, arts []domain.Article) error {
+	// 本地缓存基本不会失败
+	_ = repo.local.Set(ctx, arts)
+	return repo.redis.Set(ctx, arts)
+}
+
+func (repo *CachedRankingRepository) GetTopN(ctx context.Context) ([]domain.Article, error) {
+	arts, err := repo.local.Get(ctx)
+	if err == nil {
+		return arts, nil
+	}
+	arts, err = repo.redis.Get(ctx)
+	if err == nil {
+		_ = repo.local.Set(ctx, arts)
+		return arts, nil
+	}
+	repo.l.Warn("从 Redis 获取热榜失败，使用本地旧数据兜底", logger.Error(err))
+	return repo.local.ForceGet(ctx)
+}
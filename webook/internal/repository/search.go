@@ -0,0 +1,119 @@
+package repository
+
+import (
+	"context"
+	"gitee.com/geekbang/basic-go/webook/internal/domain"
+	"gitee.com/geekbang/basic-go/webook/internal/repository/dao/article"
+	"gitee.com/geekbang/basic-go/webook/pkg/logger"
+	"gitee.com/geekbang/basic-go/webook/pkg/search"
+	"sync"
+	"time"
+)
+
+// SearchRepository 搜索引擎的抽象，以后换成 Elasticsearch 之类的只需要换实现
+type SearchRepository interface {
+	// InputArticle 新增或者覆盖一篇已发表的文章
+	InputArticle(ctx context.Context, art domain.Article) error
+	DeleteArticle(ctx context.Context, id int64) error
+	SearchArticle(ctx context.Context, query string, offset int, limit int) ([]domain.SearchArticle, error)
+}
+
+// LocalSearchRepository 基于内存倒排索引的实现
+// 索引只在本实例里面，启动的时候要调用 Rebuild 从数据库加载；
+// 部署多个实例的时候，别的实例发表的文章要等重建才能搜到，这种情况应该换成独立的搜索引擎
+type LocalSearchRepository struct {
+	idx *search.Index
+	dao article.ArticleDao
+	l   logger.Logger
+	// 索引里面只有 ID，展示用的数据放在这里
+	mu   sync.RWMutex
+	arts map[int64]domain.Article
+}
+
+func NewLocalSearchRepository(dao article.ArticleDao, l logger.Logger) *LocalSearchRepository {
+	return &LocalSearchRepository{
+		// 标题命中比内容命中重要
+		idx:  search.NewIndex(map[string]float64{"title": 3, "content": 1}),
+		dao:  dao,
+		l:    l,
+		arts: make(map[int64]domain.Article),
+	}
+}
+
+func (r *LocalSearchRepository) InputArticle(ctx context.Context, art domain.Article) error {
+	r.idx.Put(search.Document{
+		Id: art.Id,
+		Fields: map[string]string{
+			"title":   art.Title,
+			"content": art.Content,
+		},
+	})
+	r.mu.Lock()
+	r.arts[art.Id] = art
+	r.mu.Unlock()
+	return nil
+}
+
+func (r *LocalSearchRepository) DeleteArticle(ctx context.Context, id int64) error {
+	r.idx.Delete(id)
+	r.mu.Lock()
+	delete(r.arts, id)
+	r.mu.Unlock()
+	return nil
+}
+
+func (r *LocalSearchRepository) SearchArticle(ctx context.Context, query string, offset int, limit int) ([]domain.SearchArticle, error) {
+	hits := r.idx.Search(query, offset, limit)
+	res := make([]domain.SearchArticle, 0, len(hits))
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	for _, hit := range hits {
+		art, ok := r.arts[hit.Id]
+		if !ok {
+			// 刚好被删除了
+			continue
+		}
+		res = append(res, domain.SearchArticle{
+			Id:       art.Id,
+			Title:    search.Highlight(art.Title, query, "<em>", "</em>", 0),
+			Abstract: search.Highlight(art.Content, query, "<em>", "</em>", 100),
+			Author:   art.Author,
+			Utime:    art.Utime,
+			Score:    hit.Score,
+		})
+	}
+	return res, nil
+}
+
+// Rebuild 把数据库里面所有已发表的文章加载到索引里面
+func (r *LocalSearchRepository) Rebuild(ctx context.Context) error {
+	const batchSize = 100
+	var (
+		utime int64
+		id    int64
+		cnt   int
+	)
+	for {
+		arts, err := r.dao.ListPub(ctx, domain.ArticleStatusPublished.ToUint8(), utime, id, batchSize)
+		if err != nil {
+			return err
+		}
+		for _, art := range arts {
+			_ = r.InputArticle(ctx, domain.Article{
+				Id:      art.ID,
+				Title:   art.Title,
+				Content: art.Content,
+				Author:  domain.Author{Id: art.AuthorID},
+				Utime:   time.UnixMilli(art.Utime),
+			})
+		}
+		cnt += len(arts)
+		if len(arts) < batchSize {
+			break
+		}
+		last := arts[len(arts)-1]
+		utime, id = last.Utime, last.ID
+	}
+	r.l.Info("重建搜索索引", logger.Int64("cnt", int64(cnt)))
+	return nil
+}
@@ -2,6 +2,7 @@ package repository
 
 import (
 	"database/sql"
+	"errors"
 	"gitee.com/geekbang/basic-go/webook/internal/domain"
 	"gitee.com/geekbang/basic-go/webook/internal/repository/cache"
 	"gitee.com/geekbang/basic-go/webook/internal/repository/dao"
@@ -10,8 +11,10 @@ import (
 )
 
 var (
-	ErrUserDuplicate = dao.ErrUserDuplicate
-	ErrUserNotFound  = dao.ErrUserNotFound
+	ErrUserDuplicate      = dao.ErrUserDuplicate
+	ErrUserNotFound       = dao.ErrUserNotFound
+	ErrResetTicketInvalid = errors.New("重置密码的凭证无效")
+	ErrLastLoginMethod    = dao.ErrLastLoginMethod
 )
 
 //go:generate mockgen -source=$GOFILE -destination=mocks/mock_$GOFILE --package=$GOPACKAGEmocks
@@ -21,6 +24,27 @@ type UserRepo interface {
 	FindById(ctx context.Context, id int64) (domain.User, error)
 	FindByPhone(ctx context.Context, phone string) (domain.User, error)
 	FindByWechat(ctx context.Context, openID string) (domain.User, error)
+	// UpdateNonSensitiveInfo 更新昵称、生日、个人简介这些不需要验证身份的信息
+	UpdateNonSensitiveInfo(ctx context.Context, user domain.User) error
+	UpdatePassword(ctx context.Context, id int64, password string) error
+	UpdatePhone(ctx context.Context, id int64, phone string) error
+	UpdateEmail(ctx context.Context, id int64, email string) error
+	UpdateStatus(ctx context.Context, id int64, status domain.UserStatus) error
+	UpdateWechat(ctx context.Context, id int64, info domain.WechatInfo) error
+	// RemoveLoginMethod 最后一种登录方式不能删除，返回 ErrLastLoginMethod
+	RemoveLoginMethod(ctx context.Context, id int64, method domain.LoginMethod) error
+	CreateResetTicket(ctx context.Context, ticket string, uid int64, expiration time.Duration) error
+	// ConsumeResetTicket 凭证不存在或者已经用过了返回 ErrResetTicketInvalid
+	ConsumeResetTicket(ctx context.Context, ticket string) (int64, error)
+	// SetSignUpPassword 暂存重新注册时的新密码，已经加密过了
+	SetSignUpPassword(ctx context.Context, email string, password string, expiration time.Duration) error
+	// ConsumeSignUpPassword 取出暂存的新密码，没有的时候返回空字符串
+	ConsumeSignUpPassword(ctx context.Context, email string) (string, error)
+	// Deactivate 注销账号，清空个人信息，并且删除缓存
+	Deactivate(ctx context.Context, id int64) error
+	List(ctx context.Context, offset int, limit int) ([]domain.User, error)
+	// FindRoles 不走缓存，只在登录和刷新 token 的时候用
+	FindRoles(ctx context.Context, uid int64) ([]domain.Role, error)
 }
 
 type userRepoImpl struct {
@@ -72,6 +96,121 @@ func (u *userRepoImpl) FindByEmail(ctx context.Context, email string) (domain.Us
 	return u.daoToDomain(user), nil
 }
 
+func (u *userRepoImpl) UpdateNonSensitiveInfo(ctx context.Context, user domain.User) error {
+	err := u.dao.UpdateProfile(ctx, u.domainToDao(user))
+	if err != nil {
+		return err
+	}
+	return u.cache.Del(ctx, user.Id)
+}
+
+func (u *userRepoImpl) UpdatePassword(ctx context.Context, id int64, password string) error {
+	err := u.dao.UpdatePassword(ctx, id, password)
+	if err != nil {
+		return err
+	}
+	// 缓存里面有密码
+	return u.cache.Del(ctx, id)
+}
+
+func (u *userRepoImpl) UpdatePhone(ctx context.Context, id int64, phone string) error {
+	err := u.dao.UpdatePhone(ctx, id, phone)
+	if err != nil {
+		return err
+	}
+	return u.cache.Del(ctx, id)
+}
+
+func (u *userRepoImpl) UpdateEmail(ctx context.Context, id int64, email string) error {
+	err := u.dao.UpdateEmail(ctx, id, email)
+	if err != nil {
+		return err
+	}
+	return u.cache.Del(ctx, id)
+}
+
+func (u *userRepoImpl) UpdateStatus(ctx context.Context, id int64, status domain.UserStatus) error {
+	err := u.dao.UpdateStatus(ctx, id, uint8(status))
+	if err != nil {
+		return err
+	}
+	return u.cache.Del(ctx, id)
+}
+
+func (u *userRepoImpl) UpdateWechat(ctx context.Context, id int64, info domain.WechatInfo) error {
+	err := u.dao.UpdateWechat(ctx, id, info.OpenID, info.UnionID)
+	if err != nil {
+		return err
+	}
+	return u.cache.Del(ctx, id)
+}
+
+func (u *userRepoImpl) RemoveLoginMethod(ctx context.Context, id int64, method domain.LoginMethod) error {
+	err := u.dao.RemoveLoginMethod(ctx, id, string(method))
+	if err != nil {
+		return err
+	}
+	return u.cache.Del(ctx, id)
+}
+
+func (u *userRepoImpl) Deactivate(ctx context.Context, id int64) error {
+	err := u.dao.Deactivate(ctx, id, uint8(domain.UserStatusDeactivated), domain.DeactivatedNickname)
+	if err != nil {
+		return err
+	}
+	return u.cache.Del(ctx, id)
+}
+
+func (u *userRepoImpl) List(ctx context.Context, offset int, limit int) ([]domain.User, error) {
+	users, err := u.dao.List(ctx, offset, limit)
+	if err != nil {
+		return nil, err
+	}
+	res := make([]domain.User, 0, len(users))
+	for _, user := range users {
+		res = append(res, u.daoToDomain(user))
+	}
+	return res, nil
+}
+
+func (u *userRepoImpl) FindRoles(ctx context.Context, uid int64) ([]domain.Role, error) {
+	roles, err := u.dao.FindRoles(ctx, uid)
+	if err != nil {
+		return nil, err
+	}
+	res := make([]domain.Role, 0, len(roles))
+	for _, r := range roles {
+		if role := domain.Role(r); role.Valid() {
+			res = append(res, role)
+		}
+	}
+	return res, nil
+}
+
+func (u *userRepoImpl) CreateResetTicket(ctx context.Context, ticket string, uid int64, expiration time.Duration) error {
+	return u.cache.SetResetTicket(ctx, ticket, uid, expiration)
+}
+
+func (u *userRepoImpl) ConsumeResetTicket(ctx context.Context, ticket string) (int64, error) {
+	uid, err := u.cache.ConsumeResetTicket(ctx, ticket)
+	if err == cache.ErrKeyNotExist {
+		return 0, ErrResetTicketInvalid
+	}
+	return uid, err
+}
+
+func (u *userRepoImpl) SetSignUpPassword(ctx context.Context, email string, password string, expiration time.Duration) error {
+	return u.cache.SetSignUpPassword(ctx, email, password, expiration)
+}
+
+func (u *userRepoImpl) ConsumeSignUpPassword(ctx context.Context, email string) (string, error) {
+	password, err := u.cache.ConsumeSignUpPassword(ctx, email)
+	if err == cache.ErrKeyNotExist {
+		return "", nil
+	}
+	return password, err
+}
+
 func (u *userRepoImpl) Create(ctx context.Context, user domain.User) error {
 	return u.dao.Insert(ctx, u.domainToDao(user))
 }
@@ -88,6 +227,13 @@ func (u *userRepoImpl) domainToDao(user domain.User) dao.User {
 			Valid:  user.Phone != "",
 		},
 		Password: user.Password,
+		Nickname: user.Nickname,
+		Birthday: sql.NullInt64{
+			Int64: user.Birthday.UnixMilli(),
+			Valid: !user.Birthday.IsZero(),
+		},
+		AboutMe: user.AboutMe,
+		Status:  uint8(user.Status),
 		WechatUnionID: sql.NullString{
 			String: user.WechatInfo.UnionID,
 			Valid:  user.WechatInfo.UnionID != "",
@@ -100,11 +246,19 @@ func (u *userRepoImpl) domainToDao(user domain.User) dao.User {
 }
 
 func (u *userRepoImpl) daoToDomain(user dao.User) domain.User {
+	var birthday time.Time
+	if user.Birthday.Valid {
+		birthday = time.UnixMilli(user.Birthday.Int64)
+	}
 	return domain.User{
 		Id:       user.Id,
 		Email:    user.Email.String,
 		Phone:    user.Phone.String,
 		Password: user.Password,
+		Nickname: user.Nickname,
+		Birthday: birthday,
+		AboutMe:  user.AboutMe,
+		Status:   domain.UserStatus(user.Status),
 		WechatInfo: domain.WechatInfo{
 			UnionID: user.WechatUnionID.String,
 			OpenID:  user.WechatOpenID.String,
@@ -2,16 +2,32 @@ package service
 
 import (
 	"context"
+	"errors"
 	"gitee.com/geekbang/basic-go/webook/internal/domain"
 	"gitee.com/geekbang/basic-go/webook/internal/repository"
 	"gitee.com/geekbang/basic-go/webook/pkg/logger"
+	"strings"
+	"time"
+	"unicode/utf8"
 )
 
 var (
 	ErrArticleDuplicate        = repository.ErrArticleDuplicate
 	ErrPossibleIncorrectAuthor = repository.ErrPossibleIncorrectAuthor
+	ErrInvalidPublishTime      = errors.New("定时发表的时间必须在将来")
+	ErrArticleNotFound         = repository.ErrArticleNotFound
+	ErrTooManyTags             = errors.New("标签太多")
+	ErrInvalidTag              = errors.New("标签不合法")
 )
 
+const (
+	// 一篇文章最多多少个标签
+	maxTagCnt = 5
+	// 一个标签最多多少个字
+	maxTagLen = 20
+)
+
+//go:generate mockgen -source=$GOFILE -destination=mocks/mock_$GOFILE --package=$GOPACKAGEmocks
 type ArticleService interface {
 	Save(ctx context.Context, art domain.Article) (int64, error)
 	Publish(ctx context.Context, art domain.Article) (int64, error)
@@ -19,23 +35,200 @@ type ArticleService interface {
 	List(ctx context.Context, uid int64, offset int, limit int) ([]domain.Article, error)
 	GetById(ctx context.Context, id int64) (domain.Article, error)
 	GetPubById(ctx context.Context, id int64) (domain.Article, error)
+	// ListPub 游标分页，utime 和 id 是上一页最后一篇文章的
+	ListPub(ctx context.Context, utime time.Time, id int64, limit int) ([]domain.Article, error)
+	ListRevisions(ctx context.Context, uid int64, artId int64, offset int, limit int) ([]domain.ArticleRevision, error)
+	// GetRevision 只能查看自己的文章的版本
+	GetRevision(ctx context.Context, uid int64, id int64) (domain.ArticleRevision, error)
+	// Restore 把某个历史版本恢复成当前的草稿，恢复本身也会生成一个新的版本
+	Restore(ctx context.Context, uid int64, artId int64, revisionId int64) error
+	// Schedule 保存草稿，并且在 art.PublishTime 的时候自动发表
+	Schedule(ctx context.Context, art domain.Article) (int64, error)
+	// CancelSchedule 取消定时发表，文章回到未发表状态
+	CancelSchedule(ctx context.Context, uid int64, id int64) error
+	// PublishScheduled 发表 now 之前就该发表的定时文章，最多 limit 篇，返回发表了多少篇
+	PublishScheduled(ctx context.Context, now time.Time, limit int) (int, error)
+	// Delete 软删除，在保留期内还可以恢复
+	Delete(ctx context.Context, uid int64, id int64) error
+	// Recover 恢复保留期内删除的文章，超过保留期的返回 ErrArticleNotFound
+	Recover(ctx context.Context, uid int64, id int64) error
+	// ListDeleted 保留期内删除的文章，也就是回收站
+	ListDeleted(ctx context.Context, uid int64, offset int, limit int) ([]domain.Article, error)
+	// ListByTag 标签下面已经发表的文章
+	ListByTag(ctx context.Context, tag string, offset int, limit int) ([]domain.Article, error)
+	// PopularTags 已发表文章最多的 n 个标签
+	PopularTags(ctx context.Context, n int) ([]domain.Tag, error)
+	// ForceWithdraw 管理员下架文章，不校验作者，没有发表过的返回 ErrArticleNotFound
+	ForceWithdraw(ctx context.Context, id int64) error
 }
 
 type articleService struct {
-	repo repository.ArticleRepository
-	log  logger.Logger
+	repo    repository.ArticleRepository
+	feedSvc FeedService
+	log     logger.Logger
+	// 删除之后多久之内可以恢复
+	retention time.Duration
 }
 
 func (s *articleService) GetPubById(ctx context.Context, id int64) (domain.Article, error) {
 	return s.repo.GetPubById(ctx, id)
 }
 
+func (s *articleService) ListPub(ctx context.Context, utime time.Time, id int64, limit int) ([]domain.Article, error) {
+	return s.repo.ListPub(ctx, utime, id, limit)
+}
+
+func (s *articleService) ListRevisions(ctx context.Context, uid int64, artId int64, offset int, limit int) ([]domain.ArticleRevision, error) {
+	return s.repo.ListRevisions(ctx, uid, artId, offset, limit)
+}
+
+func (s *articleService) GetRevision(ctx context.Context, uid int64, id int64) (domain.ArticleRevision, error) {
+	rev, err := s.repo.GetRevision(ctx, id)
+	if err != nil {
+		return domain.ArticleRevision{}, err
+	}
+	if rev.AuthorId != uid {
+		return domain.ArticleRevision{}, ErrPossibleIncorrectAuthor
+	}
+	return rev, nil
+}
+
+func (s *articleService) Restore(ctx context.Context, uid int64, artId int64, revisionId int64) error {
+	rev, err := s.GetRevision(ctx, uid, revisionId)
+	if err != nil {
+		return err
+	}
+	if rev.ArticleId != artId {
+		return ErrPossibleIncorrectAuthor
+	}
+	// 走 Save 的逻辑，Update 里面会再一次校验 author_id
+	_, err = s.Save(ctx, domain.Article{
+		Id:      artId,
+		Title:   rev.Title,
+		Content: rev.Content,
+		Author: domain.Author{
+			Id: uid,
+		},
+	})
+	return err
+}
+
+func (s *articleService) Schedule(ctx context.Context, art domain.Article) (int64, error) {
+	if !art.PublishTime.After(time.Now()) {
+		return 0, ErrInvalidPublishTime
+	}
+	var err error
+	if art.Tags, err = s.normalizeTags(art.Tags); err != nil {
+		return 0, err
+	}
+	art.Status = domain.ArticleStatusScheduled
+	if art.Id == 0 {
+		return s.repo.Create(ctx, art)
+	}
+	err = s.repo.Update(ctx, art)
+	return art.Id, err
+}
+
+func (s *articleService) CancelSchedule(ctx context.Context, uid int64, id int64) error {
+	return s.repo.CancelSchedule(ctx, uid, id)
+}
+
+func (s *articleService) PublishScheduled(ctx context.Context, now time.Time, limit int) (int, error) {
+	arts, err := s.repo.ListScheduled(ctx, now, limit)
+	if err != nil {
+		return 0, err
+	}
+	cnt := 0
+	for _, art := range arts {
+		if ctx.Err() != nil {
+			return cnt, ctx.Err()
+		}
+		// 先把状态从定时改成已发表，改成功了才有资格发表
+		// 这样作者中途取消，或者别的实例已经发表了，这里都不会重复发表
+		ok, err := s.repo.TransitStatus(ctx, art,
+			domain.ArticleStatusScheduled, domain.ArticleStatusPublished)
+		if err != nil {
+			s.log.Error("抢占定时发表的文章失败",
+				logger.Int64("aid", art.Id), logger.Error(err))
+			continue
+		}
+		if !ok {
+			continue
+		}
+		art.Status = domain.ArticleStatusPublished
+		if _, err = s.repo.Sync(ctx, art); err != nil {
+			s.log.Error("定时发表文章失败",
+				logger.Int64("aid", art.Id), logger.Error(err))
+			// 改回定时状态，下一轮再试
+			_, er := s.repo.TransitStatus(ctx, art,
+				domain.ArticleStatusPublished, domain.ArticleStatusScheduled)
+			if er != nil {
+				s.log.Error("恢复定时发表状态失败",
+					logger.Int64("aid", art.Id), logger.Error(er))
+			}
+			continue
+		}
+		s.publishFeed(art)
+		cnt++
+	}
+	return cnt, nil
+}
+
+func (s *articleService) ListByTag(ctx context.Context, tag string, offset int, limit int) ([]domain.Article, error) {
+	return s.repo.ListByTag(ctx, strings.TrimSpace(tag), offset, limit)
+}
+
+func (s *articleService) PopularTags(ctx context.Context, n int) ([]domain.Tag, error) {
+	return s.repo.PopularTags(ctx, n)
+}
+
+// normalizeTags 去掉首尾空格和重复的标签，nil 表示不修改标签，原样返回
+func (s *articleService) normalizeTags(tags []string) ([]string, error) {
+	if tags == nil {
+		return nil, nil
+	}
+	res := make([]string, 0, len(tags))
+	seen := make(map[string]struct{}, len(tags))
+	for _, tag := range tags {
+		tag = strings.TrimSpace(tag)
+		if tag == "" || utf8.RuneCountInString(tag) > maxTagLen {
+			return nil, ErrInvalidTag
+		}
+		if _, ok := seen[tag]; ok {
+			continue
+		}
+		seen[tag] = struct{}{}
+		res = append(res, tag)
+	}
+	if len(res) > maxTagCnt {
+		return nil, ErrTooManyTags
+	}
+	return res, nil
+}
+
+func (s *articleService) Delete(ctx context.Context, uid int64, id int64) error {
+	return s.repo.Delete(ctx, uid, id)
+}
+
+func (s *articleService) Recover(ctx context.Context, uid int64, id int64) error {
+	return s.repo.Recover(ctx, uid, id, time.Now().Add(-s.retention))
+}
+
+func (s *articleService) ListDeleted(ctx context.Context, uid int64, offset int, limit int) ([]domain.Article, error) {
+	return s.repo.ListDeleted(ctx, uid, time.Now().Add(-s.retention), offset, limit)
+}
+
 func (s *articleService) GetById(ctx context.Context, id int64) (domain.Article, error) {
 	return s.repo.GetById(ctx, id)
 }
 
-func NewArticleService(repo repository.ArticleRepository, log logger.Logger) ArticleService {
-	return &articleService{repo: repo, log: log}
+func NewArticleService(repo repository.ArticleRepository, feedSvc FeedService, log logger.Logger) ArticleService {
+	return &articleService{
+		repo:      repo,
+		feedSvc:   feedSvc,
+		log:       log,
+		retention: time.Hour * 24 * 30,
+	}
 }
 
 func (s *articleService) List(ctx context.Context, uid int64, offset int, limit int) ([]domain.Article, error) {
@@ -47,16 +240,45 @@ func (s *articleService) Withdraw(ctx context.Context, art domain.Article) (int6
 	return s.repo.SyncStatus(ctx, art.Author.Id, art.Id, domain.ArticleStatusPrivate)
 }
 
+func (s *articleService) ForceWithdraw(ctx context.Context, id int64) error {
+	return s.repo.ForceWithdraw(ctx, id)
+}
+
 func (s *articleService) Publish(ctx context.Context, art domain.Article) (int64, error) {
+	var err error
+	if art.Tags, err = s.normalizeTags(art.Tags); err != nil {
+		return 0, err
+	}
 	art.Status = domain.ArticleStatusPublished
-	return s.repo.Sync(ctx, art)
+	id, err := s.repo.Sync(ctx, art)
+	if err == nil {
+		art.Id = id
+		s.publishFeed(art)
+	}
+	return id, err
+}
+
+// publishFeed 推送给粉丝可能很慢，所以异步执行，失败了也不影响发表
+func (s *articleService) publishFeed(art domain.Article) {
+	go func() {
+		ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+		defer cancel()
+		if err := s.feedSvc.OnArticlePublished(ctx, art); err != nil {
+			s.log.Error("推送时间线失败",
+				logger.Int64("aid", art.Id), logger.Error(err))
+		}
+	}()
 }
 
 func (s *articleService) Save(ctx context.Context, art domain.Article) (int64, error) {
+	var err error
+	if art.Tags, err = s.normalizeTags(art.Tags); err != nil {
+		return 0, err
+	}
 	art.Status = domain.ArticleStatusUnpublished
 	if art.Id == 0 {
 		return s.repo.Create(ctx, art)
 	}
-	err := s.repo.Update(ctx, art)
+	err = s.repo.Update(ctx, art)
 	return art.Id, err
 }
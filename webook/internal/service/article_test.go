@@ -0,0 +1,219 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"gitee.com/geekbang/basic-go/webook/internal/domain"
+	"gitee.com/geekbang/basic-go/webook/internal/repository"
+	mock_repository "gitee.com/geekbang/basic-go/webook/internal/repository/mocks"
+	mock_service "gitee.com/geekbang/basic-go/webook/internal/service/mocks"
+	"gitee.com/geekbang/basic-go/webook/pkg/logger"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestArticleService_normalizeTags(t *testing.T) {
+	testCases := []struct {
+		name string
+		tags []string
+
+		wantTags []string
+		wantErr  error
+	}{
+		{
+			name: "不修改标签",
+		},
+		{
+			name:     "清空标签",
+			tags:     []string{},
+			wantTags: []string{},
+		},
+		{
+			name:     "去掉空格和重复的",
+			tags:     []string{" Go ", "Go", "后端"},
+			wantTags: []string{"Go", "后端"},
+		},
+		{
+			name:    "空标签",
+			tags:    []string{"Go", "  "},
+			wantErr: ErrInvalidTag,
+		},
+		{
+			name:    "标签太长",
+			tags:    []string{strings.Repeat("长", maxTagLen+1)},
+			wantErr: ErrInvalidTag,
+		},
+		{
+			name:    "标签太多",
+			tags:    []string{"a", "b", "c", "d", "e", "f"},
+			wantErr: ErrTooManyTags,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			svc := &articleService{}
+			tags, err := svc.normalizeTags(tc.tags)
+			assert.Equal(t, tc.wantErr, err)
+			assert.Equal(t, tc.wantTags, tags)
+		})
+	}
+}
+
+func TestArticleService_PublishScheduled(t *testing.T) {
+	now := time.Now()
+	testCases := []struct {
+		name string
+		mock func(ctrl *gomock.Controller, published chan<- int64) (repository.ArticleRepository, FeedService)
+
+		wantCnt       int
+		wantPublished []int64
+	}{
+		{
+			name: "两个实例抢同一篇，只有抢到的发表",
+			mock: func(ctrl *gomock.Controller, published chan<- int64) (repository.ArticleRepository, FeedService) {
+				repo := mock_repository.NewMockArticleRepository(ctrl)
+				feedSvc := mock_service.NewMockFeedService(ctrl)
+				repo.EXPECT().ListScheduled(gomock.Any(), now, 100).
+					Return([]domain.Article{{Id: 1}, {Id: 2}}, nil)
+				// 1 已经被别的实例改成已发表了
+				repo.EXPECT().TransitStatus(gomock.Any(), domain.Article{Id: 1},
+					domain.ArticleStatusScheduled, domain.ArticleStatusPublished).Return(false, nil)
+				repo.EXPECT().TransitStatus(gomock.Any(), domain.Article{Id: 2},
+					domain.ArticleStatusScheduled, domain.ArticleStatusPublished).Return(true, nil)
+				repo.EXPECT().Sync(gomock.Any(), domain.Article{Id: 2, Status: domain.ArticleStatusPublished}).
+					Return(int64(2), nil)
+				feedSvc.EXPECT().OnArticlePublished(gomock.Any(), gomock.Any()).
+					DoAndReturn(func(ctx context.Context, art domain.Article) error {
+						published <- art.Id
+						return nil
+					})
+				return repo, feedSvc
+			},
+			wantCnt:       1,
+			wantPublished: []int64{2},
+		},
+		{
+			name: "发表失败，改回定时状态",
+			mock: func(ctrl *gomock.Controller, published chan<- int64) (repository.ArticleRepository, FeedService) {
+				repo := mock_repository.NewMockArticleRepository(ctrl)
+				feedSvc := mock_service.NewMockFeedService(ctrl)
+				repo.EXPECT().ListScheduled(gomock.Any(), now, 100).
+					Return([]domain.Article{{Id: 1}}, nil)
+				gomock.InOrder(
+					repo.EXPECT().TransitStatus(gomock.Any(), domain.Article{Id: 1},
+						domain.ArticleStatusScheduled, domain.ArticleStatusPublished).Return(true, nil),
+					repo.EXPECT().Sync(gomock.Any(), gomock.Any()).Return(int64(0), errors.New("mock db error")),
+					repo.EXPECT().TransitStatus(gomock.Any(), domain.Article{Id: 1, Status: domain.ArticleStatusPublished},
+						domain.ArticleStatusPublished, domain.ArticleStatusScheduled).Return(true, nil),
+				)
+				return repo, feedSvc
+			},
+			wantCnt: 0,
+		},
+		{
+			name: "没到时间的不会查出来",
+			mock: func(ctrl *gomock.Controller, published chan<- int64) (repository.ArticleRepository, FeedService) {
+				repo := mock_repository.NewMockArticleRepository(ctrl)
+				feedSvc := mock_service.NewMockFeedService(ctrl)
+				// 以调用的时间为准，发表时间在这之后的由 DAO 过滤掉
+				repo.EXPECT().ListScheduled(gomock.Any(), now, 100).Return([]domain.Article{}, nil)
+				return repo, feedSvc
+			},
+			wantCnt: 0,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+			published := make(chan int64, 10)
+			repo, feedSvc := tc.mock(ctrl, published)
+			svc := NewArticleService(repo, feedSvc, &logger.NopLogger{})
+			cnt, err := svc.PublishScheduled(context.Background(), now, 100)
+			require.NoError(t, err)
+			assert.Equal(t, tc.wantCnt, cnt)
+			// 推送时间线是异步的
+			for _, id := range tc.wantPublished {
+				select {
+				case got := <-published:
+					assert.Equal(t, id, got)
+				case <-time.After(time.Second):
+					t.Fatal("没有推送时间线")
+				}
+			}
+		})
+	}
+}
+
+func TestArticleService_Restore(t *testing.T) {
+	testCases := []struct {
+		name string
+		mock func(ctrl *gomock.Controller) repository.ArticleRepository
+
+		wantErr error
+	}{
+		{
+			name: "恢复成功，按照保存的逻辑写一个新的版本",
+			mock: func(ctrl *gomock.Controller) repository.ArticleRepository {
+				repo := mock_repository.NewMockArticleRepository(ctrl)
+				repo.EXPECT().GetRevision(gomock.Any(), int64(10)).
+					Return(domain.ArticleRevision{Id: 10, ArticleId: 1, AuthorId: 123,
+						Title: "旧的标题", Content: "旧的内容"}, nil)
+				repo.EXPECT().Update(gomock.Any(), domain.Article{
+					Id:      1,
+					Title:   "旧的标题",
+					Content: "旧的内容",
+					Author:  domain.Author{Id: 123},
+					Status:  domain.ArticleStatusUnpublished,
+				}).Return(nil)
+				return repo
+			},
+		},
+		{
+			name: "不是自己的版本",
+			mock: func(ctrl *gomock.Controller) repository.ArticleRepository {
+				repo := mock_repository.NewMockArticleRepository(ctrl)
+				repo.EXPECT().GetRevision(gomock.Any(), int64(10)).
+					Return(domain.ArticleRevision{Id: 10, ArticleId: 1, AuthorId: 234}, nil)
+				return repo
+			},
+			wantErr: ErrPossibleIncorrectAuthor,
+		},
+		{
+			name: "版本不属于这篇文章",
+			mock: func(ctrl *gomock.Controller) repository.ArticleRepository {
+				repo := mock_repository.NewMockArticleRepository(ctrl)
+				repo.EXPECT().GetRevision(gomock.Any(), int64(10)).
+					Return(domain.ArticleRevision{Id: 10, ArticleId: 2, AuthorId: 123}, nil)
+				return repo
+			},
+			wantErr: ErrPossibleIncorrectAuthor,
+		},
+		{
+			name: "版本不存在",
+			mock: func(ctrl *gomock.Controller) repository.ArticleRepository {
+				repo := mock_repository.NewMockArticleRepository(ctrl)
+				repo.EXPECT().GetRevision(gomock.Any(), int64(10)).
+					Return(domain.ArticleRevision{}, repository.ErrArticleNotFound)
+				return repo
+			},
+			wantErr: repository.ErrArticleNotFound,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+			svc := NewArticleService(tc.mock(ctrl), nil, &logger.NopLogger{})
+			err := svc.Restore(context.Background(), 123, 1, 10)
+			assert.Equal(t, tc.wantErr, err)
+		})
+	}
+}
@@ -1,8 +1,10 @@
 package service
 
 import (
+	"errors"
 	"fmt"
 	"gitee.com/geekbang/basic-go/webook/internal/repository"
+	"gitee.com/geekbang/basic-go/webook/internal/service/email"
 	"gitee.com/geekbang/basic-go/webook/internal/service/sms"
 	"golang.org/x/net/context"
 	"math/rand"
@@ -10,36 +12,59 @@ import (
 
 const codeTplId = "1877556"
 
+// 验证码的发送渠道，也是缓存 key 的前缀，所以发送和验证的频率限制是按照渠道分开算的
+const (
+	CodeChannelSMS   = "phone"
+	CodeChannelEmail = "email"
+)
+
 var (
 	ErrCodeVerifyTooManyTimes = repository.ErrCodeVerifyTooManyTimes
 	ErrCodeSendTooMany        = repository.ErrCodeSendTooMany
+	ErrUnknownCodeChannel     = errors.New("未知的验证码渠道")
 )
 
 //go:generate mockgen -source=$GOFILE -destination=mocks/mock_$GOFILE --package=$GOPACKAGEmocks
 type CodeService interface {
-	Send(ctx context.Context, biz string, phone string) error
-	Verify(ctx context.Context, biz string, phone string, inputCode string) (bool, error)
+	// Send target 是手机号或者邮箱，取决于 channel
+	Send(ctx context.Context, biz string, channel string, target string) error
+	Verify(ctx context.Context, biz string, channel string, target string, inputCode string) (bool, error)
+}
+
+// CodeSender 把验证码发到某个渠道
+type CodeSender interface {
+	Send(ctx context.Context, target string, code string) error
 }
 
 type CodeServiceImpl struct {
-	smsSvc sms.Service
-	repo   repository.CodeRepo
+	senders map[string]CodeSender
+	repo    repository.CodeRepo
 }
 
-func NewCodeServiceImpl(smsSvc sms.Service, repo repository.CodeRepo) CodeService {
-	return &CodeServiceImpl{smsSvc: smsSvc, repo: repo}
+func NewCodeServiceImpl(smsSvc sms.Service, emailSvc email.Service, repo repository.CodeRepo) CodeService {
+	return &CodeServiceImpl{
+		senders: map[string]CodeSender{
+			CodeChannelSMS:   NewSMSCodeSender(smsSvc, codeTplId),
+			CodeChannelEmail: NewEmailCodeSender(emailSvc),
+		},
+		repo: repo,
+	}
 }
 
-func (c *CodeServiceImpl) Send(ctx context.Context, biz string, phone string) error {
+func (c *CodeServiceImpl) Send(ctx context.Context, biz string, channel string, target string) error {
+	sender, ok := c.senders[channel]
+	if !ok {
+		return ErrUnknownCodeChannel
+	}
 
 	// 生成验证码
 	code := c.generateCode()
-	err := c.repo.Set(ctx, biz, phone, code)
+	err := c.repo.Set(ctx, channel, biz, target, code)
 	if err != nil {
 		return err
 	}
 
-	err = c.smsSvc.Send(ctx, codeTplId, []string{code}, phone)
+	err = sender.Send(ctx, target, code)
 
 	//if err != nil {
 	// 这个地方怎么办？
@@ -53,11 +78,43 @@ func (c *CodeServiceImpl) Send(ctx context.Context, biz string, phone string) er
 
 }
 
-func (c *CodeServiceImpl) Verify(ctx context.Context, biz string, phone string, inputCode string) (bool, error) {
-	return c.repo.Verify(ctx, biz, phone, inputCode)
+func (c *CodeServiceImpl) Verify(ctx context.Context, biz string, channel string, target string, inputCode string) (bool, error) {
+	if _, ok := c.senders[channel]; !ok {
+		return false, ErrUnknownCodeChannel
+	}
+	return c.repo.Verify(ctx, channel, biz, target, inputCode)
 }
 
 func (c *CodeServiceImpl) generateCode() string {
 	// 6位随机数， 不够0的 加上前导0
 	return fmt.Sprintf("%06d", rand.Intn(1000000))
 }
+
+type SMSCodeSender struct {
+	svc   sms.Service
+	tplId string
+}
+
+func NewSMSCodeSender(svc sms.Service, tplId string) *SMSCodeSender {
+	return &SMSCodeSender{
+		svc:   svc,
+		tplId: tplId,
+	}
+}
+
+func (s *SMSCodeSender) Send(ctx context.Context, phone string, code string) error {
+	return s.svc.Send(ctx, s.tplId, []string{code}, phone)
+}
+
+type EmailCodeSender struct {
+	svc email.Service
+}
+
+func NewEmailCodeSender(svc email.Service) *EmailCodeSender {
+	return &EmailCodeSender{svc: svc}
+}
+
+func (s *EmailCodeSender) Send(ctx context.Context, addr string, code string) error {
+	return s.svc.Send(ctx, "webook 验证码",
+		fmt.Sprintf("你的验证码是 %s，10 分钟内有效。如果不是你本人操作，请忽略这封邮件。", code), addr)
+}
@@ -0,0 +1,85 @@
+package service
+
+import (
+	"context"
+	"gitee.com/geekbang/basic-go/webook/internal/repository"
+	mock_repository "gitee.com/geekbang/basic-go/webook/internal/repository/mocks"
+	emailmemory "gitee.com/geekbang/basic-go/webook/internal/service/email/memory"
+	smsmemory "gitee.com/geekbang/basic-go/webook/internal/service/sms/memory"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
+	"testing"
+)
+
+func TestCodeServiceImpl_Send(t *testing.T) {
+	testCases := []struct {
+		name    string
+		mock    func(ctrl *gomock.Controller) repository.CodeRepo
+		channel string
+		target  string
+
+		wantErr   error
+		wantEmail int
+	}{
+		{
+			name: "邮件发送成功",
+			mock: func(ctrl *gomock.Controller) repository.CodeRepo {
+				repo := mock_repository.NewMockCodeRepo(ctrl)
+				repo.EXPECT().Set(gomock.Any(), CodeChannelEmail, "signup", "123@qq.com", gomock.Any()).
+					Return(nil)
+				return repo
+			},
+			channel:   CodeChannelEmail,
+			target:    "123@qq.com",
+			wantEmail: 1,
+		},
+		{
+			name: "邮件发送太频繁",
+			mock: func(ctrl *gomock.Controller) repository.CodeRepo {
+				repo := mock_repository.NewMockCodeRepo(ctrl)
+				repo.EXPECT().Set(gomock.Any(), CodeChannelEmail, "signup", "123@qq.com", gomock.Any()).
+					Return(repository.ErrCodeSendTooMany)
+				return repo
+			},
+			channel: CodeChannelEmail,
+			target:  "123@qq.com",
+			wantErr: ErrCodeSendTooMany,
+		},
+		{
+			name: "短信不会发邮件",
+			mock: func(ctrl *gomock.Controller) repository.CodeRepo {
+				repo := mock_repository.NewMockCodeRepo(ctrl)
+				repo.EXPECT().Set(gomock.Any(), CodeChannelSMS, "signup", "15212345678", gomock.Any()).
+					Return(nil)
+				return repo
+			},
+			channel: CodeChannelSMS,
+			target:  "15212345678",
+		},
+		{
+			name: "未知渠道",
+			mock: func(ctrl *gomock.Controller) repository.CodeRepo {
+				return mock_repository.NewMockCodeRepo(ctrl)
+			},
+			channel: "wechat",
+			target:  "abc",
+			wantErr: ErrUnknownCodeChannel,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+			emailSvc := emailmemory.NewService()
+			svc := NewCodeServiceImpl(smsmemory.NewService(), emailSvc, tc.mock(ctrl))
+			err := svc.Send(context.Background(), "signup", tc.channel, tc.target)
+			assert.Equal(t, tc.wantErr, err)
+			msgs := emailSvc.Messages()
+			assert.Equal(t, tc.wantEmail, len(msgs))
+			for _, msg := range msgs {
+				assert.Equal(t, []string{tc.target}, msg.To)
+			}
+		})
+	}
+}
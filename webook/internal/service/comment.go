@@ -0,0 +1,122 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"gitee.com/geekbang/basic-go/webook/internal/domain"
+	"gitee.com/geekbang/basic-go/webook/internal/repository"
+	"strings"
+	"unicode/utf8"
+)
+
+var (
+	ErrCommentNotFound       = repository.ErrCommentNotFound
+	ErrInvalidComment        = errors.New("评论内容不合法")
+	ErrCommentTargetNotFound = errors.New("评论的对象不存在")
+	ErrNoCommentPermission   = errors.New("没有权限删除评论")
+)
+
+// 评论最多多少个字
+const maxCommentLen = 1000
+
+//go:generate mockgen -source=$GOFILE -destination=mocks/mock_$GOFILE --package=$GOPACKAGEmocks
+type CommentService interface {
+	// CreateComment ParentId 不为 0 的时候是回复，RootId 会根据 ParentId 计算，不需要传
+	CreateComment(ctx context.Context, c domain.Comment) (int64, error)
+	// ListRoots 根评论，按照时间倒序，maxId 是上一页最后一条评论的 ID
+	ListRoots(ctx context.Context, biz string, bizId int64, maxId int64, limit int) ([]domain.Comment, error)
+	// ListReplies 根评论下面的回复，按照时间正序，minId 是上一页最后一条回复的 ID
+	ListReplies(ctx context.Context, rootId int64, minId int64, limit int) ([]domain.Comment, error)
+	// DeleteComment 评论人自己或者文章作者才可以删除
+	DeleteComment(ctx context.Context, uid int64, id int64) error
+	Count(ctx context.Context, biz string, bizId int64) (int64, error)
+}
+
+type commentService struct {
+	repo   repository.CommentRepository
+	artSvc ArticleService
+}
+
+func NewCommentService(repo repository.CommentRepository, artSvc ArticleService) CommentService {
+	return &commentService{
+		repo:   repo,
+		artSvc: artSvc,
+	}
+}
+
+func (s *commentService) CreateComment(ctx context.Context, c domain.Comment) (int64, error) {
+	c.Content = strings.TrimSpace(c.Content)
+	if c.Content == "" || utf8.RuneCountInString(c.Content) > maxCommentLen {
+		return 0, ErrInvalidComment
+	}
+	if _, err := s.bizOwner(ctx, c.Biz, c.BizId); err != nil {
+		return 0, err
+	}
+	c.RootId = 0
+	if c.ParentId > 0 {
+		parent, err := s.repo.FindById(ctx, c.ParentId)
+		if err == ErrCommentNotFound {
+			return 0, ErrCommentTargetNotFound
+		}
+		if err != nil {
+			return 0, err
+		}
+		if parent.Biz != c.Biz || parent.BizId != c.BizId {
+			return 0, ErrCommentTargetNotFound
+		}
+		// 回复的回复，也放在同一个根评论下面
+		c.RootId = parent.RootId
+		if c.RootId == 0 {
+			c.RootId = parent.Id
+		}
+	}
+	return s.repo.CreateComment(ctx, c)
+}
+
+func (s *commentService) ListRoots(ctx context.Context, biz string, bizId int64, maxId int64, limit int) ([]domain.Comment, error) {
+	return s.repo.ListRoots(ctx, biz, bizId, maxId, limit)
+}
+
+func (s *commentService) ListReplies(ctx context.Context, rootId int64, minId int64, limit int) ([]domain.Comment, error) {
+	return s.repo.ListReplies(ctx, rootId, minId, limit)
+}
+
+func (s *commentService) DeleteComment(ctx context.Context, uid int64, id int64) error {
+	c, err := s.repo.FindById(ctx, id)
+	if err != nil {
+		return err
+	}
+	if c.Commentator.Id != uid {
+		owner, err := s.bizOwner(ctx, c.Biz, c.BizId)
+		if err == ErrCommentTargetNotFound || (err == nil && owner != uid) {
+			return ErrNoCommentPermission
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return s.repo.DeleteComment(ctx, c)
+}
+
+func (s *commentService) Count(ctx context.Context, biz string, bizId int64) (int64, error) {
+	return s.repo.Count(ctx, biz, bizId)
+}
+
+// bizOwner 被评论的资源的所有者，资源不存在或者不能评论的时候返回 ErrCommentTargetNotFound
+// 目前只有文章可以评论
+func (s *commentService) bizOwner(ctx context.Context, biz string, bizId int64) (int64, error) {
+	if biz != "article" {
+		return 0, ErrCommentTargetNotFound
+	}
+	art, err := s.artSvc.GetPubById(ctx, bizId)
+	if err == ErrArticleNotFound {
+		return 0, ErrCommentTargetNotFound
+	}
+	if err != nil {
+		return 0, err
+	}
+	if art.Status != domain.ArticleStatusPublished {
+		return 0, ErrCommentTargetNotFound
+	}
+	return art.Author.Id, nil
+}
@@ -0,0 +1,94 @@
+package service
+
+import (
+	"context"
+	"gitee.com/geekbang/basic-go/webook/internal/domain"
+	mock_repository "gitee.com/geekbang/basic-go/webook/internal/repository/mocks"
+	mock_service "gitee.com/geekbang/basic-go/webook/internal/service/mocks"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
+	"testing"
+)
+
+func TestCommentService_DeleteComment(t *testing.T) {
+	cmt := domain.Comment{
+		Id:          10,
+		Biz:         "article",
+		BizId:       1,
+		Commentator: domain.Author{Id: 100},
+	}
+	testCases := []struct {
+		name string
+		mock func(ctrl *gomock.Controller) (*mock_repository.MockCommentRepository, *mock_service.MockArticleService)
+		uid  int64
+
+		wantErr error
+	}{
+		{
+			name: "评论人自己删除",
+			mock: func(ctrl *gomock.Controller) (*mock_repository.MockCommentRepository, *mock_service.MockArticleService) {
+				repo := mock_repository.NewMockCommentRepository(ctrl)
+				artSvc := mock_service.NewMockArticleService(ctrl)
+				repo.EXPECT().FindById(gomock.Any(), int64(10)).Return(cmt, nil)
+				repo.EXPECT().DeleteComment(gomock.Any(), cmt).Return(nil)
+				return repo, artSvc
+			},
+			uid: 100,
+		},
+		{
+			name: "文章作者删除",
+			mock: func(ctrl *gomock.Controller) (*mock_repository.MockCommentRepository, *mock_service.MockArticleService) {
+				repo := mock_repository.NewMockCommentRepository(ctrl)
+				artSvc := mock_service.NewMockArticleService(ctrl)
+				repo.EXPECT().FindById(gomock.Any(), int64(10)).Return(cmt, nil)
+				artSvc.EXPECT().GetPubById(gomock.Any(), int64(1)).Return(domain.Article{
+					Id:     1,
+					Status: domain.ArticleStatusPublished,
+					Author: domain.Author{Id: 200},
+				}, nil)
+				repo.EXPECT().DeleteComment(gomock.Any(), cmt).Return(nil)
+				return repo, artSvc
+			},
+			uid: 200,
+		},
+		{
+			name: "别人不能删除",
+			mock: func(ctrl *gomock.Controller) (*mock_repository.MockCommentRepository, *mock_service.MockArticleService) {
+				repo := mock_repository.NewMockCommentRepository(ctrl)
+				artSvc := mock_service.NewMockArticleService(ctrl)
+				repo.EXPECT().FindById(gomock.Any(), int64(10)).Return(cmt, nil)
+				artSvc.EXPECT().GetPubById(gomock.Any(), int64(1)).Return(domain.Article{
+					Id:     1,
+					Status: domain.ArticleStatusPublished,
+					Author: domain.Author{Id: 200},
+				}, nil)
+				return repo, artSvc
+			},
+			uid:     300,
+			wantErr: ErrNoCommentPermission,
+		},
+		{
+			name: "评论不存在",
+			mock: func(ctrl *gomock.Controller) (*mock_repository.MockCommentRepository, *mock_service.MockArticleService) {
+				repo := mock_repository.NewMockCommentRepository(ctrl)
+				artSvc := mock_service.NewMockArticleService(ctrl)
+				repo.EXPECT().FindById(gomock.Any(), int64(10)).
+					Return(domain.Comment{}, ErrCommentNotFound)
+				return repo, artSvc
+			},
+			uid:     100,
+			wantErr: ErrCommentNotFound,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+			repo, artSvc := tc.mock(ctrl)
+			svc := NewCommentService(repo, artSvc)
+			err := svc.DeleteComment(context.Background(), tc.uid, 10)
+			assert.Equal(t, tc.wantErr, err)
+		})
+	}
+}
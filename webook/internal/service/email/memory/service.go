@@ -0,0 +1,42 @@
+package memory
+
+import (
+	"context"
+	"sync"
+)
+
+type Message struct {
+	Subject string
+	Content string
+	To      []string
+}
+
+// Service 只把邮件记在内存里面，测试和本地开发用
+type Service struct {
+	lock     sync.Mutex
+	messages []Message
+}
+
+func NewService() *Service {
+	return &Service{}
+}
+
+func (s *Service) Send(ctx context.Context, subject string, content string, to ...string) error {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	s.messages = append(s.messages, Message{
+		Subject: subject,
+		Content: content,
+		To:      to,
+	})
+	return nil
+}
+
+// Messages 已经发送的邮件
+func (s *Service) Messages() []Message {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	res := make([]Message, len(s.messages))
+	copy(res, s.messages)
+	return res
+}
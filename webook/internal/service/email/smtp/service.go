@@ -0,0 +1,46 @@
+package smtp
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"mime"
+	"net/smtp"
+	"strings"
+)
+
+type Service struct {
+	addr string
+	from string
+	auth smtp.Auth
+}
+
+// NewService addr 是 host:port，auth 为 nil 表示不需要认证
+func NewService(addr string, from string, auth smtp.Auth) *Service {
+	return &Service{
+		addr: addr,
+		from: from,
+		auth: auth,
+	}
+}
+
+func (s *Service) Send(ctx context.Context, subject string, content string, to ...string) error {
+	// net/smtp 不支持 context，所以只能在发送之前检查一下
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	return smtp.SendMail(s.addr, s.auth, s.from, to, s.message(subject, content, to))
+}
+
+func (s *Service) message(subject string, content string, to []string) []byte {
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, "From: %s\r\n", s.from)
+	fmt.Fprintf(&buf, "To: %s\r\n", strings.Join(to, ", "))
+	// 标题里面有中文，要编码
+	fmt.Fprintf(&buf, "Subject: %s\r\n", mime.BEncoding.Encode("UTF-8", subject))
+	buf.WriteString("MIME-Version: 1.0\r\n")
+	buf.WriteString("Content-Type: text/plain; charset=UTF-8\r\n")
+	buf.WriteString("\r\n")
+	buf.WriteString(content)
+	return buf.Bytes()
+}
@@ -0,0 +1,234 @@
+package service
+
+import (
+	"context"
+	"gitee.com/geekbang/basic-go/webook/internal/domain"
+	"gitee.com/geekbang/basic-go/webook/internal/repository"
+	"gitee.com/geekbang/basic-go/webook/pkg/logger"
+	"sort"
+	"time"
+)
+
+//go:generate mockgen -source=$GOFILE -destination=mocks/mock_$GOFILE --package=$GOPACKAGEmocks
+type FeedService interface {
+	// OnArticlePublished 文章发表之后调用，粉丝少的作者推到粉丝的收件箱，
+	// 粉丝多的作者只写自己的发件箱，等粉丝读的时候再拉
+	OnArticlePublished(ctx context.Context, art domain.Article) error
+	// GetFeed uid 关注的人发表的文章，按照发表时间倒序，ctime 和 artId 是上一页最后一条的。
+	// 文章已经撤回或者删除的，Article 是零值，但是依旧返回，方便计算下一页的游标
+	// 已经取消关注的作者的文章不会返回
+	GetFeed(ctx context.Context, uid int64, ctime time.Time, artId int64, limit int) ([]domain.FeedEvent, error)
+}
+
+type feedService struct {
+	repo      repository.FeedRepository
+	artRepo   repository.ArticleRepository
+	followSvc FollowService
+	log       logger.Logger
+	// 粉丝数小于这个值的作者用推模型
+	threshold int64
+	// 读的时候最多看多少个关注的人，避免关注太多的人拖垮查询
+	maxFollowees int
+	batchSize    int
+}
+
+func NewFeedService(repo repository.FeedRepository, artRepo repository.ArticleRepository,
+	followSvc FollowService, l logger.Logger) FeedService {
+	return &feedService{
+		repo:         repo,
+		artRepo:      artRepo,
+		followSvc:    followSvc,
+		log:          l,
+		threshold:    1000,
+		maxFollowees: 1000,
+		batchSize:    100,
+	}
+}
+
+func (s *feedService) OnArticlePublished(ctx context.Context, art domain.Article) error {
+	evt := domain.FeedEvent{
+		ArticleId: art.Id,
+		AuthorId:  art.Author.Id,
+		Ctime:     time.Now(),
+	}
+	// 发件箱总是写，作者粉丝数变多之后，粉丝可以从这里拉到以前的文章
+	if err := s.repo.CreatePullEvent(ctx, evt); err != nil {
+		return err
+	}
+	statics, err := s.followSvc.GetStatics(ctx, evt.AuthorId)
+	if err != nil {
+		return err
+	}
+	if statics.Followers >= s.threshold {
+		return nil
+	}
+	var (
+		utime time.Time
+		id    int64
+	)
+	for {
+		followers, err := s.followSvc.ListFollowers(ctx, evt.AuthorId, utime, id, s.batchSize)
+		if err != nil {
+			return err
+		}
+		if len(followers) == 0 {
+			return nil
+		}
+		uids := make([]int64, 0, len(followers))
+		for _, f := range followers {
+			uids = append(uids, f.Follower)
+		}
+		if err = s.repo.CreatePushEvents(ctx, uids, evt); err != nil {
+			return err
+		}
+		if len(followers) < s.batchSize {
+			return nil
+		}
+		last := followers[len(followers)-1]
+		utime, id = last.Utime, last.Id
+	}
+}
+
+func (s *feedService) GetFeed(ctx context.Context, uid int64, ctime time.Time, artId int64, limit int) ([]domain.FeedEvent, error) {
+	followees, err := s.followees(ctx, uid)
+	if err != nil {
+		return nil, err
+	}
+	pushed, err := s.followedPushEvents(ctx, uid, followees, ctime, artId, limit)
+	if err != nil {
+		return nil, err
+	}
+	pulled, err := s.repo.FindPullEvents(ctx, followees.big, ctime, artId, limit)
+	if err != nil {
+		return nil, err
+	}
+	// 作者粉丝数跨过阈值前后的文章，可能收件箱和发件箱里面都有
+	seen := make(map[int64]struct{}, len(pushed)+len(pulled))
+	events := make([]domain.FeedEvent, 0, len(pushed)+len(pulled))
+	for _, evt := range append(pushed, pulled...) {
+		if _, ok := seen[evt.ArticleId]; ok {
+			continue
+		}
+		seen[evt.ArticleId] = struct{}{}
+		events = append(events, evt)
+	}
+	sort.Slice(events, func(i, j int) bool {
+		if events[i].Ctime.Equal(events[j].Ctime) {
+			return events[i].ArticleId > events[j].ArticleId
+		}
+		return events[i].Ctime.After(events[j].Ctime)
+	})
+	if len(events) > limit {
+		events = events[:limit]
+	}
+	for i := range events {
+		art, err := s.artRepo.GetPubById(ctx, events[i].ArticleId)
+		if err != nil {
+			if err != repository.ErrArticleNotFound {
+				s.log.Error("时间线查询文章失败",
+					logger.Int64("aid", events[i].ArticleId), logger.Error(err))
+			}
+			continue
+		}
+		if art.Status != domain.ArticleStatusPublished {
+			continue
+		}
+		events[i].Article = art
+	}
+	return events, nil
+}
+
+// followedPushEvents 收件箱里面的事件。取消关注的时候不会清理收件箱，
+// 所以要过滤掉已经不再关注的作者，过滤之后不够 limit 条就接着往下翻
+func (s *feedService) followedPushEvents(ctx context.Context, uid int64, followees followeeSet,
+	ctime time.Time, artId int64, limit int) ([]domain.FeedEvent, error) {
+	res := make([]domain.FeedEvent, 0, limit)
+	for len(res) < limit {
+		events, err := s.repo.FindPushEvents(ctx, uid, ctime, artId, limit)
+		if err != nil {
+			return nil, err
+		}
+		for _, evt := range events {
+			ok, err := followees.contains(ctx, s.followSvc, uid, evt.AuthorId)
+			if err != nil {
+				return nil, err
+			}
+			if ok {
+				res = append(res, evt)
+			}
+		}
+		if len(events) < limit {
+			break
+		}
+		last := events[len(events)-1]
+		ctime, artId = last.Ctime, last.ArticleId
+	}
+	if len(res) > limit {
+		res = res[:limit]
+	}
+	return res, nil
+}
+
+// followeeSet uid 目前关注的人
+type followeeSet struct {
+	all map[int64]bool
+	// 用拉模型的那些
+	big []int64
+	// 关注的人超过了 maxFollowees，all 不完整
+	truncated bool
+}
+
+// contains uid 是否关注了 author，all 里面没有又不完整的时候，查一次并且记下来
+func (f followeeSet) contains(ctx context.Context, followSvc FollowService, uid int64, author int64) (bool, error) {
+	ok, found := f.all[author]
+	if found || !f.truncated {
+		return ok, nil
+	}
+	ok, err := followSvc.IsFollowing(ctx, uid, author)
+	if err != nil {
+		return false, err
+	}
+	f.all[author] = ok
+	return ok, nil
+}
+
+func (s *feedService) followees(ctx context.Context, uid int64) (followeeSet, error) {
+	res := followeeSet{all: make(map[int64]bool)}
+	var (
+		utime time.Time
+		id    int64
+	)
+	for cnt := 0; ; cnt += s.batchSize {
+		if cnt >= s.maxFollowees {
+			res.truncated = true
+			break
+		}
+		followees, err := s.followSvc.ListFollowees(ctx, uid, utime, id, s.batchSize)
+		if err != nil {
+			return followeeSet{}, err
+		}
+		if len(followees) == 0 {
+			break
+		}
+		uids := make([]int64, 0, len(followees))
+		for _, f := range followees {
+			uids = append(uids, f.Followee)
+			res.all[f.Followee] = true
+		}
+		statics, err := s.followSvc.GetStaticsByIds(ctx, uids)
+		if err != nil {
+			return followeeSet{}, err
+		}
+		for _, followee := range uids {
+			if statics[followee].Followers >= s.threshold {
+				res.big = append(res.big, followee)
+			}
+		}
+		if len(followees) < s.batchSize {
+			break
+		}
+		last := followees[len(followees)-1]
+		utime, id = last.Utime, last.Id
+	}
+	return res, nil
+}
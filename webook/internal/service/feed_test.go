@@ -0,0 +1,166 @@
+package service
+
+import (
+	"context"
+	"gitee.com/geekbang/basic-go/webook/internal/domain"
+	mock_repository "gitee.com/geekbang/basic-go/webook/internal/repository/mocks"
+	mock_service "gitee.com/geekbang/basic-go/webook/internal/service/mocks"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+	"testing"
+	"time"
+)
+
+func TestFeedService_OnArticlePublished(t *testing.T) {
+	art := domain.Article{
+		Id:     1,
+		Author: domain.Author{Id: 100},
+	}
+	testCases := []struct {
+		name string
+		mock func(ctrl *gomock.Controller) (*mock_repository.MockFeedRepository, *mock_service.MockFollowService)
+
+		wantErr error
+	}{
+		{
+			name: "粉丝少，推给粉丝",
+			mock: func(ctrl *gomock.Controller) (*mock_repository.MockFeedRepository, *mock_service.MockFollowService) {
+				repo := mock_repository.NewMockFeedRepository(ctrl)
+				followSvc := mock_service.NewMockFollowService(ctrl)
+				repo.EXPECT().CreatePullEvent(gomock.Any(), gomock.Any()).Return(nil)
+				followSvc.EXPECT().GetStatics(gomock.Any(), int64(100)).
+					Return(domain.FollowStatics{Followers: 2}, nil)
+				followSvc.EXPECT().ListFollowers(gomock.Any(), int64(100), gomock.Any(), int64(0), 100).
+					Return([]domain.FollowRelation{
+						{Id: 1, Follower: 200, Followee: 100},
+						{Id: 2, Follower: 300, Followee: 100},
+					}, nil)
+				repo.EXPECT().CreatePushEvents(gomock.Any(), []int64{200, 300}, gomock.Any()).Return(nil)
+				return repo, followSvc
+			},
+		},
+		{
+			name: "粉丝多，只写发件箱",
+			mock: func(ctrl *gomock.Controller) (*mock_repository.MockFeedRepository, *mock_service.MockFollowService) {
+				repo := mock_repository.NewMockFeedRepository(ctrl)
+				followSvc := mock_service.NewMockFollowService(ctrl)
+				repo.EXPECT().CreatePullEvent(gomock.Any(), gomock.Any()).Return(nil)
+				followSvc.EXPECT().GetStatics(gomock.Any(), int64(100)).
+					Return(domain.FollowStatics{Followers: 1000}, nil)
+				return repo, followSvc
+			},
+		},
+		{
+			name: "没有粉丝",
+			mock: func(ctrl *gomock.Controller) (*mock_repository.MockFeedRepository, *mock_service.MockFollowService) {
+				repo := mock_repository.NewMockFeedRepository(ctrl)
+				followSvc := mock_service.NewMockFollowService(ctrl)
+				repo.EXPECT().CreatePullEvent(gomock.Any(), gomock.Any()).Return(nil)
+				followSvc.EXPECT().GetStatics(gomock.Any(), int64(100)).
+					Return(domain.FollowStatics{}, nil)
+				followSvc.EXPECT().ListFollowers(gomock.Any(), int64(100), gomock.Any(), int64(0), 100).
+					Return(nil, nil)
+				return repo, followSvc
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+			repo, followSvc := tc.mock(ctrl)
+			svc := NewFeedService(repo, nil, followSvc, nil)
+			err := svc.OnArticlePublished(context.Background(), art)
+			assert.Equal(t, tc.wantErr, err)
+		})
+	}
+}
+
+func TestFeedService_GetFeed(t *testing.T) {
+	now := time.Now()
+	evt := func(aid int64, author int64, minutes int) domain.FeedEvent {
+		return domain.FeedEvent{ArticleId: aid, AuthorId: author, Ctime: now.Add(-time.Duration(minutes) * time.Minute)}
+	}
+	testCases := []struct {
+		name         string
+		mock         func(ctrl *gomock.Controller) (*mock_repository.MockFeedRepository, *mock_service.MockFollowService)
+		maxFollowees int
+		batchSize    int
+
+		wantIds []int64
+	}{
+		{
+			name: "过滤已经取消关注的作者，不够再往下翻",
+			mock: func(ctrl *gomock.Controller) (*mock_repository.MockFeedRepository, *mock_service.MockFollowService) {
+				repo := mock_repository.NewMockFeedRepository(ctrl)
+				followSvc := mock_service.NewMockFollowService(ctrl)
+				followSvc.EXPECT().ListFollowees(gomock.Any(), int64(1), time.Time{}, int64(0), 100).
+					Return([]domain.FollowRelation{
+						{Id: 1, Follower: 1, Followee: 100},
+						{Id: 2, Follower: 1, Followee: 300},
+					}, nil)
+				// 统计数据一次查完
+				followSvc.EXPECT().GetStaticsByIds(gomock.Any(), []int64{100, 300}).
+					Return(map[int64]domain.FollowStatics{300: {Followers: 1000}}, nil)
+				// 200 已经取消关注了，收件箱里面还有他的文章
+				second := evt(2, 100, 2)
+				repo.EXPECT().FindPushEvents(gomock.Any(), int64(1), time.Time{}, int64(0), 2).
+					Return([]domain.FeedEvent{evt(1, 200, 1), second}, nil)
+				repo.EXPECT().FindPushEvents(gomock.Any(), int64(1), second.Ctime, int64(2), 2).
+					Return([]domain.FeedEvent{evt(3, 100, 3)}, nil)
+				repo.EXPECT().FindPullEvents(gomock.Any(), []int64{300}, time.Time{}, int64(0), 2).
+					Return([]domain.FeedEvent{evt(4, 300, 0)}, nil)
+				return repo, followSvc
+			},
+			maxFollowees: 1000,
+			batchSize:    100,
+			wantIds:      []int64{4, 2},
+		},
+		{
+			name: "关注的人太多，不在列表里面的单独查",
+			mock: func(ctrl *gomock.Controller) (*mock_repository.MockFeedRepository, *mock_service.MockFollowService) {
+				repo := mock_repository.NewMockFeedRepository(ctrl)
+				followSvc := mock_service.NewMockFollowService(ctrl)
+				followSvc.EXPECT().ListFollowees(gomock.Any(), int64(1), time.Time{}, int64(0), 1).
+					Return([]domain.FollowRelation{{Id: 1, Follower: 1, Followee: 100}}, nil)
+				followSvc.EXPECT().GetStaticsByIds(gomock.Any(), []int64{100}).
+					Return(map[int64]domain.FollowStatics{}, nil)
+				repo.EXPECT().FindPushEvents(gomock.Any(), int64(1), time.Time{}, int64(0), 3).
+					Return([]domain.FeedEvent{evt(1, 200, 1), evt(2, 100, 2), evt(3, 200, 3)}, nil)
+				// 同一个作者只查一次
+				followSvc.EXPECT().IsFollowing(gomock.Any(), int64(1), int64(200)).Return(true, nil)
+				repo.EXPECT().FindPullEvents(gomock.Any(), gomock.Len(0), time.Time{}, int64(0), 3).
+					Return(nil, nil)
+				return repo, followSvc
+			},
+			maxFollowees: 1,
+			batchSize:    1,
+			wantIds:      []int64{1, 2, 3},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+			repo, followSvc := tc.mock(ctrl)
+			artRepo := mock_repository.NewMockArticleRepository(ctrl)
+			artRepo.EXPECT().GetPubById(gomock.Any(), gomock.Any()).
+				DoAndReturn(func(ctx context.Context, id int64) (domain.Article, error) {
+					return domain.Article{Id: id, Status: domain.ArticleStatusPublished}, nil
+				}).AnyTimes()
+			svc := NewFeedService(repo, artRepo, followSvc, nil).(*feedService)
+			svc.maxFollowees = tc.maxFollowees
+			svc.batchSize = tc.batchSize
+			events, err := svc.GetFeed(context.Background(), 1, time.Time{}, 0, len(tc.wantIds))
+			require.NoError(t, err)
+			ids := make([]int64, 0, len(events))
+			for _, e := range events {
+				ids = append(ids, e.ArticleId)
+			}
+			assert.Equal(t, tc.wantIds, ids)
+		})
+	}
+}
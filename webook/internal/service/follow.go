@@ -0,0 +1,82 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"gitee.com/geekbang/basic-go/webook/internal/domain"
+	"gitee.com/geekbang/basic-go/webook/internal/repository"
+	"time"
+)
+
+var (
+	ErrFollowSelf       = errors.New("不能关注自己")
+	ErrFolloweeNotFound = errors.New("被关注的用户不存在")
+)
+
+//go:generate mockgen -source=$GOFILE -destination=mocks/mock_$GOFILE --package=$GOPACKAGEmocks
+type FollowService interface {
+	Follow(ctx context.Context, follower int64, followee int64) error
+	CancelFollow(ctx context.Context, follower int64, followee int64) error
+	// IsFollowing a 是否关注了 b
+	IsFollowing(ctx context.Context, a int64, b int64) (bool, error)
+	// ListFollowees uid 关注的人，按照关注时间倒序，utime 和 id 是上一页最后一条的
+	ListFollowees(ctx context.Context, uid int64, utime time.Time, id int64, limit int) ([]domain.FollowRelation, error)
+	// ListFollowers uid 的粉丝
+	ListFollowers(ctx context.Context, uid int64, utime time.Time, id int64, limit int) ([]domain.FollowRelation, error)
+	GetStatics(ctx context.Context, uid int64) (domain.FollowStatics, error)
+	// GetStaticsByIds 批量查询，key 是 uid
+	GetStaticsByIds(ctx context.Context, uids []int64) (map[int64]domain.FollowStatics, error)
+}
+
+type followService struct {
+	repo     repository.FollowRepository
+	userRepo repository.UserRepo
+}
+
+func NewFollowService(repo repository.FollowRepository, userRepo repository.UserRepo) FollowService {
+	return &followService{
+		repo:     repo,
+		userRepo: userRepo,
+	}
+}
+
+func (s *followService) Follow(ctx context.Context, follower int64, followee int64) error {
+	if follower == followee {
+		return ErrFollowSelf
+	}
+	_, err := s.userRepo.FindById(ctx, followee)
+	if err == repository.ErrUserNotFound {
+		return ErrFolloweeNotFound
+	}
+	if err != nil {
+		return err
+	}
+	return s.repo.AddFollowRelation(ctx, follower, followee)
+}
+
+func (s *followService) CancelFollow(ctx context.Context, follower int64, followee int64) error {
+	return s.repo.InactiveFollowRelation(ctx, follower, followee)
+}
+
+func (s *followService) IsFollowing(ctx context.Context, a int64, b int64) (bool, error) {
+	if a == b {
+		return false, nil
+	}
+	return s.repo.Followed(ctx, a, b)
+}
+
+func (s *followService) ListFollowees(ctx context.Context, uid int64, utime time.Time, id int64, limit int) ([]domain.FollowRelation, error) {
+	return s.repo.ListFollowees(ctx, uid, utime, id, limit)
+}
+
+func (s *followService) ListFollowers(ctx context.Context, uid int64, utime time.Time, id int64, limit int) ([]domain.FollowRelation, error) {
+	return s.repo.ListFollowers(ctx, uid, utime, id, limit)
+}
+
+func (s *followService) GetStatics(ctx context.Context, uid int64) (domain.FollowStatics, error) {
+	return s.repo.GetStatics(ctx, uid)
+}
+
+func (s *followService) GetStaticsByIds(ctx context.Context, uids []int64) (map[int64]domain.FollowStatics, error) {
+	return s.repo.GetStaticsByIds(ctx, uids)
+}
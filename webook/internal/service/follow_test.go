@@ -0,0 +1,62 @@
+package service
+
+import (
+	"context"
+	"gitee.com/geekbang/basic-go/webook/internal/domain"
+	"gitee.com/geekbang/basic-go/webook/internal/repository"
+	mock_repository "gitee.com/geekbang/basic-go/webook/internal/repository/mocks"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
+	"testing"
+)
+
+func Test_followService_Follow(t *testing.T) {
+	testCases := []struct {
+		name     string
+		mock     func(ctrl *gomock.Controller) (repository.FollowRepository, repository.UserRepo)
+		followee int64
+
+		wantErr error
+	}{
+		{
+			name: "关注成功",
+			mock: func(ctrl *gomock.Controller) (repository.FollowRepository, repository.UserRepo) {
+				repo := mock_repository.NewMockFollowRepository(ctrl)
+				userRepo := mock_repository.NewMockUserRepo(ctrl)
+				userRepo.EXPECT().FindById(gomock.Any(), int64(2)).Return(domain.User{Id: 2}, nil)
+				repo.EXPECT().AddFollowRelation(gomock.Any(), int64(1), int64(2)).Return(nil)
+				return repo, userRepo
+			},
+			followee: 2,
+		},
+		{
+			name: "不能关注自己",
+			mock: func(ctrl *gomock.Controller) (repository.FollowRepository, repository.UserRepo) {
+				return mock_repository.NewMockFollowRepository(ctrl), mock_repository.NewMockUserRepo(ctrl)
+			},
+			followee: 1,
+			wantErr:  ErrFollowSelf,
+		},
+		{
+			name: "被关注的用户不存在",
+			mock: func(ctrl *gomock.Controller) (repository.FollowRepository, repository.UserRepo) {
+				userRepo := mock_repository.NewMockUserRepo(ctrl)
+				userRepo.EXPECT().FindById(gomock.Any(), int64(2)).Return(domain.User{}, repository.ErrUserNotFound)
+				return mock_repository.NewMockFollowRepository(ctrl), userRepo
+			},
+			followee: 2,
+			wantErr:  ErrFolloweeNotFound,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+			repo, userRepo := tc.mock(ctrl)
+			svc := NewFollowService(repo, userRepo)
+			err := svc.Follow(context.Background(), 1, tc.followee)
+			assert.Equal(t, tc.wantErr, err)
+		})
+	}
+}
@@ -0,0 +1,83 @@
+package service
+
+import (
+	"context"
+	"gitee.com/geekbang/basic-go/webook/internal/domain"
+	"gitee.com/geekbang/basic-go/webook/internal/repository"
+	"gitee.com/geekbang/basic-go/webook/pkg/logger"
+)
+
+//go:generate mockgen -source=$GOFILE -destination=mocks/mock_$GOFILE --package=$GOPACKAGEmocks
+type InteractiveService interface {
+	IncrReadCnt(ctx context.Context, biz string, bizId int64) error
+	// Like 点赞，重复点赞不会重复计数
+	Like(ctx context.Context, biz string, bizId int64, uid int64) error
+	// CancelLike 取消点赞，没点过赞也不会报错
+	CancelLike(ctx context.Context, biz string, bizId int64, uid int64) error
+	Collect(ctx context.Context, biz string, bizId int64, uid int64) error
+	CancelCollect(ctx context.Context, biz string, bizId int64, uid int64) error
+	// Get 获得计数，以及 uid 对应的用户有没有点赞、收藏
+	Get(ctx context.Context, biz string, bizId int64, uid int64) (domain.Interactive, error)
+	// GetByIds 批量获得计数，key 是 bizId，没有互动数据的不在 map 里面
+	GetByIds(ctx context.Context, biz string, bizIds []int64) (map[int64]domain.Interactive, error)
+}
+
+type interactiveService struct {
+	repo repository.InteractiveRepository
+	log  logger.Logger
+}
+
+func NewInteractiveService(repo repository.InteractiveRepository, log logger.Logger) InteractiveService {
+	return &interactiveService{repo: repo, log: log}
+}
+
+func (s *interactiveService) IncrReadCnt(ctx context.Context, biz string, bizId int64) error {
+	return s.repo.IncrReadCnt(ctx, biz, bizId)
+}
+
+func (s *interactiveService) Like(ctx context.Context, biz string, bizId int64, uid int64) error {
+	return s.repo.IncrLike(ctx, biz, bizId, uid)
+}
+
+func (s *interactiveService) CancelLike(ctx context.Context, biz string, bizId int64, uid int64) error {
+	return s.repo.DecrLike(ctx, biz, bizId, uid)
+}
+
+func (s *interactiveService) Collect(ctx context.Context, biz string, bizId int64, uid int64) error {
+	return s.repo.AddCollectionItem(ctx, biz, bizId, uid)
+}
+
+func (s *interactiveService) CancelCollect(ctx context.Context, biz string, bizId int64, uid int64) error {
+	return s.repo.DelCollectionItem(ctx, biz, bizId, uid)
+}
+
+func (s *interactiveService) Get(ctx context.Context, biz string, bizId int64, uid int64) (domain.Interactive, error) {
+	intr, err := s.repo.Get(ctx, biz, bizId)
+	if err != nil {
+		return domain.Interactive{}, err
+	}
+	// 点赞和收藏状态查不到不影响计数的展示，降级为 false
+	intr.Liked, err = s.repo.Liked(ctx, biz, bizId, uid)
+	if err != nil {
+		s.log.Error("查询点赞状态失败", logger.Error(err),
+			logger.Int64("bizId", bizId), logger.Int64("uid", uid))
+	}
+	intr.Collected, err = s.repo.Collected(ctx, biz, bizId, uid)
+	if err != nil {
+		s.log.Error("查询收藏状态失败", logger.Error(err),
+			logger.Int64("bizId", bizId), logger.Int64("uid", uid))
+	}
+	return intr, nil
+}
+
+func (s *interactiveService) GetByIds(ctx context.Context, biz string, bizIds []int64) (map[int64]domain.Interactive, error) {
+	intrs, err := s.repo.GetByIds(ctx, biz, bizIds)
+	if err != nil {
+		return nil, err
+	}
+	res := make(map[int64]domain.Interactive, len(intrs))
+	for _, intr := range intrs {
+		res[intr.BizId] = intr
+	}
+	return res, nil
+}
@@ -0,0 +1,152 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"gitee.com/geekbang/basic-go/webook/internal/repository"
+	"gitee.com/geekbang/basic-go/webook/internal/repository/cache"
+	"gitee.com/geekbang/basic-go/webook/internal/repository/cache/redismocks"
+	"gitee.com/geekbang/basic-go/webook/internal/repository/dao/interactive"
+	"gitee.com/geekbang/basic-go/webook/pkg/logger"
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+	gormMysql "gorm.io/driver/mysql"
+	"gorm.io/gorm"
+	"regexp"
+	"testing"
+)
+
+// 计数只有状态真的变了才会改，所以重复操作不会重复计数，取消也不会减成负数
+func TestInteractiveService_LikeAndCollect(t *testing.T) {
+	testCases := []struct {
+		name string
+		// 数据库里面的用户记录
+		mockDB func(mock sqlmock.Sqlmock)
+		// 缓存只在计数变了的时候才会更新
+		mockCache func(cmd *redismocks.MockCmdable)
+		op        func(svc InteractiveService) error
+	}{
+		{
+			name: "第一次点赞，计数加一",
+			mockDB: func(mock sqlmock.Sqlmock) {
+				mock.ExpectBegin()
+				mock.ExpectExec("UPDATE `user_like_bizs` SET .*").
+					WillReturnResult(sqlmock.NewResult(0, 0))
+				mock.ExpectExec("INSERT INTO `user_like_bizs` .*").
+					WillReturnResult(sqlmock.NewResult(1, 1))
+				mock.ExpectExec(regexp.QuoteMeta("ON DUPLICATE KEY UPDATE `like_cnt`=GREATEST(`like_cnt` + ?, 0)")).
+					WillReturnResult(sqlmock.NewResult(1, 1))
+				mock.ExpectCommit()
+			},
+			mockCache: func(cmd *redismocks.MockCmdable) {
+				res := redis.NewCmd(context.Background())
+				res.SetVal(int64(1))
+				cmd.EXPECT().Eval(gomock.Any(), gomock.Any(), []string{"interactive:article:1"}, "like_cnt", 1).
+					Return(res)
+			},
+			op: func(svc InteractiveService) error {
+				return svc.Like(context.Background(), "article", 1, 123)
+			},
+		},
+		{
+			name: "重复点赞，计数不变",
+			mockDB: func(mock sqlmock.Sqlmock) {
+				mock.ExpectBegin()
+				// 已经点过赞了，UPDATE 改不到，INSERT 冲突
+				mock.ExpectExec("UPDATE `user_like_bizs` SET .*").
+					WillReturnResult(sqlmock.NewResult(0, 0))
+				mock.ExpectExec("INSERT INTO `user_like_bizs` .*").
+					WillReturnResult(sqlmock.NewResult(0, 0))
+				mock.ExpectCommit()
+			},
+			op: func(svc InteractiveService) error {
+				return svc.Like(context.Background(), "article", 1, 123)
+			},
+		},
+		{
+			name: "没点过赞就取消，计数不变",
+			mockDB: func(mock sqlmock.Sqlmock) {
+				mock.ExpectBegin()
+				mock.ExpectExec("UPDATE `user_like_bizs` SET .*").
+					WillReturnResult(sqlmock.NewResult(0, 0))
+				mock.ExpectCommit()
+			},
+			op: func(svc InteractiveService) error {
+				return svc.CancelLike(context.Background(), "article", 1, 123)
+			},
+		},
+		{
+			name: "取消收藏，计数最少减到零",
+			mockDB: func(mock sqlmock.Sqlmock) {
+				mock.ExpectBegin()
+				mock.ExpectExec("UPDATE `user_collection_bizs` SET .*").
+					WillReturnResult(sqlmock.NewResult(0, 1))
+				// 插入的是 0，已经有了的话用 GREATEST 兜底
+				mock.ExpectExec(regexp.QuoteMeta("INSERT INTO `interactives` (`biz_id`,`biz`,`read_cnt`,`like_cnt`,`collect_cnt`,`utime`,`ctime`) VALUES (?,?,?,?,?,?,?) ON DUPLICATE KEY UPDATE `collect_cnt`=GREATEST(`collect_cnt` + ?, 0)")).
+					WithArgs(1, "article", 0, 0, 0, sqlmock.AnyArg(), sqlmock.AnyArg(), -1, sqlmock.AnyArg()).
+					WillReturnResult(sqlmock.NewResult(0, 2))
+				mock.ExpectCommit()
+			},
+			mockCache: func(cmd *redismocks.MockCmdable) {
+				res := redis.NewCmd(context.Background())
+				res.SetVal(int64(1))
+				cmd.EXPECT().Eval(gomock.Any(), gomock.Any(), []string{"interactive:article:1"}, "collect_cnt", -1).
+					Return(res)
+			},
+			op: func(svc InteractiveService) error {
+				return svc.CancelCollect(context.Background(), "article", 1, 123)
+			},
+		},
+		{
+			name: "重复收藏，计数不变",
+			mockDB: func(mock sqlmock.Sqlmock) {
+				mock.ExpectBegin()
+				mock.ExpectExec("UPDATE `user_collection_bizs` SET .*").
+					WillReturnResult(sqlmock.NewResult(0, 0))
+				mock.ExpectExec("INSERT INTO `user_collection_bizs` .*").
+					WillReturnResult(sqlmock.NewResult(0, 0))
+				mock.ExpectCommit()
+			},
+			op: func(svc InteractiveService) error {
+				return svc.Collect(context.Background(), "article", 1, 123)
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+			mockDB, mock, err := sqlmock.New()
+			require.NoError(t, err)
+			tc.mockDB(mock)
+			cmd := redismocks.NewMockCmdable(ctrl)
+			if tc.mockCache != nil {
+				tc.mockCache(cmd)
+			}
+			l := &logger.NopLogger{}
+			repo := repository.NewInteractiveRepository(
+				interactive.NewInteractiveDaoGORM(newMockDB(t, mockDB)),
+				cache.NewRedisInteractiveCache(cmd), l)
+			svc := NewInteractiveService(repo, l)
+			assert.NoError(t, tc.op(svc))
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
+
+func newMockDB(t *testing.T, conn *sql.DB) *gorm.DB {
+	db, err := gorm.Open(gormMysql.New(gormMysql.Config{
+		Conn:                      conn,
+		SkipInitializeWithVersion: true,
+	}), &gorm.Config{
+		DisableAutomaticPing:   true,
+		SkipDefaultTransaction: true,
+		TranslateError:         true,
+	})
+	require.NoError(t, err)
+	return db
+}
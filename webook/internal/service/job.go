@@ -0,0 +1,81 @@
+package service
+
+import (
+	"gitee.com/geekbang/basic-go/webook/internal/domain"
+	"gitee.com/geekbang/basic-go/webook/internal/repository"
+	"gitee.com/geekbang/basic-go/webook/pkg/logger"
+	"golang.org/x/net/context"
+	"time"
+)
+
+var (
+	ErrNoJobToPreempt = repository.ErrNoJobToPreempt
+	ErrJobNotHold     = repository.ErrJobNotHold
+)
+
+type CronJobService interface {
+	// AddJob 注册任务定义，NextTime 为零值的时候按照表达式计算
+	AddJob(ctx context.Context, j domain.Job) error
+	// Preempt 抢占一个可以执行的任务
+	Preempt(ctx context.Context) (domain.Job, error)
+	// Heartbeat 持有任务期间要不断调用，返回 ErrJobNotHold 说明任务被别人抢走了
+	Heartbeat(ctx context.Context, j domain.Job) error
+	// Release 执行完毕，释放任务并计算下一次执行时间
+	Release(ctx context.Context, j domain.Job) error
+	// HeartbeatInterval 调用 Heartbeat 的间隔
+	HeartbeatInterval() time.Duration
+}
+
+type cronJobService struct {
+	repo repository.CronJobRepository
+	// owner 当前实例的标识
+	owner string
+	// 超过 heartbeatTimeout 没有心跳，就认为持有者已经崩溃了，别的实例可以抢占
+	heartbeatTimeout  time.Duration
+	heartbeatInterval time.Duration
+	l                 logger.Logger
+}
+
+func NewCronJobService(repo repository.CronJobRepository, owner string, l logger.Logger) CronJobService {
+	return &cronJobService{
+		repo:              repo,
+		owner:             owner,
+		heartbeatTimeout:  time.Minute,
+		heartbeatInterval: time.Second * 10,
+		l:                 l,
+	}
+}
+
+func (s *cronJobService) AddJob(ctx context.Context, j domain.Job) error {
+	if j.NextTime.IsZero() {
+		next, err := j.Next(time.Now())
+		if err != nil {
+			return err
+		}
+		j.NextTime = next
+	}
+	return s.repo.AddJob(ctx, j)
+}
+
+func (s *cronJobService) Preempt(ctx context.Context) (domain.Job, error) {
+	return s.repo.Preempt(ctx, s.owner, time.Now().Add(-s.heartbeatTimeout))
+}
+
+func (s *cronJobService) Heartbeat(ctx context.Context, j domain.Job) error {
+	return s.repo.Heartbeat(ctx, j.Id, s.owner)
+}
+
+func (s *cronJobService) Release(ctx context.Context, j domain.Job) error {
+	next, err := j.Next(time.Now())
+	if err != nil {
+		// 表达式有问题，再调度也没有意义
+		s.l.Error("cron 表达式错误，停止调度任务", logger.Error(err),
+			logger.Int64("jid", j.Id), logger.String("expression", j.Expression))
+		return s.repo.Pause(ctx, j.Id)
+	}
+	return s.repo.Release(ctx, j.Id, s.owner, next)
+}
+
+func (s *cronJobService) HeartbeatInterval() time.Duration {
+	return s.heartbeatInterval
+}
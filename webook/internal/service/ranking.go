@@ -0,0 +1,148 @@
+package service
+
+import (
+	"container/heap"
+	"context"
+	"errors"
+	"gitee.com/geekbang/basic-go/webook/internal/domain"
+	"gitee.com/geekbang/basic-go/webook/internal/repository"
+	"math"
+	"time"
+)
+
+//go:generate mockgen -source=$GOFILE -destination=mocks/mock_$GOFILE --package=$GOPACKAGEmocks
+type RankingService interface {
+	// TopN 重新计算热榜，并且保存起来
+	TopN(ctx context.Context) error
+	// GetTopN 获得计算好的热榜
+	GetTopN(ctx context.Context) ([]domain.Article, error)
+}
+
+// BatchRankingService 分批从数据库里面捞出最近发表的文章，在内存里面计算热度
+type BatchRankingService struct {
+	artSvc  ArticleService
+	intrSvc InteractiveService
+	repo    repository.RankingRepository
+	biz     string
+	// 每一批取多少篇文章
+	batchSize int
+	// 热榜要多少篇
+	n int
+	// 只看最近多久发表的文章
+	window    time.Duration
+	scoreFunc func(intr domain.Interactive, publishTime time.Time) float64
+}
+
+func NewBatchRankingService(artSvc ArticleService, intrSvc InteractiveService,
+	repo repository.RankingRepository) RankingService {
+	return &BatchRankingService{
+		artSvc:    artSvc,
+		intrSvc:   intrSvc,
+		repo:      repo,
+		biz:       "article",
+		batchSize: 100,
+		n:         100,
+		window:    time.Hour * 24 * 7,
+		scoreFunc: hackerNewsScore,
+	}
+}
+
+// hackerNewsScore 参考 Hacker News 的公式 (P-1) / (T+2)^G
+// P 是互动数据折算出来的分数，T 是发表了多少个小时，G 是重力因子
+func hackerNewsScore(intr domain.Interactive, publishTime time.Time) float64 {
+	const gravity = 1.5
+	points := float64(intr.LikeCnt) + float64(intr.CollectCnt)*2 + float64(intr.ReadCnt)*0.1
+	hours := time.Since(publishTime).Hours()
+	return (points - 1) / math.Pow(hours+2, gravity)
+}
+
+func (s *BatchRankingService) GetTopN(ctx context.Context) ([]domain.Article, error) {
+	return s.repo.GetTopN(ctx)
+}
+
+func (s *BatchRankingService) TopN(ctx context.Context) error {
+	arts, err := s.topN(ctx)
+	if err != nil {
+		return err
+	}
+	return s.repo.ReplaceTopN(ctx, arts)
+}
+
+func (s *BatchRankingService) topN(ctx context.Context) ([]domain.Article, error) {
+	if s.n <= 0 {
+		return nil, errors.New("热榜的数量必须大于 0")
+	}
+	ddl := time.Now().Add(-s.window)
+	// 小顶堆，堆顶是目前 topN 里面分数最低的
+	h := &scoreHeap{}
+	var (
+		utime time.Time
+		id    int64
+	)
+	for {
+		arts, err := s.artSvc.ListPub(ctx, utime, id, s.batchSize)
+		if err != nil {
+			return nil, err
+		}
+		if len(arts) == 0 {
+			break
+		}
+		ids := make([]int64, 0, len(arts))
+		for _, art := range arts {
+			ids = append(ids, art.Id)
+		}
+		intrs, err := s.intrSvc.GetByIds(ctx, s.biz, ids)
+		if err != nil {
+			return nil, err
+		}
+		for _, art := range arts {
+			// 线上库的 Ctime 就是第一次发表的时间，修改文章不会让它重新变新
+			if art.Ctime.Before(ddl) {
+				continue
+			}
+			score := s.scoreFunc(intrs[art.Id], art.Ctime)
+			if h.Len() < s.n {
+				heap.Push(h, scoredArticle{art: art, score: score})
+				continue
+			}
+			if (*h)[0].score < score {
+				(*h)[0] = scoredArticle{art: art, score: score}
+				heap.Fix(h, 0)
+			}
+		}
+		last := arts[len(arts)-1]
+		// 没有下一批了，或者已经超出了时间窗口。Ctime 不会晚于 Utime，按照 Utime 判断不会漏掉
+		if len(arts) < s.batchSize || last.Utime.Before(ddl) {
+			break
+		}
+		utime, id = last.Utime, last.Id
+	}
+	res := make([]domain.Article, h.Len())
+	for i := len(res) - 1; i >= 0; i-- {
+		res[i] = heap.Pop(h).(scoredArticle).art
+	}
+	return res, nil
+}
+
+type scoredArticle struct {
+	art   domain.Article
+	score float64
+}
+
+type scoreHeap []scoredArticle
+
+func (h scoreHeap) Len() int           { return len(h) }
+func (h scoreHeap) Less(i, j int) bool { return h[i].score < h[j].score }
+func (h scoreHeap) Swap(i, j int)      { h[i], h[j] = h[j], h[i] }
+
+func (h *scoreHeap) Push(x any) {
+	*h = append(*h, x.(scoredArticle))
+}
+
+func (h *scoreHeap) Pop() any {
+	old := *h
+	n := len(old)
+	x := old[n-1]
+	*h = old[:n-1]
+	return x
+}
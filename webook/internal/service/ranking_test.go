@@ -0,0 +1,133 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"gitee.com/geekbang/basic-go/webook/internal/domain"
+	mock_service "gitee.com/geekbang/basic-go/webook/internal/service/mocks"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
+	"testing"
+	"time"
+)
+
+func TestBatchRankingService_topN(t *testing.T) {
+	now := time.Now()
+	testCases := []struct {
+		name string
+		mock func(ctrl *gomock.Controller) (ArticleService, InteractiveService)
+
+		batchSize int
+		n         int
+
+		wantArts []domain.Article
+		wantErr  error
+	}{
+		{
+			name: "分两批计算",
+			mock: func(ctrl *gomock.Controller) (ArticleService, InteractiveService) {
+				artSvc := mock_service.NewMockArticleService(ctrl)
+				intrSvc := mock_service.NewMockInteractiveService(ctrl)
+				artSvc.EXPECT().ListPub(gomock.Any(), time.Time{}, int64(0), 2).
+					Return([]domain.Article{
+						{Id: 1, Ctime: now, Utime: now},
+						{Id: 2, Ctime: now, Utime: now},
+					}, nil)
+				intrSvc.EXPECT().GetByIds(gomock.Any(), "article", []int64{1, 2}).
+					Return(map[int64]domain.Interactive{
+						1: {BizId: 1, LikeCnt: 1},
+						2: {BizId: 2, LikeCnt: 2},
+					}, nil)
+				artSvc.EXPECT().ListPub(gomock.Any(), now, int64(2), 2).
+					Return([]domain.Article{
+						// 没有互动数据
+						{Id: 3, Ctime: now, Utime: now},
+					}, nil)
+				intrSvc.EXPECT().GetByIds(gomock.Any(), "article", []int64{3}).
+					Return(map[int64]domain.Interactive{}, nil)
+				return artSvc, intrSvc
+			},
+			batchSize: 2,
+			n:         2,
+			wantArts: []domain.Article{
+				{Id: 2, Ctime: now, Utime: now},
+				{Id: 1, Ctime: now, Utime: now},
+			},
+		},
+		{
+			name: "超出时间窗口的不参与计算",
+			mock: func(ctrl *gomock.Controller) (ArticleService, InteractiveService) {
+				artSvc := mock_service.NewMockArticleService(ctrl)
+				intrSvc := mock_service.NewMockInteractiveService(ctrl)
+				artSvc.EXPECT().ListPub(gomock.Any(), time.Time{}, int64(0), 2).
+					Return([]domain.Article{
+						{Id: 1, Ctime: now, Utime: now},
+						{Id: 2, Utime: now.Add(-time.Hour * 24 * 30)},
+					}, nil)
+				intrSvc.EXPECT().GetByIds(gomock.Any(), "article", []int64{1, 2}).
+					Return(map[int64]domain.Interactive{
+						2: {BizId: 2, LikeCnt: 100},
+					}, nil)
+				return artSvc, intrSvc
+			},
+			batchSize: 2,
+			n:         2,
+			wantArts: []domain.Article{
+				{Id: 1, Ctime: now, Utime: now},
+			},
+		},
+		{
+			name: "按照发表时间衰减，改过的旧文章不会变新",
+			mock: func(ctrl *gomock.Controller) (ArticleService, InteractiveService) {
+				artSvc := mock_service.NewMockArticleService(ctrl)
+				intrSvc := mock_service.NewMockInteractiveService(ctrl)
+				artSvc.EXPECT().ListPub(gomock.Any(), time.Time{}, int64(0), 2).
+					Return([]domain.Article{
+						// 1 是六天前发表的，刚刚改过
+						{Id: 1, Ctime: now.Add(-time.Hour * 24 * 6), Utime: now},
+						{Id: 2, Ctime: now.Add(-time.Hour), Utime: now.Add(-time.Hour)},
+					}, nil)
+				intrSvc.EXPECT().GetByIds(gomock.Any(), "article", []int64{1, 2}).
+					Return(map[int64]domain.Interactive{
+						1: {BizId: 1, LikeCnt: 10},
+						2: {BizId: 2, LikeCnt: 5},
+					}, nil)
+				artSvc.EXPECT().ListPub(gomock.Any(), now.Add(-time.Hour), int64(2), 2).
+					Return([]domain.Article{}, nil)
+				return artSvc, intrSvc
+			},
+			batchSize: 2,
+			n:         1,
+			wantArts: []domain.Article{
+				{Id: 2, Ctime: now.Add(-time.Hour), Utime: now.Add(-time.Hour)},
+			},
+		},
+		{
+			name: "查询文章失败",
+			mock: func(ctrl *gomock.Controller) (ArticleService, InteractiveService) {
+				artSvc := mock_service.NewMockArticleService(ctrl)
+				intrSvc := mock_service.NewMockInteractiveService(ctrl)
+				artSvc.EXPECT().ListPub(gomock.Any(), time.Time{}, int64(0), 2).
+					Return(nil, errors.New("db 错误"))
+				return artSvc, intrSvc
+			},
+			batchSize: 2,
+			n:         2,
+			wantErr:   errors.New("db 错误"),
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+			artSvc, intrSvc := tc.mock(ctrl)
+			svc := NewBatchRankingService(artSvc, intrSvc, nil).(*BatchRankingService)
+			svc.batchSize = tc.batchSize
+			svc.n = tc.n
+			arts, err := svc.topN(context.Background())
+			assert.Equal(t, tc.wantErr, err)
+			assert.Equal(t, tc.wantArts, arts)
+		})
+	}
+}
@@ -0,0 +1,56 @@
+package service
+
+import (
+	"context"
+	"gitee.com/geekbang/basic-go/webook/internal/domain"
+	"gitee.com/geekbang/basic-go/webook/internal/repository"
+	"gitee.com/geekbang/basic-go/webook/pkg/logger"
+	"strings"
+)
+
+//go:generate mockgen -source=$GOFILE -destination=mocks/mock_$GOFILE --package=$GOPACKAGEmocks
+type SearchService interface {
+	// SearchArticles 搜索已发表的文章，按照相关度排序
+	SearchArticles(ctx context.Context, query string, offset int, limit int) ([]domain.SearchArticle, error)
+}
+
+type searchService struct {
+	repo     repository.SearchRepository
+	userRepo repository.UserRepo
+	l        logger.Logger
+}
+
+func NewSearchService(repo repository.SearchRepository, userRepo repository.UserRepo, l logger.Logger) SearchService {
+	return &searchService{
+		repo:     repo,
+		userRepo: userRepo,
+		l:        l,
+	}
+}
+
+func (s *searchService) SearchArticles(ctx context.Context, query string, offset int, limit int) ([]domain.SearchArticle, error) {
+	query = strings.TrimSpace(query)
+	if query == "" {
+		return []domain.SearchArticle{}, nil
+	}
+	arts, err := s.repo.SearchArticle(ctx, query, offset, limit)
+	if err != nil {
+		return nil, err
+	}
+	// 索引里面只有作者 ID，名字可能会改，所以查询的时候再补上
+	names := make(map[int64]string, len(arts))
+	for i := range arts {
+		uid := arts[i].Author.Id
+		name, ok := names[uid]
+		if !ok {
+			user, er := s.userRepo.FindById(ctx, uid)
+			if er != nil {
+				s.l.Error("查询文章作者失败", logger.Error(er), logger.Int64("uid", uid))
+			}
+			name = user.Nickname
+			names[uid] = name
+		}
+		arts[i].Author.Name = name
+	}
+	return arts, nil
+}
@@ -4,32 +4,265 @@ import (
 	"errors"
 	"gitee.com/geekbang/basic-go/webook/internal/domain"
 	"gitee.com/geekbang/basic-go/webook/internal/repository"
+	"gitee.com/geekbang/basic-go/webook/pkg/logger"
+	"github.com/google/uuid"
 	"golang.org/x/crypto/bcrypt"
 	"golang.org/x/net/context"
+	"slices"
+	"time"
 )
 
 var (
 	ErrUserDuplicate         = repository.ErrUserDuplicate
+	ErrUserNotFound          = repository.ErrUserNotFound
 	ErrInvalidUserOrPassword = errors.New("账号/邮箱或密码不对")
+	ErrResetTicketInvalid    = repository.ErrResetTicketInvalid
+	ErrUserNotActivated      = errors.New("邮箱还没有验证")
+	ErrLastLoginMethod       = repository.ErrLastLoginMethod
+	ErrLoginMethodNotLinked  = errors.New("没有绑定这种登录方式")
+	ErrUserBanned            = errors.New("用户已被封禁")
+	ErrRoleNotHigher         = errors.New("只能处理级别比自己低的用户")
 )
 
+// resetTicketExpiration 验证码校验通过之后，多久之内要设置新密码
+const resetTicketExpiration = time.Minute * 10
+
+// signUpPasswordExpiration 和验证码的有效期一样
+const signUpPasswordExpiration = time.Minute * 10
+
 //go:generate mockgen -source=$GOFILE -destination=mocks/mock_$GOFILE --package=$GOPACKAGEmocks
 type UserService interface {
+	// SignUp 邮箱注册，创建出来的用户要调用 ActivateEmail 之后才能登录。
+	// 还没有激活的邮箱可以重新注册，但是不会修改之前的密码，新密码要通过 SetSignUpPassword 暂存
 	SignUp(ctx context.Context, user domain.User) error
+	// SetSignUpPassword 注册验证码发出去之后调用，暂存这一次注册的密码，
+	// 验证码校验通过调用 ActivateEmail 的时候才生效，避免知道邮箱的人改掉别人的密码
+	SetSignUpPassword(ctx context.Context, email string, password string) error
+	// ActivateEmail 邮箱已经通过验证码校验之后调用
+	ActivateEmail(ctx context.Context, email string) error
+	// Login 还没有激活的返回 ErrUserNotActivated，被封禁的返回 ErrUserBanned
 	Login(ctx context.Context, user domain.User) (domain.User, error)
 	Profile(ctx context.Context, id int64) (domain.User, error)
+	// FindOrCreate 手机号登录，被封禁的返回 ErrUserBanned
 	FindOrCreate(ctx context.Context, phone string) (domain.User, error)
+	// FindOrCreateByWechat 微信登录，被封禁的返回 ErrUserBanned
 	FindOrCreateByWechat(ctx context.Context, info domain.WechatInfo) (domain.User, error)
+	// UpdateNonSensitiveInfo 修改昵称、生日和个人简介，调用方负责校验
+	UpdateNonSensitiveInfo(ctx context.Context, user domain.User) error
+	// CreateResetTicket 手机号或者邮箱已经通过验证码校验之后调用，返回重置密码的凭证。
+	// channel 是 CodeChannelSMS 或者 CodeChannelEmail，target 是对应的手机号或者邮箱
+	CreateResetTicket(ctx context.Context, channel string, target string) (string, error)
+	// ResetPassword 用凭证设置新密码，凭证只能用一次，返回用户 ID
+	ResetPassword(ctx context.Context, ticket string, password string) (int64, error)
+	// ChangePassword 旧密码不对返回 ErrInvalidUserOrPassword
+	ChangePassword(ctx context.Context, uid int64, oldPassword string, newPassword string) error
+	// BindPhone 绑定或者修改手机号，调用方负责校验验证码，被别人用了返回 ErrUserDuplicate
+	BindPhone(ctx context.Context, uid int64, phone string) error
+	// BindEmail 和 BindPhone 一样
+	BindEmail(ctx context.Context, uid int64, email string) error
+	// BindWechat 把微信绑定到已经登录的账号上，微信已经绑定了别的账号返回 ErrUserDuplicate
+	BindWechat(ctx context.Context, uid int64, info domain.WechatInfo) error
+	// UnlinkLoginMethod 解绑一种登录方式，最后一种登录方式不能解绑
+	UnlinkLoginMethod(ctx context.Context, uid int64, method domain.LoginMethod) error
+	// Deactivate 注销账号，先撤回文章再清空个人信息。会话由调用方负责撤销
+	Deactivate(ctx context.Context, uid int64) error
+	// Export 导出个人信息和所有的文章
+	Export(ctx context.Context, uid int64) (domain.User, []domain.Article, error)
+	// Roles 用户的角色，放进 access token 里面
+	Roles(ctx context.Context, uid int64) ([]domain.Role, error)
+	// List 管理后台的用户列表
+	List(ctx context.Context, offset int, limit int) ([]domain.User, error)
+	// Ban operator 封禁 uid，已经封禁的不做处理，已经注销的返回 ErrUserNotFound。
+	// uid 的级别不比 operator 低的返回 ErrRoleNotHigher。会话由调用方负责撤销
+	Ban(ctx context.Context, operator int64, uid int64) error
+	// Unban 解封，只有封禁状态的用户才会恢复成正常状态，级别的限制和 Ban 一样
+	Unban(ctx context.Context, operator int64, uid int64) error
 }
 
 type userServiceImpl struct {
-	repo repository.UserRepo
+	repo    repository.UserRepo
+	artRepo repository.ArticleRepository
+	log     logger.Logger
+}
+
+func (svc *userServiceImpl) UpdateNonSensitiveInfo(ctx context.Context, user domain.User) error {
+	err := svc.repo.UpdateNonSensitiveInfo(ctx, user)
+	if err != nil {
+		return err
+	}
+	// 文章缓存里面有作者的昵称，删除失败也只是晚一点看到新的昵称
+	if err = svc.artRepo.DelAuthorCache(ctx, user.Id); err != nil {
+		svc.log.Error("删除作者的文章缓存失败",
+			logger.Int64("uid", user.Id), logger.Error(err))
+	}
+	return nil
+}
+
+func (svc *userServiceImpl) Deactivate(ctx context.Context, uid int64) error {
+	// 先撤回文章，这一步失败了用户还可以重试；反过来的话账号已经注销了，没有机会再撤回
+	err := svc.artRepo.WithdrawByAuthor(ctx, uid)
+	if err != nil {
+		return err
+	}
+	err = svc.repo.Deactivate(ctx, uid)
+	if err != nil {
+		return err
+	}
+	if err = svc.artRepo.DelAuthorCache(ctx, uid); err != nil {
+		svc.log.Error("删除作者的文章缓存失败",
+			logger.Int64("uid", uid), logger.Error(err))
+	}
+	return nil
+}
+
+func (svc *userServiceImpl) Export(ctx context.Context, uid int64) (domain.User, []domain.Article, error) {
+	user, err := svc.repo.FindById(ctx, uid)
+	if err != nil {
+		return domain.User{}, nil, err
+	}
+	arts, err := svc.artRepo.ListAllByAuthor(ctx, uid)
+	if err != nil {
+		return domain.User{}, nil, err
+	}
+	return user, arts, nil
+}
+
+func (svc *userServiceImpl) Roles(ctx context.Context, uid int64) ([]domain.Role, error) {
+	return svc.repo.FindRoles(ctx, uid)
+}
+
+func (svc *userServiceImpl) List(ctx context.Context, offset int, limit int) ([]domain.User, error) {
+	return svc.repo.List(ctx, offset, limit)
+}
+
+func (svc *userServiceImpl) Ban(ctx context.Context, operator int64, uid int64) error {
+	user, err := svc.repo.FindById(ctx, uid)
+	if err != nil {
+		return err
+	}
+	if user.Status == domain.UserStatusDeactivated {
+		return ErrUserNotFound
+	}
+	if err = svc.checkRank(ctx, operator, uid); err != nil {
+		return err
+	}
+	if user.Status == domain.UserStatusBanned {
+		return nil
+	}
+	return svc.repo.UpdateStatus(ctx, uid, domain.UserStatusBanned)
+}
+
+func (svc *userServiceImpl) Unban(ctx context.Context, operator int64, uid int64) error {
+	user, err := svc.repo.FindById(ctx, uid)
+	if err != nil {
+		return err
+	}
+	if user.Status != domain.UserStatusBanned {
+		return nil
+	}
+	if err = svc.checkRank(ctx, operator, uid); err != nil {
+		return err
+	}
+	return svc.repo.UpdateStatus(ctx, uid, domain.UserStatusActive)
+}
+
+// checkRank 版主不能处理管理员或者别的版主。角色以数据库为准，不用 token 里面的
+func (svc *userServiceImpl) checkRank(ctx context.Context, operator int64, uid int64) error {
+	opRoles, err := svc.repo.FindRoles(ctx, operator)
+	if err != nil {
+		return err
+	}
+	roles, err := svc.repo.FindRoles(ctx, uid)
+	if err != nil {
+		return err
+	}
+	if domain.Rank(roles) >= domain.Rank(opRoles) {
+		return ErrRoleNotHigher
+	}
+	return nil
+}
+
+func (svc *userServiceImpl) CreateResetTicket(ctx context.Context, channel string, target string) (string, error) {
+	var (
+		user domain.User
+		err  error
+	)
+	switch channel {
+	case CodeChannelSMS:
+		user, err = svc.repo.FindByPhone(ctx, target)
+	case CodeChannelEmail:
+		user, err = svc.repo.FindByEmail(ctx, target)
+	default:
+		return "", ErrUnknownCodeChannel
+	}
+	if err != nil {
+		return "", err
+	}
+	ticket := uuid.New().String()
+	err = svc.repo.CreateResetTicket(ctx, ticket, user.Id, resetTicketExpiration)
+	return ticket, err
+}
+
+func (svc *userServiceImpl) ResetPassword(ctx context.Context, ticket string, password string) (int64, error) {
+	uid, err := svc.repo.ConsumeResetTicket(ctx, ticket)
+	if err != nil {
+		return 0, err
+	}
+	hash, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+	if err != nil {
+		return 0, err
+	}
+	return uid, svc.repo.UpdatePassword(ctx, uid, string(hash))
+}
+
+func (svc *userServiceImpl) ChangePassword(ctx context.Context, uid int64, oldPassword string, newPassword string) error {
+	user, err := svc.repo.FindById(ctx, uid)
+	if err != nil {
+		return err
+	}
+	err = bcrypt.CompareHashAndPassword([]byte(user.Password), []byte(oldPassword))
+	if err != nil {
+		return ErrInvalidUserOrPassword
+	}
+	hash, err := bcrypt.GenerateFromPassword([]byte(newPassword), bcrypt.DefaultCost)
+	if err != nil {
+		return err
+	}
+	return svc.repo.UpdatePassword(ctx, uid, string(hash))
+}
+
+func (svc *userServiceImpl) BindPhone(ctx context.Context, uid int64, phone string) error {
+	return svc.repo.UpdatePhone(ctx, uid, phone)
+}
+
+func (svc *userServiceImpl) BindEmail(ctx context.Context, uid int64, email string) error {
+	return svc.repo.UpdateEmail(ctx, uid, email)
+}
+
+func (svc *userServiceImpl) BindWechat(ctx context.Context, uid int64, info domain.WechatInfo) error {
+	return svc.repo.UpdateWechat(ctx, uid, info)
+}
+
+func (svc *userServiceImpl) UnlinkLoginMethod(ctx context.Context, uid int64, method domain.LoginMethod) error {
+	user, err := svc.repo.FindById(ctx, uid)
+	if err != nil {
+		return err
+	}
+	methods := user.LoginMethods()
+	if !slices.Contains(methods, method) {
+		return ErrLoginMethodNotLinked
+	}
+	if len(methods) == 1 {
+		return ErrLastLoginMethod
+	}
+	// 并发解绑的时候，这里还是有可能返回 ErrLastLoginMethod
+	return svc.repo.RemoveLoginMethod(ctx, uid, method)
 }
 
 func (svc *userServiceImpl) FindOrCreateByWechat(ctx context.Context, info domain.WechatInfo) (domain.User, error) {
 	u, err := svc.repo.FindByWechat(ctx, info.OpenID)
 	if err != repository.ErrUserNotFound {
-		return u, err
+		return svc.checkBanned(u, err)
 	}
 	u = domain.User{
 		WechatInfo: info,
@@ -49,7 +282,7 @@ func (svc *userServiceImpl) FindOrCreate(ctx context.Context, phone string) (dom
 		// 绝大部分请求进来这里
 		// nil 会进来这里
 		// 不为 ErrUserNotFound 的也会进来这里
-		return user, err
+		return svc.checkBanned(user, err)
 	}
 	// 在系统资源不足，触发降级之后，不执行慢路径了
 	//if ctx.Value("降级") == "true" {
@@ -87,7 +320,21 @@ func (svc *userServiceImpl) Login(ctx context.Context, user domain.User) (domain
 	if err != nil {
 		return domain.User{}, ErrInvalidUserOrPassword
 	}
-	return found, nil
+	if found.Status == domain.UserStatusPending {
+		return domain.User{}, ErrUserNotActivated
+	}
+	return svc.checkBanned(found, nil)
+}
+
+// checkBanned 登录的时候用，被封禁的用户不能登录
+func (svc *userServiceImpl) checkBanned(user domain.User, err error) (domain.User, error) {
+	if err != nil {
+		return user, err
+	}
+	if user.Status == domain.UserStatusBanned {
+		return domain.User{}, ErrUserBanned
+	}
+	return user, nil
 }
 
 func (svc *userServiceImpl) SignUp(ctx context.Context, user domain.User) error {
@@ -97,11 +344,53 @@ func (svc *userServiceImpl) SignUp(ctx context.Context, user domain.User) error
 		return err
 	}
 	user.Password = string(password)
-	return svc.repo.Create(ctx, user)
+	user.Status = domain.UserStatusPending
+	err = svc.repo.Create(ctx, user)
+	if err != repository.ErrUserDuplicate {
+		return err
+	}
+	// 避免别人用你的邮箱注册了又不验证，导致你自己注册不了。
+	// 这里不能改密码，不然知道你邮箱的人可以抢在你验证之前把密码改掉
+	found, er := svc.repo.FindByEmail(ctx, user.Email)
+	if er != nil || found.Status != domain.UserStatusPending {
+		return err
+	}
+	return nil
+}
+
+func (svc *userServiceImpl) SetSignUpPassword(ctx context.Context, email string, password string) error {
+	hash, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+	if err != nil {
+		return err
+	}
+	return svc.repo.SetSignUpPassword(ctx, email, string(hash), signUpPasswordExpiration)
+}
+
+func (svc *userServiceImpl) ActivateEmail(ctx context.Context, email string) error {
+	found, err := svc.repo.FindByEmail(ctx, email)
+	if err != nil {
+		return err
+	}
+	if found.Status != domain.UserStatusPending {
+		return nil
+	}
+	// 验证码是和最后一次注册的密码一起发出去的，能拿到验证码的才是邮箱的主人
+	password, err := svc.repo.ConsumeSignUpPassword(ctx, email)
+	if err != nil {
+		return err
+	}
+	if password != "" {
+		if err = svc.repo.UpdatePassword(ctx, found.Id, password); err != nil {
+			return err
+		}
+	}
+	return svc.repo.UpdateStatus(ctx, found.Id, domain.UserStatusActive)
 }
 
-func NewUserServiceImpl(repo repository.UserRepo) UserService {
+func NewUserServiceImpl(repo repository.UserRepo, artRepo repository.ArticleRepository, l logger.Logger) UserService {
 	return &userServiceImpl{
-		repo: repo,
+		repo:    repo,
+		artRepo: artRepo,
+		log:     l,
 	}
 }
@@ -1,11 +1,13 @@
 package service
 
 import (
+	"errors"
 	"gitee.com/geekbang/basic-go/webook/internal/domain"
 	"gitee.com/geekbang/basic-go/webook/internal/repository"
 	mock_repository "gitee.com/geekbang/basic-go/webook/internal/repository/mocks"
 	"github.com/stretchr/testify/assert"
 	"go.uber.org/mock/gomock"
+	"golang.org/x/crypto/bcrypt"
 	"golang.org/x/net/context"
 	"testing"
 )
@@ -59,6 +61,25 @@ func Test_userServiceImpl_Login(t *testing.T) {
 			wantUser: domain.User{},
 			wantErr:  ErrInvalidUserOrPassword,
 		},
+		{
+			name: "用户被封禁",
+			mock: func(ctrl *gomock.Controller) repository.UserRepo {
+				repo := mock_repository.NewMockUserRepo(ctrl)
+				repo.EXPECT().FindByEmail(gomock.Any(), "123@qq.com").
+					Return(domain.User{
+						Email:    "123@qq.com",
+						Password: "$2a$10$MN9ZKKIbjLZDyEpCYW19auY7mvOG9pcpiIcUUoZZI6pA6OmKZKOVi",
+						Status:   domain.UserStatusBanned,
+					}, nil)
+				return repo
+			},
+			user: domain.User{
+				Email:    "123@qq.com",
+				Password: "hello#world123",
+			},
+			wantUser: domain.User{},
+			wantErr:  ErrUserBanned,
+		},
 	}
 
 	for _, tc := range testCases {
@@ -66,7 +87,7 @@ func Test_userServiceImpl_Login(t *testing.T) {
 			ctrl := gomock.NewController(t)
 			defer ctrl.Finish()
 			userRepo := tc.mock(ctrl)
-			userSvc := NewUserServiceImpl(userRepo)
+			userSvc := NewUserServiceImpl(userRepo, nil, nil)
 			user, err := userSvc.Login(context.Background(), tc.user)
 			assert.Equal(t, tc.wantErr, err)
 			if err == nil {
@@ -78,3 +99,425 @@ func Test_userServiceImpl_Login(t *testing.T) {
 	}
 
 }
+
+func Test_userServiceImpl_SignUp(t *testing.T) {
+	testCases := []struct {
+		name string
+		mock func(ctrl *gomock.Controller) repository.UserRepo
+
+		wantErr error
+	}{
+		{
+			name: "新用户",
+			mock: func(ctrl *gomock.Controller) repository.UserRepo {
+				repo := mock_repository.NewMockUserRepo(ctrl)
+				repo.EXPECT().Create(gomock.Any(), gomock.Any()).Return(nil)
+				return repo
+			},
+		},
+		{
+			name: "还没有激活的邮箱重新注册，不修改密码",
+			mock: func(ctrl *gomock.Controller) repository.UserRepo {
+				repo := mock_repository.NewMockUserRepo(ctrl)
+				repo.EXPECT().Create(gomock.Any(), gomock.Any()).Return(repository.ErrUserDuplicate)
+				repo.EXPECT().FindByEmail(gomock.Any(), "123@qq.com").
+					Return(domain.User{Id: 123, Status: domain.UserStatusPending}, nil)
+				return repo
+			},
+		},
+		{
+			name: "已经激活的邮箱",
+			mock: func(ctrl *gomock.Controller) repository.UserRepo {
+				repo := mock_repository.NewMockUserRepo(ctrl)
+				repo.EXPECT().Create(gomock.Any(), gomock.Any()).Return(repository.ErrUserDuplicate)
+				repo.EXPECT().FindByEmail(gomock.Any(), "123@qq.com").
+					Return(domain.User{Id: 123, Status: domain.UserStatusActive}, nil)
+				return repo
+			},
+			wantErr: ErrUserDuplicate,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+			userSvc := NewUserServiceImpl(tc.mock(ctrl), nil, nil)
+			err := userSvc.SignUp(context.Background(), domain.User{Email: "123@qq.com", Password: "hello#world123"})
+			assert.Equal(t, tc.wantErr, err)
+		})
+	}
+}
+
+func Test_userServiceImpl_ActivateEmail(t *testing.T) {
+	testCases := []struct {
+		name string
+		mock func(ctrl *gomock.Controller) repository.UserRepo
+
+		wantErr error
+	}{
+		{
+			name: "重新注册过，激活的时候才修改密码",
+			mock: func(ctrl *gomock.Controller) repository.UserRepo {
+				repo := mock_repository.NewMockUserRepo(ctrl)
+				repo.EXPECT().FindByEmail(gomock.Any(), "123@qq.com").
+					Return(domain.User{Id: 123, Status: domain.UserStatusPending}, nil)
+				repo.EXPECT().ConsumeSignUpPassword(gomock.Any(), "123@qq.com").Return("new hash", nil)
+				repo.EXPECT().UpdatePassword(gomock.Any(), int64(123), "new hash").Return(nil)
+				repo.EXPECT().UpdateStatus(gomock.Any(), int64(123), domain.UserStatusActive).Return(nil)
+				return repo
+			},
+		},
+		{
+			name: "没有暂存的密码，直接激活",
+			mock: func(ctrl *gomock.Controller) repository.UserRepo {
+				repo := mock_repository.NewMockUserRepo(ctrl)
+				repo.EXPECT().FindByEmail(gomock.Any(), "123@qq.com").
+					Return(domain.User{Id: 123, Status: domain.UserStatusPending}, nil)
+				repo.EXPECT().ConsumeSignUpPassword(gomock.Any(), "123@qq.com").Return("", nil)
+				repo.EXPECT().UpdateStatus(gomock.Any(), int64(123), domain.UserStatusActive).Return(nil)
+				return repo
+			},
+		},
+		{
+			name: "已经激活了",
+			mock: func(ctrl *gomock.Controller) repository.UserRepo {
+				repo := mock_repository.NewMockUserRepo(ctrl)
+				repo.EXPECT().FindByEmail(gomock.Any(), "123@qq.com").
+					Return(domain.User{Id: 123, Status: domain.UserStatusActive}, nil)
+				return repo
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+			userSvc := NewUserServiceImpl(tc.mock(ctrl), nil, nil)
+			err := userSvc.ActivateEmail(context.Background(), "123@qq.com")
+			assert.Equal(t, tc.wantErr, err)
+		})
+	}
+}
+
+func Test_userServiceImpl_ResetPassword(t *testing.T) {
+	testCases := []struct {
+		name string
+		mock func(ctrl *gomock.Controller) repository.UserRepo
+
+		wantUid int64
+		wantErr error
+	}{
+		{
+			name: "重置成功",
+			mock: func(ctrl *gomock.Controller) repository.UserRepo {
+				repo := mock_repository.NewMockUserRepo(ctrl)
+				repo.EXPECT().ConsumeResetTicket(gomock.Any(), "ticket").Return(int64(123), nil)
+				repo.EXPECT().UpdatePassword(gomock.Any(), int64(123), gomock.Any()).
+					DoAndReturn(func(ctx context.Context, id int64, password string) error {
+						// 存的是加密之后的
+						return bcrypt.CompareHashAndPassword([]byte(password), []byte("hello#world123"))
+					})
+				return repo
+			},
+			wantUid: 123,
+		},
+		{
+			name: "凭证无效",
+			mock: func(ctrl *gomock.Controller) repository.UserRepo {
+				repo := mock_repository.NewMockUserRepo(ctrl)
+				repo.EXPECT().ConsumeResetTicket(gomock.Any(), "ticket").
+					Return(int64(0), repository.ErrResetTicketInvalid)
+				return repo
+			},
+			wantErr: ErrResetTicketInvalid,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+			userSvc := NewUserServiceImpl(tc.mock(ctrl), nil, nil)
+			uid, err := userSvc.ResetPassword(context.Background(), "ticket", "hello#world123")
+			assert.Equal(t, tc.wantErr, err)
+			assert.Equal(t, tc.wantUid, uid)
+		})
+	}
+}
+
+func Test_userServiceImpl_CreateResetTicket(t *testing.T) {
+	testCases := []struct {
+		name    string
+		mock    func(ctrl *gomock.Controller) repository.UserRepo
+		channel string
+		target  string
+
+		wantErr error
+	}{
+		{
+			name: "手机号",
+			mock: func(ctrl *gomock.Controller) repository.UserRepo {
+				repo := mock_repository.NewMockUserRepo(ctrl)
+				repo.EXPECT().FindByPhone(gomock.Any(), "15212345678").Return(domain.User{Id: 123}, nil)
+				repo.EXPECT().CreateResetTicket(gomock.Any(), gomock.Any(), int64(123), resetTicketExpiration).Return(nil)
+				return repo
+			},
+			channel: CodeChannelSMS,
+			target:  "15212345678",
+		},
+		{
+			name: "邮箱",
+			mock: func(ctrl *gomock.Controller) repository.UserRepo {
+				repo := mock_repository.NewMockUserRepo(ctrl)
+				repo.EXPECT().FindByEmail(gomock.Any(), "123@qq.com").Return(domain.User{Id: 123}, nil)
+				repo.EXPECT().CreateResetTicket(gomock.Any(), gomock.Any(), int64(123), resetTicketExpiration).Return(nil)
+				return repo
+			},
+			channel: CodeChannelEmail,
+			target:  "123@qq.com",
+		},
+		{
+			name: "邮箱未注册",
+			mock: func(ctrl *gomock.Controller) repository.UserRepo {
+				repo := mock_repository.NewMockUserRepo(ctrl)
+				repo.EXPECT().FindByEmail(gomock.Any(), "123@qq.com").Return(domain.User{}, repository.ErrUserNotFound)
+				return repo
+			},
+			channel: CodeChannelEmail,
+			target:  "123@qq.com",
+			wantErr: ErrUserNotFound,
+		},
+		{
+			name: "未知渠道",
+			mock: func(ctrl *gomock.Controller) repository.UserRepo {
+				return mock_repository.NewMockUserRepo(ctrl)
+			},
+			channel: "wechat",
+			target:  "abc",
+			wantErr: ErrUnknownCodeChannel,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+			userSvc := NewUserServiceImpl(tc.mock(ctrl), nil, nil)
+			ticket, err := userSvc.CreateResetTicket(context.Background(), tc.channel, tc.target)
+			assert.Equal(t, tc.wantErr, err)
+			if err == nil {
+				assert.NotEmpty(t, ticket)
+			}
+		})
+	}
+}
+
+func Test_userServiceImpl_UnlinkLoginMethod(t *testing.T) {
+	testCases := []struct {
+		name   string
+		mock   func(ctrl *gomock.Controller) repository.UserRepo
+		method domain.LoginMethod
+
+		wantErr error
+	}{
+		{
+			name: "解绑微信",
+			mock: func(ctrl *gomock.Controller) repository.UserRepo {
+				repo := mock_repository.NewMockUserRepo(ctrl)
+				repo.EXPECT().FindById(gomock.Any(), int64(123)).Return(domain.User{
+					Id:         123,
+					Phone:      "15212345678",
+					WechatInfo: domain.WechatInfo{OpenID: "open_id"},
+				}, nil)
+				repo.EXPECT().RemoveLoginMethod(gomock.Any(), int64(123), domain.LoginMethodWechat).Return(nil)
+				return repo
+			},
+			method: domain.LoginMethodWechat,
+		},
+		{
+			name: "只剩下一种登录方式",
+			mock: func(ctrl *gomock.Controller) repository.UserRepo {
+				repo := mock_repository.NewMockUserRepo(ctrl)
+				repo.EXPECT().FindById(gomock.Any(), int64(123)).Return(domain.User{
+					Id:    123,
+					Phone: "15212345678",
+					// 没有密码的邮箱不能登录
+					Email: "123@qq.com",
+				}, nil)
+				return repo
+			},
+			method:  domain.LoginMethodPhone,
+			wantErr: ErrLastLoginMethod,
+		},
+		{
+			name: "没有绑定",
+			mock: func(ctrl *gomock.Controller) repository.UserRepo {
+				repo := mock_repository.NewMockUserRepo(ctrl)
+				repo.EXPECT().FindById(gomock.Any(), int64(123)).Return(domain.User{
+					Id:    123,
+					Phone: "15212345678",
+				}, nil)
+				return repo
+			},
+			method:  domain.LoginMethodWechat,
+			wantErr: ErrLoginMethodNotLinked,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+			userSvc := NewUserServiceImpl(tc.mock(ctrl), nil, nil)
+			err := userSvc.UnlinkLoginMethod(context.Background(), 123, tc.method)
+			assert.Equal(t, tc.wantErr, err)
+		})
+	}
+}
+
+func Test_userServiceImpl_Deactivate(t *testing.T) {
+	testCases := []struct {
+		name string
+		mock func(ctrl *gomock.Controller) (repository.UserRepo, repository.ArticleRepository)
+
+		wantErr error
+	}{
+		{
+			name: "注销成功",
+			mock: func(ctrl *gomock.Controller) (repository.UserRepo, repository.ArticleRepository) {
+				repo := mock_repository.NewMockUserRepo(ctrl)
+				artRepo := mock_repository.NewMockArticleRepository(ctrl)
+				gomock.InOrder(
+					artRepo.EXPECT().WithdrawByAuthor(gomock.Any(), int64(123)).Return(nil),
+					repo.EXPECT().Deactivate(gomock.Any(), int64(123)).Return(nil),
+					artRepo.EXPECT().DelAuthorCache(gomock.Any(), int64(123)).Return(nil),
+				)
+				return repo, artRepo
+			},
+		},
+		{
+			name: "撤回文章失败",
+			mock: func(ctrl *gomock.Controller) (repository.UserRepo, repository.ArticleRepository) {
+				repo := mock_repository.NewMockUserRepo(ctrl)
+				artRepo := mock_repository.NewMockArticleRepository(ctrl)
+				// 文章没撤回，账号也不能注销
+				artRepo.EXPECT().WithdrawByAuthor(gomock.Any(), int64(123)).Return(errors.New("mock db error"))
+				return repo, artRepo
+			},
+			wantErr: errors.New("mock db error"),
+		},
+		{
+			name: "已经注销过了",
+			mock: func(ctrl *gomock.Controller) (repository.UserRepo, repository.ArticleRepository) {
+				repo := mock_repository.NewMockUserRepo(ctrl)
+				artRepo := mock_repository.NewMockArticleRepository(ctrl)
+				artRepo.EXPECT().WithdrawByAuthor(gomock.Any(), int64(123)).Return(nil)
+				repo.EXPECT().Deactivate(gomock.Any(), int64(123)).Return(repository.ErrUserNotFound)
+				return repo, artRepo
+			},
+			wantErr: ErrUserNotFound,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+			repo, artRepo := tc.mock(ctrl)
+			userSvc := NewUserServiceImpl(repo, artRepo, nil)
+			err := userSvc.Deactivate(context.Background(), 123)
+			assert.Equal(t, tc.wantErr, err)
+		})
+	}
+}
+
+func Test_userServiceImpl_Ban(t *testing.T) {
+	testCases := []struct {
+		name string
+		mock func(ctrl *gomock.Controller) repository.UserRepo
+
+		wantErr error
+	}{
+		{
+			name: "封禁成功",
+			mock: func(ctrl *gomock.Controller) repository.UserRepo {
+				repo := mock_repository.NewMockUserRepo(ctrl)
+				repo.EXPECT().FindById(gomock.Any(), int64(123)).
+					Return(domain.User{Id: 123, Status: domain.UserStatusActive}, nil)
+				repo.EXPECT().FindRoles(gomock.Any(), int64(1)).Return([]domain.Role{domain.RoleModerator}, nil)
+				repo.EXPECT().FindRoles(gomock.Any(), int64(123)).Return(nil, nil)
+				repo.EXPECT().UpdateStatus(gomock.Any(), int64(123), domain.UserStatusBanned).Return(nil)
+				return repo
+			},
+		},
+		{
+			name: "已经封禁了",
+			mock: func(ctrl *gomock.Controller) repository.UserRepo {
+				repo := mock_repository.NewMockUserRepo(ctrl)
+				repo.EXPECT().FindById(gomock.Any(), int64(123)).
+					Return(domain.User{Id: 123, Status: domain.UserStatusBanned}, nil)
+				repo.EXPECT().FindRoles(gomock.Any(), int64(1)).Return([]domain.Role{domain.RoleAdmin}, nil)
+				repo.EXPECT().FindRoles(gomock.Any(), int64(123)).Return(nil, nil)
+				return repo
+			},
+		},
+		{
+			name: "已经注销了",
+			mock: func(ctrl *gomock.Controller) repository.UserRepo {
+				repo := mock_repository.NewMockUserRepo(ctrl)
+				repo.EXPECT().FindById(gomock.Any(), int64(123)).
+					Return(domain.User{Id: 123, Status: domain.UserStatusDeactivated}, nil)
+				return repo
+			},
+			wantErr: ErrUserNotFound,
+		},
+		{
+			name: "版主不能封禁管理员",
+			mock: func(ctrl *gomock.Controller) repository.UserRepo {
+				repo := mock_repository.NewMockUserRepo(ctrl)
+				repo.EXPECT().FindById(gomock.Any(), int64(123)).
+					Return(domain.User{Id: 123, Status: domain.UserStatusActive}, nil)
+				repo.EXPECT().FindRoles(gomock.Any(), int64(1)).Return([]domain.Role{domain.RoleModerator}, nil)
+				repo.EXPECT().FindRoles(gomock.Any(), int64(123)).Return([]domain.Role{domain.RoleAdmin}, nil)
+				return repo
+			},
+			wantErr: ErrRoleNotHigher,
+		},
+		{
+			name: "版主不能封禁别的版主",
+			mock: func(ctrl *gomock.Controller) repository.UserRepo {
+				repo := mock_repository.NewMockUserRepo(ctrl)
+				repo.EXPECT().FindById(gomock.Any(), int64(123)).
+					Return(domain.User{Id: 123, Status: domain.UserStatusActive}, nil)
+				repo.EXPECT().FindRoles(gomock.Any(), int64(1)).Return([]domain.Role{domain.RoleModerator}, nil)
+				repo.EXPECT().FindRoles(gomock.Any(), int64(123)).Return([]domain.Role{domain.RoleModerator}, nil)
+				return repo
+			},
+			wantErr: ErrRoleNotHigher,
+		},
+		{
+			name: "管理员可以封禁版主",
+			mock: func(ctrl *gomock.Controller) repository.UserRepo {
+				repo := mock_repository.NewMockUserRepo(ctrl)
+				repo.EXPECT().FindById(gomock.Any(), int64(123)).
+					Return(domain.User{Id: 123, Status: domain.UserStatusActive}, nil)
+				repo.EXPECT().FindRoles(gomock.Any(), int64(1)).Return([]domain.Role{domain.RoleAdmin}, nil)
+				repo.EXPECT().FindRoles(gomock.Any(), int64(123)).Return([]domain.Role{domain.RoleModerator}, nil)
+				repo.EXPECT().UpdateStatus(gomock.Any(), int64(123), domain.UserStatusBanned).Return(nil)
+				return repo
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+			userSvc := NewUserServiceImpl(tc.mock(ctrl), nil, nil)
+			err := userSvc.Ban(context.Background(), 1, 123)
+			assert.Equal(t, tc.wantErr, err)
+		})
+	}
+}
@@ -0,0 +1,220 @@
+package web
+
+import (
+	"gitee.com/geekbang/basic-go/webook/internal/domain"
+	"gitee.com/geekbang/basic-go/webook/internal/service"
+	"gitee.com/geekbang/basic-go/webook/internal/web/jwt"
+	"gitee.com/geekbang/basic-go/webook/internal/web/middlewares"
+	"gitee.com/geekbang/basic-go/webook/pkg/logger"
+	"github.com/gin-gonic/gin"
+	"net/http"
+	"time"
+)
+
+var _ handler = (*AdminHandler)(nil)
+
+// AdminHandler 管理后台，每个接口按照权限校验
+type AdminHandler struct {
+	userSvc service.UserService
+	artSvc  service.ArticleService
+	jwt.Handler
+	log logger.Logger
+}
+
+func NewAdminHandler(userSvc service.UserService, artSvc service.ArticleService,
+	jwtHdl jwt.Handler, log logger.Logger) *AdminHandler {
+	return &AdminHandler{
+		userSvc: userSvc,
+		artSvc:  artSvc,
+		Handler: jwtHdl,
+		log:     log,
+	}
+}
+
+func (h *AdminHandler) RegisterHandlers(engine *gin.Engine) {
+	g := engine.Group("/admin")
+	g.POST("/users/list",
+		middlewares.NewRBACMiddlewareBuilder(domain.PermissionUserList).Build(), h.ListUsers)
+	g.POST("/users/ban",
+		middlewares.NewRBACMiddlewareBuilder(domain.PermissionUserBan).Build(), h.Ban)
+	g.POST("/users/unban",
+		middlewares.NewRBACMiddlewareBuilder(domain.PermissionUserBan).Build(), h.Unban)
+	g.POST("/articles/withdraw",
+		middlewares.NewRBACMiddlewareBuilder(domain.PermissionArticleWithdraw).Build(), h.WithdrawArticle)
+}
+
+func (h *AdminHandler) ListUsers(ctx *gin.Context) {
+	var req AdminUserListReq
+	if err := ctx.Bind(&req); err != nil {
+		h.log.Error("反序列化请求失败", logger.Error(err))
+		return
+	}
+	if req.Limit <= 0 || req.Limit > 100 || req.Offset < 0 {
+		ctx.JSON(http.StatusOK, Result{
+			Code: 4,
+			Msg:  "请求有误",
+		})
+		return
+	}
+	users, err := h.userSvc.List(ctx, req.Offset, req.Limit)
+	if err != nil {
+		ctx.JSON(http.StatusOK, Result{
+			Code: 5,
+			Msg:  "系统错误",
+		})
+		h.log.Error("查询用户列表失败", logger.Error(err))
+		return
+	}
+	res := make([]AdminUserVo, 0, len(users))
+	for _, u := range users {
+		res = append(res, AdminUserVo{
+			Id:       u.Id,
+			Email:    u.Email,
+			Phone:    u.Phone,
+			Nickname: u.Nickname,
+			Status:   uint8(u.Status),
+			Ctime:    u.Ctime.Format(time.DateTime),
+		})
+	}
+	ctx.JSON(http.StatusOK, Result{
+		Msg:  "成功",
+		Data: res,
+	})
+}
+
+// Ban 封禁之后用户所有的会话都失效
+func (h *AdminHandler) Ban(ctx *gin.Context) {
+	var req AdminUserReq
+	if err := ctx.Bind(&req); err != nil {
+		h.log.Error("反序列化请求失败", logger.Error(err))
+		return
+	}
+	uc, ok := ctx.MustGet(jwt.KeyAccessClaims).(*jwt.AccessClaims)
+	if !ok {
+		ctx.JSON(http.StatusOK, Result{
+			Code: 5,
+			Msg:  "系统错误",
+		})
+		h.log.Error("获得用户会话信息失败")
+		return
+	}
+	if req.Uid <= 0 || req.Uid == uc.Uid {
+		ctx.JSON(http.StatusOK, Result{
+			Code: 4,
+			Msg:  "不能封禁这个用户",
+		})
+		return
+	}
+	err := h.userSvc.Ban(ctx, uc.Uid, req.Uid)
+	switch err {
+	case nil:
+	case service.ErrUserNotFound:
+		ctx.JSON(http.StatusOK, Result{
+			Code: 4,
+			Msg:  "用户不存在",
+		})
+		return
+	case service.ErrRoleNotHigher:
+		ctx.JSON(http.StatusOK, Result{
+			Code: 4,
+			Msg:  "不能封禁这个用户",
+		})
+		return
+	default:
+		ctx.JSON(http.StatusOK, Result{
+			Code: 5,
+			Msg:  "系统错误",
+		})
+		h.log.Error("封禁用户失败", logger.Error(err),
+			logger.Int64("operator", uc.Uid), logger.Int64("uid", req.Uid))
+		return
+	}
+	// 加入封禁名单之后，没有过期的 access token 也不能用了
+	if err = h.BanUser(ctx, req.Uid); err != nil {
+		// 数据库里面已经是封禁状态，重试的时候会再加一次
+		ctx.JSON(http.StatusOK, Result{
+			Code: 5,
+			Msg:  "系统错误",
+		})
+		h.log.Error("加入封禁名单失败", logger.Error(err), logger.Int64("uid", req.Uid))
+		return
+	}
+	if err = h.RevokeSessions(ctx, req.Uid); err != nil {
+		// 已经封禁了，只是旧的会话没能踢掉
+		h.log.Error("封禁之后清除会话失败", logger.Error(err), logger.Int64("uid", req.Uid))
+	}
+	h.log.Info("封禁用户", logger.Int64("operator", uc.Uid), logger.Int64("uid", req.Uid))
+	ctx.JSON(http.StatusOK, Result{
+		Msg: "封禁成功",
+	})
+}
+
+func (h *AdminHandler) Unban(ctx *gin.Context) {
+	var req AdminUserReq
+	if err := ctx.Bind(&req); err != nil {
+		h.log.Error("反序列化请求失败", logger.Error(err))
+		return
+	}
+	uc, ok := ctx.MustGet(jwt.KeyAccessClaims).(*jwt.AccessClaims)
+	if !ok {
+		ctx.JSON(http.StatusOK, Result{
+			Code: 5,
+			Msg:  "系统错误",
+		})
+		h.log.Error("获得用户会话信息失败")
+		return
+	}
+	err := h.userSvc.Unban(ctx, uc.Uid, req.Uid)
+	if err == nil {
+		err = h.UnbanUser(ctx, req.Uid)
+	}
+	switch err {
+	case nil:
+		ctx.JSON(http.StatusOK, Result{
+			Msg: "解封成功",
+		})
+	case service.ErrUserNotFound:
+		ctx.JSON(http.StatusOK, Result{
+			Code: 4,
+			Msg:  "用户不存在",
+		})
+	case service.ErrRoleNotHigher:
+		ctx.JSON(http.StatusOK, Result{
+			Code: 4,
+			Msg:  "不能解封这个用户",
+		})
+	default:
+		ctx.JSON(http.StatusOK, Result{
+			Code: 5,
+			Msg:  "系统错误",
+		})
+		h.log.Error("解封用户失败", logger.Error(err), logger.Int64("uid", req.Uid))
+	}
+}
+
+// WithdrawArticle 强制下架任何人的文章
+func (h *AdminHandler) WithdrawArticle(ctx *gin.Context) {
+	var req AdminArticleReq
+	if err := ctx.Bind(&req); err != nil {
+		h.log.Error("反序列化请求失败", logger.Error(err))
+		return
+	}
+	err := h.artSvc.ForceWithdraw(ctx, req.Id)
+	switch err {
+	case nil:
+		ctx.JSON(http.StatusOK, Result{
+			Msg: "下架成功",
+		})
+	case service.ErrArticleNotFound:
+		ctx.JSON(http.StatusOK, Result{
+			Code: 4,
+			Msg:  "文章不存在",
+		})
+	default:
+		ctx.JSON(http.StatusOK, Result{
+			Code: 5,
+			Msg:  "系统错误",
+		})
+		h.log.Error("下架文章失败", logger.Error(err), logger.Int64("aid", req.Id))
+	}
+}
@@ -0,0 +1,24 @@
+package web
+
+type AdminUserListReq struct {
+	Offset int `json:"offset"`
+	Limit  int `json:"limit"`
+}
+
+type AdminUserReq struct {
+	Uid int64 `json:"uid"`
+}
+
+type AdminArticleReq struct {
+	Id int64 `json:"id"`
+}
+
+// AdminUserVo 管理后台看到的用户，不包括密码之类的
+type AdminUserVo struct {
+	Id       int64  `json:"id"`
+	Email    string `json:"email"`
+	Phone    string `json:"phone"`
+	Nickname string `json:"nickname"`
+	Status   uint8  `json:"status"`
+	Ctime    string `json:"ctime"`
+}
@@ -1,6 +1,7 @@
 package web
 
 import (
+	"context"
 	"gitee.com/geekbang/basic-go/webook/internal/domain"
 	"gitee.com/geekbang/basic-go/webook/internal/service"
 	"gitee.com/geekbang/basic-go/webook/internal/web/jwt"
@@ -14,12 +15,29 @@ import (
 var _ handler = (*ArticleHandler)(nil)
 
 type ArticleHandler struct {
-	svc service.ArticleService
-	log logger.Logger
+	svc        service.ArticleService
+	intrSvc    service.InteractiveService
+	rankingSvc service.RankingService
+	commentSvc service.CommentService
+	followSvc  service.FollowService
+	biz        string
+	log        logger.Logger
 }
 
-func NewArticleHandler(svc service.ArticleService, log logger.Logger) *ArticleHandler {
-	return &ArticleHandler{svc: svc, log: log}
+func NewArticleHandler(svc service.ArticleService,
+	intrSvc service.InteractiveService,
+	rankingSvc service.RankingService,
+	commentSvc service.CommentService,
+	followSvc service.FollowService, log logger.Logger) *ArticleHandler {
+	return &ArticleHandler{
+		svc:        svc,
+		intrSvc:    intrSvc,
+		rankingSvc: rankingSvc,
+		commentSvc: commentSvc,
+		followSvc:  followSvc,
+		biz:        "article",
+		log:        log,
+	}
 }
 
 func (h *ArticleHandler) RegisterHandlers(engine *gin.Engine) {
@@ -27,11 +45,24 @@ func (h *ArticleHandler) RegisterHandlers(engine *gin.Engine) {
 	g.POST("/edit", h.Edit)
 	g.POST("/publish", h.Publish)
 	g.POST("/withdraw", h.Withdraw)
+	g.POST("/schedule", h.Schedule)
+	g.POST("/schedule/cancel", h.CancelSchedule)
+	g.POST("/delete", h.Delete)
+	g.POST("/recover", h.Recover)
+	g.POST("/trash", h.Trash)
 	g.POST("/list", h.List)
 	g.GET("/detail/:id", h.Detail)
+	g.POST("/revisions", h.Revisions)
+	g.GET("/revision/:id", h.Revision)
+	g.POST("/revision/restore", h.Restore)
 	pub := g.Group("/pub")
-	//pub.GET("/pub", a.PubList)
+	pub.POST("/list", h.PubList)
+	pub.GET("/hot", h.Hot)
+	pub.POST("/tag", h.TagList)
+	pub.GET("/tags", h.PopularTags)
 	pub.GET("/:id", h.PubDetail)
+	pub.POST("/like", h.Like)
+	pub.POST("/collect", h.Collect)
 }
 
 func (h *ArticleHandler) Edit(ctx *gin.Context) {
@@ -55,6 +86,9 @@ func (h *ArticleHandler) Edit(ctx *gin.Context) {
 	article := h.toDomain(req, uc.Uid)
 
 	id, err := h.svc.Save(ctx, article)
+	if h.isInvalidTags(ctx, err) {
+		return
+	}
 	if err != nil {
 		ctx.JSON(http.StatusOK, Result{
 			Code: 5,
@@ -76,12 +110,32 @@ func (h *ArticleHandler) toDomain(req ArticleReq, uid int64) domain.Article {
 		Id:      req.Id,
 		Title:   req.Title,
 		Content: req.Content,
+		Tags:    req.Tags,
 		Author: domain.Author{
 			Id: uid,
 		},
 	}
 }
 
+// isInvalidTags 标签不合法的时候直接返回错误给前端
+func (h *ArticleHandler) isInvalidTags(ctx *gin.Context, err error) bool {
+	switch err {
+	case service.ErrTooManyTags:
+		ctx.JSON(http.StatusOK, Result{
+			Code: 4,
+			Msg:  "标签太多",
+		})
+		return true
+	case service.ErrInvalidTag:
+		ctx.JSON(http.StatusOK, Result{
+			Code: 4,
+			Msg:  "标签不能为空，也不能太长",
+		})
+		return true
+	}
+	return false
+}
+
 func (h *ArticleHandler) Publish(ctx *gin.Context) {
 	var req ArticleReq
 	err := ctx.Bind(&req)
@@ -103,6 +157,9 @@ func (h *ArticleHandler) Publish(ctx *gin.Context) {
 	article := h.toDomain(req, uc.Uid)
 
 	id, err := h.svc.Publish(ctx, article)
+	if h.isInvalidTags(ctx, err) {
+		return
+	}
 	if err != nil {
 		ctx.JSON(http.StatusOK, Result{
 			Code: 5,
@@ -156,6 +213,235 @@ func (h *ArticleHandler) Withdraw(ctx *gin.Context) {
 	})
 }
 
+// Schedule 保存草稿，到了 PublishTime 自动发表
+func (h *ArticleHandler) Schedule(ctx *gin.Context) {
+	var req ScheduleReq
+	err := ctx.Bind(&req)
+	if err != nil {
+		h.log.Error("反序列化请求失败", logger.Error(err))
+		return
+	}
+
+	uc, ok := ctx.MustGet(jwt.KeyAccessClaims).(*jwt.AccessClaims)
+	if !ok {
+		ctx.JSON(http.StatusOK, Result{
+			Code: 5,
+			Msg:  "系统错误",
+		})
+		h.log.Error("获得用户会话信息失败")
+		return
+	}
+
+	article := h.toDomain(req.ArticleReq, uc.Uid)
+	article.PublishTime = time.UnixMilli(req.PublishTime)
+
+	id, err := h.svc.Schedule(ctx, article)
+	switch err {
+	case nil:
+		ctx.JSON(http.StatusOK, Result{
+			Code: 2,
+			Msg:  "定时发表成功",
+			Data: id,
+		})
+	case service.ErrInvalidPublishTime:
+		ctx.JSON(http.StatusOK, Result{
+			Code: 4,
+			Msg:  "发表时间必须在将来",
+		})
+	case service.ErrTooManyTags, service.ErrInvalidTag:
+		h.isInvalidTags(ctx, err)
+	default:
+		ctx.JSON(http.StatusOK, Result{
+			Code: 5,
+			Msg:  "系统错误",
+		})
+		h.log.Error("定时发表帖子失败", logger.Error(err))
+	}
+}
+
+// CancelSchedule 取消定时发表，文章变回草稿
+func (h *ArticleHandler) CancelSchedule(ctx *gin.Context) {
+	var req ArticleReq
+	err := ctx.Bind(&req)
+	if err != nil {
+		h.log.Error("反序列化请求失败", logger.Error(err))
+		return
+	}
+
+	uc, ok := ctx.MustGet(jwt.KeyAccessClaims).(*jwt.AccessClaims)
+	if !ok {
+		ctx.JSON(http.StatusOK, Result{
+			Code: 5,
+			Msg:  "系统错误",
+		})
+		h.log.Error("获得用户会话信息失败")
+		return
+	}
+
+	err = h.svc.CancelSchedule(ctx, uc.Uid, req.Id)
+	if err != nil {
+		ctx.JSON(http.StatusOK, Result{
+			Code: 5,
+			Msg:  "系统错误",
+		})
+		h.log.Error("取消定时发表失败", logger.Error(err))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, Result{
+		Code: 2,
+		Msg:  "成功",
+		Data: req.Id,
+	})
+}
+
+// Delete 软删除，在回收站里面还能找回来
+func (h *ArticleHandler) Delete(ctx *gin.Context) {
+	var req ArticleReq
+	err := ctx.Bind(&req)
+	if err != nil {
+		h.log.Error("反序列化请求失败", logger.Error(err))
+		return
+	}
+
+	uc, ok := ctx.MustGet(jwt.KeyAccessClaims).(*jwt.AccessClaims)
+	if !ok {
+		ctx.JSON(http.StatusOK, Result{
+			Code: 5,
+			Msg:  "系统错误",
+		})
+		h.log.Error("获得用户会话信息失败")
+		return
+	}
+
+	err = h.svc.Delete(ctx, uc.Uid, req.Id)
+	switch err {
+	case nil:
+		ctx.JSON(http.StatusOK, Result{
+			Code: 2,
+			Msg:  "删除成功",
+			Data: req.Id,
+		})
+	case service.ErrPossibleIncorrectAuthor:
+		ctx.JSON(http.StatusOK, Result{
+			Code: 4,
+			Msg:  "输入有误",
+		})
+		h.log.Error("非法删除文章，创作者 ID 不匹配",
+			logger.Int64("uid", uc.Uid), logger.Int64("aid", req.Id))
+	default:
+		ctx.JSON(http.StatusOK, Result{
+			Code: 5,
+			Msg:  "系统错误",
+		})
+		h.log.Error("删除文章失败", logger.Error(err))
+	}
+}
+
+// Recover 从回收站里面恢复
+func (h *ArticleHandler) Recover(ctx *gin.Context) {
+	var req ArticleReq
+	err := ctx.Bind(&req)
+	if err != nil {
+		h.log.Error("反序列化请求失败", logger.Error(err))
+		return
+	}
+
+	uc, ok := ctx.MustGet(jwt.KeyAccessClaims).(*jwt.AccessClaims)
+	if !ok {
+		ctx.JSON(http.StatusOK, Result{
+			Code: 5,
+			Msg:  "系统错误",
+		})
+		h.log.Error("获得用户会话信息失败")
+		return
+	}
+
+	err = h.svc.Recover(ctx, uc.Uid, req.Id)
+	switch err {
+	case nil:
+		ctx.JSON(http.StatusOK, Result{
+			Code: 2,
+			Msg:  "恢复成功",
+			Data: req.Id,
+		})
+	case service.ErrArticleNotFound:
+		ctx.JSON(http.StatusOK, Result{
+			Code: 4,
+			Msg:  "文章不存在或者已经超过了可以恢复的时间",
+		})
+	default:
+		ctx.JSON(http.StatusOK, Result{
+			Code: 5,
+			Msg:  "系统错误",
+		})
+		h.log.Error("恢复文章失败", logger.Error(err))
+	}
+}
+
+// Trash 回收站，列出还可以恢复的文章
+func (h *ArticleHandler) Trash(ctx *gin.Context) {
+	type Req struct {
+		Limit  int `json:"limit,omitempty"`
+		Offset int `json:"offset,omitempty"`
+	}
+	var req Req
+	err := ctx.Bind(&req)
+	if err != nil {
+		h.log.Error("反序列化请求失败", logger.Error(err))
+		return
+	}
+
+	if req.Limit > 100 || req.Limit < 0 || req.Offset < 0 {
+		ctx.JSON(http.StatusOK, Result{
+			Code: 4,
+			Msg:  "请求有误",
+		})
+		h.log.Error("参数有误", logger.Field{Key: "req", Value: req})
+		return
+	}
+
+	uc, ok := ctx.MustGet(jwt.KeyAccessClaims).(*jwt.AccessClaims)
+	if !ok {
+		ctx.JSON(http.StatusOK, Result{
+			Code: 5,
+			Msg:  "系统错误",
+		})
+		h.log.Error("获得用户会话信息失败")
+		return
+	}
+
+	arts, err := h.svc.ListDeleted(ctx, uc.Uid, req.Offset, req.Limit)
+	if err != nil {
+		ctx.JSON(http.StatusOK, Result{
+			Code: 5,
+			Msg:  "系统错误",
+		})
+		h.log.Error("查找回收站失败", logger.Error(err),
+			logger.Int64("uid", uc.Uid))
+		return
+	}
+
+	artVos := make([]ArticleVo, 0, len(arts))
+	for _, art := range arts {
+		artVos = append(artVos, ArticleVo{
+			Id:       art.Id,
+			Title:    art.Title,
+			Abstract: art.Abstract(),
+			Status:   art.Status.ToUint8(),
+			Ctime:    art.Ctime.Format(time.DateTime),
+			// 删除的时候会更新 utime，也就是删除时间
+			Utime: art.Utime.Format(time.DateTime),
+		})
+	}
+
+	ctx.JSON(http.StatusOK, Result{
+		Code: 2,
+		Msg:  "成功",
+		Data: artVos,
+	})
+}
+
 func (h *ArticleHandler) List(ctx *gin.Context) {
 	type Req struct {
 		Limit  int `json:"limit,omitempty"`
@@ -212,8 +498,10 @@ func (h *ArticleHandler) List(ctx *gin.Context) {
 			//Content: src.Content,
 			// 这个是创作者看自己的文章列表，也不需要这个字段
 			//Author: src.Author
-			Ctime: art.Ctime.Format(time.DateTime),
-			Utime: art.Utime.Format(time.DateTime),
+			Ctime:       art.Ctime.Format(time.DateTime),
+			Utime:       art.Utime.Format(time.DateTime),
+			PublishTime: h.publishTime(art),
+			Tags:        art.Tags,
 		})
 	}
 
@@ -276,12 +564,328 @@ func (h *ArticleHandler) Detail(ctx *gin.Context) {
 			Content: art.Content,
 			// 这个是创作者看自己的文章列表，也不需要这个字段
 			//Author: art.Author
-			Ctime: art.Ctime.Format(time.DateTime),
-			Utime: art.Utime.Format(time.DateTime),
+			Ctime:       art.Ctime.Format(time.DateTime),
+			Utime:       art.Utime.Format(time.DateTime),
+			PublishTime: h.publishTime(art),
+			Tags:        art.Tags,
+		},
+	})
+}
+
+// publishTime 只有定时发表的文章才返回
+func (h *ArticleHandler) publishTime(art domain.Article) string {
+	if art.Status != domain.ArticleStatusScheduled {
+		return ""
+	}
+	return art.PublishTime.Format(time.DateTime)
+}
+
+func (h *ArticleHandler) Revisions(ctx *gin.Context) {
+	type Req struct {
+		Id     int64 `json:"id"`
+		Offset int   `json:"offset"`
+		Limit  int   `json:"limit"`
+	}
+	var req Req
+	if err := ctx.Bind(&req); err != nil {
+		h.log.Error("反序列化请求失败", logger.Error(err))
+		return
+	}
+	if req.Limit > 100 || req.Limit <= 0 || req.Offset < 0 {
+		ctx.JSON(http.StatusOK, Result{
+			Code: 4,
+			Msg:  "请求有误",
+		})
+		h.log.Error("参数有误", logger.Field{Key: "req", Value: req})
+		return
+	}
+
+	uc, ok := ctx.MustGet(jwt.KeyAccessClaims).(*jwt.AccessClaims)
+	if !ok {
+		ctx.JSON(http.StatusOK, Result{
+			Code: 5,
+			Msg:  "系统错误",
+		})
+		h.log.Error("获得用户会话信息失败")
+		return
+	}
+
+	revs, err := h.svc.ListRevisions(ctx, uc.Uid, req.Id, req.Offset, req.Limit)
+	if err != nil {
+		ctx.JSON(http.StatusOK, Result{
+			Code: 5,
+			Msg:  "系统错误",
+		})
+		h.log.Error("查询文章历史版本失败", logger.Error(err))
+		return
+	}
+	res := make([]RevisionVo, 0, len(revs))
+	for _, rev := range revs {
+		res = append(res, RevisionVo{
+			Id:        rev.Id,
+			ArticleId: rev.ArticleId,
+			Title:     rev.Title,
+			Abstract:  rev.Abstract(),
+			Status:    rev.Status.ToUint8(),
+			Ctime:     rev.Ctime.Format(time.DateTime),
+		})
+	}
+	ctx.JSON(http.StatusOK, Result{
+		Msg:  "成功",
+		Data: res,
+	})
+}
+
+func (h *ArticleHandler) Revision(ctx *gin.Context) {
+	id, err := strconv.ParseInt(ctx.Param("id"), 10, 64)
+	if err != nil {
+		ctx.JSON(http.StatusOK, Result{
+			Code: 4,
+			Msg:  "参数错误",
+		})
+		h.log.Error("前端输入的 ID 不对", logger.Error(err))
+		return
+	}
+
+	uc, ok := ctx.MustGet(jwt.KeyAccessClaims).(*jwt.AccessClaims)
+	if !ok {
+		ctx.JSON(http.StatusOK, Result{
+			Code: 5,
+			Msg:  "系统错误",
+		})
+		h.log.Error("获得用户会话信息失败")
+		return
+	}
+
+	rev, err := h.svc.GetRevision(ctx, uc.Uid, id)
+	switch err {
+	case nil:
+	case service.ErrPossibleIncorrectAuthor:
+		ctx.JSON(http.StatusOK, Result{
+			Code: 4,
+			Msg:  "输入有误",
+		})
+		h.log.Error("非法访问文章历史版本，创作者 ID 不匹配",
+			logger.Int64("uid", uc.Uid), logger.Int64("rid", id))
+		return
+	default:
+		ctx.JSON(http.StatusOK, Result{
+			Code: 5,
+			Msg:  "系统错误",
+		})
+		h.log.Error("获得文章历史版本失败", logger.Error(err))
+		return
+	}
+	ctx.JSON(http.StatusOK, Result{
+		Data: RevisionVo{
+			Id:        rev.Id,
+			ArticleId: rev.ArticleId,
+			Title:     rev.Title,
+			Content:   rev.Content,
+			Status:    rev.Status.ToUint8(),
+			Ctime:     rev.Ctime.Format(time.DateTime),
 		},
 	})
 }
 
+func (h *ArticleHandler) Restore(ctx *gin.Context) {
+	type Req struct {
+		Id         int64 `json:"id"`
+		RevisionId int64 `json:"revisionId"`
+	}
+	var req Req
+	if err := ctx.Bind(&req); err != nil {
+		h.log.Error("反序列化请求失败", logger.Error(err))
+		return
+	}
+
+	uc, ok := ctx.MustGet(jwt.KeyAccessClaims).(*jwt.AccessClaims)
+	if !ok {
+		ctx.JSON(http.StatusOK, Result{
+			Code: 5,
+			Msg:  "系统错误",
+		})
+		h.log.Error("获得用户会话信息失败")
+		return
+	}
+
+	err := h.svc.Restore(ctx, uc.Uid, req.Id, req.RevisionId)
+	switch err {
+	case nil:
+		ctx.JSON(http.StatusOK, Result{
+			Code: 2,
+			Msg:  "恢复成功",
+			Data: req.Id,
+		})
+	case service.ErrPossibleIncorrectAuthor:
+		ctx.JSON(http.StatusOK, Result{
+			Code: 4,
+			Msg:  "输入有误",
+		})
+		h.log.Error("非法恢复文章历史版本，创作者 ID 不匹配",
+			logger.Int64("uid", uc.Uid), logger.Int64("aid", req.Id),
+			logger.Int64("rid", req.RevisionId))
+	default:
+		ctx.JSON(http.StatusOK, Result{
+			Code: 5,
+			Msg:  "系统错误",
+		})
+		h.log.Error("恢复文章历史版本失败", logger.Error(err))
+	}
+}
+
+func (h *ArticleHandler) PubList(ctx *gin.Context) {
+	var req PubListReq
+	if err := ctx.Bind(&req); err != nil {
+		h.log.Error("反序列化请求失败", logger.Error(err))
+		return
+	}
+	if req.Limit <= 0 || req.Limit > 100 || req.Utime < 0 || req.Id < 0 {
+		ctx.JSON(http.StatusOK, Result{
+			Code: 4,
+			Msg:  "请求有误",
+		})
+		h.log.Error("参数有误", logger.Field{Key: "req", Value: req})
+		return
+	}
+
+	var utime time.Time
+	if req.Utime > 0 {
+		utime = time.UnixMilli(req.Utime)
+	}
+	arts, err := h.svc.ListPub(ctx, utime, req.Id, req.Limit)
+	if err != nil {
+		ctx.JSON(http.StatusOK, Result{
+			Code: 5,
+			Msg:  "系统错误",
+		})
+		h.log.Error("查询文章列表失败", logger.Error(err))
+		return
+	}
+
+	res := PubListVo{
+		Articles: make([]ArticleVo, 0, len(arts)),
+		HasMore:  len(arts) == req.Limit,
+	}
+	for _, art := range arts {
+		res.Articles = append(res.Articles, ArticleVo{
+			Id:       art.Id,
+			Title:    art.Title,
+			Abstract: art.Abstract(),
+			Author:   art.Author.Name,
+			Ctime:    art.Ctime.Format(time.DateTime),
+			Utime:    art.Utime.Format(time.DateTime),
+		})
+	}
+	if len(arts) > 0 {
+		// 格式化之后的 utime 精度不够，所以单独返回游标
+		last := arts[len(arts)-1]
+		res.NextUtime = last.Utime.UnixMilli()
+		res.NextId = last.Id
+	}
+
+	ctx.JSON(http.StatusOK, Result{
+		Msg:  "成功",
+		Data: res,
+	})
+}
+
+// TagList 标签下面已经发表的文章
+func (h *ArticleHandler) TagList(ctx *gin.Context) {
+	var req TagListReq
+	if err := ctx.Bind(&req); err != nil {
+		h.log.Error("反序列化请求失败", logger.Error(err))
+		return
+	}
+	if req.Tag == "" || req.Limit > 100 || req.Limit <= 0 || req.Offset < 0 {
+		ctx.JSON(http.StatusOK, Result{
+			Code: 4,
+			Msg:  "请求有误",
+		})
+		h.log.Error("参数有误", logger.Field{Key: "req", Value: req})
+		return
+	}
+
+	arts, err := h.svc.ListByTag(ctx, req.Tag, req.Offset, req.Limit)
+	if err != nil {
+		ctx.JSON(http.StatusOK, Result{
+			Code: 5,
+			Msg:  "系统错误",
+		})
+		h.log.Error("查找标签下的文章失败", logger.Error(err),
+			logger.String("tag", req.Tag))
+		return
+	}
+	res := make([]ArticleVo, 0, len(arts))
+	for _, art := range arts {
+		res = append(res, ArticleVo{
+			Id:       art.Id,
+			Title:    art.Title,
+			Abstract: art.Abstract(),
+			Author:   art.Author.Name,
+			Ctime:    art.Ctime.Format(time.DateTime),
+			Utime:    art.Utime.Format(time.DateTime),
+			Tags:     art.Tags,
+		})
+	}
+	ctx.JSON(http.StatusOK, Result{
+		Msg:  "成功",
+		Data: res,
+	})
+}
+
+// PopularTags 热门标签，按照已发表的文章数量排序
+func (h *ArticleHandler) PopularTags(ctx *gin.Context) {
+	const n = 20
+	tags, err := h.svc.PopularTags(ctx, n)
+	if err != nil {
+		ctx.JSON(http.StatusOK, Result{
+			Code: 5,
+			Msg:  "系统错误",
+		})
+		h.log.Error("获得热门标签失败", logger.Error(err))
+		return
+	}
+	res := make([]TagVo, 0, len(tags))
+	for _, tag := range tags {
+		res = append(res, TagVo{
+			Name:       tag.Name,
+			ArticleCnt: tag.ArticleCnt,
+		})
+	}
+	ctx.JSON(http.StatusOK, Result{
+		Msg:  "成功",
+		Data: res,
+	})
+}
+
+func (h *ArticleHandler) Hot(ctx *gin.Context) {
+	arts, err := h.rankingSvc.GetTopN(ctx)
+	if err != nil {
+		ctx.JSON(http.StatusOK, Result{
+			Code: 5,
+			Msg:  "系统错误",
+		})
+		h.log.Error("获得热榜失败", logger.Error(err))
+		return
+	}
+	res := make([]ArticleVo, 0, len(arts))
+	for _, art := range arts {
+		res = append(res, ArticleVo{
+			Id:       art.Id,
+			Title:    art.Title,
+			Abstract: art.Abstract(),
+			Author:   art.Author.Name,
+			Ctime:    art.Ctime.Format(time.DateTime),
+			Utime:    art.Utime.Format(time.DateTime),
+		})
+	}
+	ctx.JSON(http.StatusOK, Result{
+		Msg:  "成功",
+		Data: res,
+	})
+}
+
 func (h *ArticleHandler) PubDetail(ctx *gin.Context) {
 	idStr := ctx.Param("id")
 	id, err := strconv.ParseInt(idStr, 10, 64)
@@ -294,15 +898,49 @@ func (h *ArticleHandler) PubDetail(ctx *gin.Context) {
 		return
 	}
 
-	art, err := h.svc.GetPubById(ctx, id)
-	if err != nil {
+	uc, ok := ctx.MustGet(jwt.KeyAccessClaims).(*jwt.AccessClaims)
+	if !ok {
 		ctx.JSON(http.StatusOK, Result{
 			Code: 5,
 			Msg:  "系统错误",
 		})
-		h.log.Error("获得文章信息失败", logger.Error(err))
+		h.log.Error("获得用户会话信息失败")
 		return
 	}
+
+	// 撤回、私密和删除了的文章都当作不存在，也不计算阅读数
+	art, ok := h.getPubArticle(ctx, id)
+	if !ok {
+		return
+	}
+
+	// 增加阅读计数，不需要阻塞读者
+	go func() {
+		ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+		defer cancel()
+		if er := h.intrSvc.IncrReadCnt(ctx, h.biz, art.Id); er != nil {
+			h.log.Error("增加阅读计数失败",
+				logger.Int64("aid", art.Id), logger.Error(er))
+		}
+	}()
+
+	intr, err := h.intrSvc.Get(ctx, h.biz, id, uc.Uid)
+	if err != nil {
+		// 互动数据拿不到，文章照样可以看
+		h.log.Error("获得互动数据失败",
+			logger.Int64("aid", art.Id), logger.Error(err))
+	}
+	commentCnt, err := h.commentSvc.Count(ctx, h.biz, id)
+	if err != nil {
+		h.log.Error("获得评论数失败",
+			logger.Int64("aid", art.Id), logger.Error(err))
+	}
+	followed, err := h.followSvc.IsFollowing(ctx, uc.Uid, art.Author.Id)
+	if err != nil {
+		h.log.Error("获得关注关系失败",
+			logger.Int64("uid", uc.Uid), logger.Int64("author", art.Author.Id), logger.Error(err))
+	}
+
 	ctx.JSON(http.StatusOK, Result{
 		Data: ArticleVo{
 			Id:    art.Id,
@@ -312,10 +950,118 @@ func (h *ArticleHandler) PubDetail(ctx *gin.Context) {
 			Status:  art.Status.ToUint8(),
 			Content: art.Content,
 			// 这个是创作者看自己的文章列表，也不需要这个字段
-			Author: art.Author.Name,
-			Ctime:  art.Ctime.Format(time.DateTime),
-			Utime:  art.Utime.Format(time.DateTime),
+			Author:         art.Author.Name,
+			AuthorId:       art.Author.Id,
+			AuthorFollowed: followed,
+			Ctime:          art.Ctime.Format(time.DateTime),
+			Utime:          art.Utime.Format(time.DateTime),
+			ReadCnt:        intr.ReadCnt,
+			LikeCnt:        intr.LikeCnt,
+			CollectCnt:     intr.CollectCnt,
+			Liked:          intr.Liked,
+			Collected:      intr.Collected,
+			CommentCnt:     commentCnt,
+			Tags:           art.Tags,
 		},
 	})
 
 }
+
+// getPubArticle 只有已经发表的文章才能看、点赞、收藏，不然随便一个 ID 都能刷出互动数据。
+// 取消不检查，文章撤回之后用户还是要能取消收藏的。返回 false 的时候已经写好了响应
+func (h *ArticleHandler) getPubArticle(ctx *gin.Context, id int64) (domain.Article, bool) {
+	art, err := h.svc.GetPubById(ctx, id)
+	switch {
+	case err == service.ErrArticleNotFound ||
+		(err == nil && art.Status != domain.ArticleStatusPublished):
+		ctx.JSON(http.StatusOK, Result{
+			Code: 4,
+			Msg:  "文章不存在",
+		})
+		return domain.Article{}, false
+	case err != nil:
+		ctx.JSON(http.StatusOK, Result{
+			Code: 5,
+			Msg:  "系统错误",
+		})
+		h.log.Error("获得文章信息失败", logger.Error(err), logger.Int64("aid", id))
+		return domain.Article{}, false
+	}
+	return art, true
+}
+
+func (h *ArticleHandler) Like(ctx *gin.Context) {
+	var req LikeReq
+	if err := ctx.Bind(&req); err != nil {
+		h.log.Error("反序列化请求失败", logger.Error(err))
+		return
+	}
+
+	uc, ok := ctx.MustGet(jwt.KeyAccessClaims).(*jwt.AccessClaims)
+	if !ok {
+		ctx.JSON(http.StatusOK, Result{
+			Code: 5,
+			Msg:  "系统错误",
+		})
+		h.log.Error("获得用户会话信息失败")
+		return
+	}
+
+	var err error
+	if req.Like {
+		if _, ok := h.getPubArticle(ctx, req.Id); !ok {
+			return
+		}
+		err = h.intrSvc.Like(ctx, h.biz, req.Id, uc.Uid)
+	} else {
+		err = h.intrSvc.CancelLike(ctx, h.biz, req.Id, uc.Uid)
+	}
+	if err != nil {
+		ctx.JSON(http.StatusOK, Result{
+			Code: 5,
+			Msg:  "系统错误",
+		})
+		h.log.Error("点赞/取消点赞失败", logger.Error(err),
+			logger.Int64("uid", uc.Uid), logger.Int64("aid", req.Id))
+		return
+	}
+	ctx.JSON(http.StatusOK, Result{Msg: "OK"})
+}
+
+func (h *ArticleHandler) Collect(ctx *gin.Context) {
+	var req CollectReq
+	if err := ctx.Bind(&req); err != nil {
+		h.log.Error("反序列化请求失败", logger.Error(err))
+		return
+	}
+
+	uc, ok := ctx.MustGet(jwt.KeyAccessClaims).(*jwt.AccessClaims)
+	if !ok {
+		ctx.JSON(http.StatusOK, Result{
+			Code: 5,
+			Msg:  "系统错误",
+		})
+		h.log.Error("获得用户会话信息失败")
+		return
+	}
+
+	var err error
+	if req.Collect {
+		if _, ok := h.getPubArticle(ctx, req.Id); !ok {
+			return
+		}
+		err = h.intrSvc.Collect(ctx, h.biz, req.Id, uc.Uid)
+	} else {
+		err = h.intrSvc.CancelCollect(ctx, h.biz, req.Id, uc.Uid)
+	}
+	if err != nil {
+		ctx.JSON(http.StatusOK, Result{
+			Code: 5,
+			Msg:  "系统错误",
+		})
+		h.log.Error("收藏/取消收藏失败", logger.Error(err),
+			logger.Int64("uid", uc.Uid), logger.Int64("aid", req.Id))
+		return
+	}
+	ctx.JSON(http.StatusOK, Result{Msg: "OK"})
+}
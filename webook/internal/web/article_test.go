@@ -0,0 +1,168 @@
+package web
+
+import (
+	"bytes"
+	"encoding/json"
+	"gitee.com/geekbang/basic-go/webook/internal/domain"
+	"gitee.com/geekbang/basic-go/webook/internal/service"
+	mock_service "gitee.com/geekbang/basic-go/webook/internal/service/mocks"
+	"gitee.com/geekbang/basic-go/webook/internal/web/jwt"
+	"gitee.com/geekbang/basic-go/webook/pkg/logger"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestArticleHandler_Like(t *testing.T) {
+	testCases := []struct {
+		name string
+		mock func(ctrl *gomock.Controller) (service.ArticleService, service.InteractiveService)
+		body string
+
+		wantResult Result
+	}{
+		{
+			name: "点赞成功",
+			mock: func(ctrl *gomock.Controller) (service.ArticleService, service.InteractiveService) {
+				artSvc := mock_service.NewMockArticleService(ctrl)
+				intrSvc := mock_service.NewMockInteractiveService(ctrl)
+				artSvc.EXPECT().GetPubById(gomock.Any(), int64(1)).
+					Return(domain.Article{Id: 1, Status: domain.ArticleStatusPublished}, nil)
+				intrSvc.EXPECT().Like(gomock.Any(), "article", int64(1), int64(123)).Return(nil)
+				return artSvc, intrSvc
+			},
+			body:       `{"id": 1, "like": true}`,
+			wantResult: Result{Msg: "OK"},
+		},
+		{
+			name: "文章不存在",
+			mock: func(ctrl *gomock.Controller) (service.ArticleService, service.InteractiveService) {
+				artSvc := mock_service.NewMockArticleService(ctrl)
+				artSvc.EXPECT().GetPubById(gomock.Any(), int64(1)).
+					Return(domain.Article{}, service.ErrArticleNotFound)
+				return artSvc, mock_service.NewMockInteractiveService(ctrl)
+			},
+			body:       `{"id": 1, "like": true}`,
+			wantResult: Result{Code: 4, Msg: "文章不存在"},
+		},
+		{
+			name: "文章已经撤回",
+			mock: func(ctrl *gomock.Controller) (service.ArticleService, service.InteractiveService) {
+				artSvc := mock_service.NewMockArticleService(ctrl)
+				artSvc.EXPECT().GetPubById(gomock.Any(), int64(1)).
+					Return(domain.Article{Id: 1, Status: domain.ArticleStatusPrivate}, nil)
+				return artSvc, mock_service.NewMockInteractiveService(ctrl)
+			},
+			body:       `{"id": 1, "like": true}`,
+			wantResult: Result{Code: 4, Msg: "文章不存在"},
+		},
+		{
+			name: "取消点赞不检查文章",
+			mock: func(ctrl *gomock.Controller) (service.ArticleService, service.InteractiveService) {
+				intrSvc := mock_service.NewMockInteractiveService(ctrl)
+				intrSvc.EXPECT().CancelLike(gomock.Any(), "article", int64(1), int64(123)).Return(nil)
+				return mock_service.NewMockArticleService(ctrl), intrSvc
+			},
+			body:       `{"id": 1, "like": false}`,
+			wantResult: Result{Msg: "OK"},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+			artSvc, intrSvc := tc.mock(ctrl)
+			hdl := NewArticleHandler(artSvc, intrSvc, nil, nil, nil, &logger.NopLogger{})
+
+			engine := gin.Default()
+			engine.Use(func(ctx *gin.Context) {
+				ctx.Set(jwt.KeyAccessClaims, &jwt.AccessClaims{Uid: 123})
+			})
+			hdl.RegisterHandlers(engine)
+			req, err := http.NewRequest(http.MethodPost, "/articles/pub/like",
+				bytes.NewReader([]byte(tc.body)))
+			require.NoError(t, err)
+			req.Header.Set("Content-Type", "application/json")
+
+			resp := httptest.NewRecorder()
+			engine.ServeHTTP(resp, req)
+			assert.Equal(t, http.StatusOK, resp.Code)
+			var res Result
+			err = json.NewDecoder(resp.Body).Decode(&res)
+			require.NoError(t, err)
+			assert.Equal(t, tc.wantResult, res)
+		})
+	}
+}
+
+func TestArticleHandler_PubDetail(t *testing.T) {
+	testCases := []struct {
+		name string
+		mock func(ctrl *gomock.Controller) service.ArticleService
+
+		wantResult Result
+	}{
+		{
+			name: "文章已经删除",
+			mock: func(ctrl *gomock.Controller) service.ArticleService {
+				artSvc := mock_service.NewMockArticleService(ctrl)
+				artSvc.EXPECT().GetPubById(gomock.Any(), int64(1)).
+					Return(domain.Article{}, service.ErrArticleNotFound)
+				return artSvc
+			},
+			wantResult: Result{Code: 4, Msg: "文章不存在"},
+		},
+		{
+			name: "文章已经撤回，不计算阅读数",
+			mock: func(ctrl *gomock.Controller) service.ArticleService {
+				artSvc := mock_service.NewMockArticleService(ctrl)
+				artSvc.EXPECT().GetPubById(gomock.Any(), int64(1)).
+					Return(domain.Article{Id: 1, Status: domain.ArticleStatusUnpublished}, nil)
+				return artSvc
+			},
+			wantResult: Result{Code: 4, Msg: "文章不存在"},
+		},
+		{
+			name: "私密文章",
+			mock: func(ctrl *gomock.Controller) service.ArticleService {
+				artSvc := mock_service.NewMockArticleService(ctrl)
+				artSvc.EXPECT().GetPubById(gomock.Any(), int64(1)).
+					Return(domain.Article{Id: 1, Status: domain.ArticleStatusPrivate}, nil)
+				return artSvc
+			},
+			wantResult: Result{Code: 4, Msg: "文章不存在"},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+			// 互动、评论和关注都不应该被调用
+			hdl := NewArticleHandler(tc.mock(ctrl), mock_service.NewMockInteractiveService(ctrl),
+				nil, mock_service.NewMockCommentService(ctrl), mock_service.NewMockFollowService(ctrl),
+				&logger.NopLogger{})
+
+			engine := gin.Default()
+			engine.Use(func(ctx *gin.Context) {
+				ctx.Set(jwt.KeyAccessClaims, &jwt.AccessClaims{Uid: 123})
+			})
+			hdl.RegisterHandlers(engine)
+			req, err := http.NewRequest(http.MethodGet, "/articles/pub/1", nil)
+			require.NoError(t, err)
+
+			resp := httptest.NewRecorder()
+			engine.ServeHTTP(resp, req)
+			assert.Equal(t, http.StatusOK, resp.Code)
+			var res Result
+			err = json.NewDecoder(resp.Body).Decode(&res)
+			require.NoError(t, err)
+			assert.Equal(t, tc.wantResult, res)
+		})
+	}
+}
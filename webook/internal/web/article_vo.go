@@ -11,10 +11,90 @@ type ArticleVo struct {
 	Author  string `json:"author"`
 	Ctime   string `json:"ctime"`
 	Utime   string `json:"utime"`
+	// 定时发表的时间
+	PublishTime string   `json:"publishTime,omitempty"`
+	Tags        []string `json:"tags,omitempty"`
+
+	// 读者看文章的时候才有
+	AuthorId       int64 `json:"authorId,omitempty"`
+	AuthorFollowed bool  `json:"authorFollowed"`
+
+	// 互动数据
+	ReadCnt    int64 `json:"readCnt"`
+	LikeCnt    int64 `json:"likeCnt"`
+	CollectCnt int64 `json:"collectCnt"`
+	Liked      bool  `json:"liked"`
+	Collected  bool  `json:"collected"`
+	CommentCnt int64 `json:"commentCnt"`
 }
 
 type ArticleReq struct {
 	Id      int64  `json:"id"`
 	Title   string `json:"title"`
 	Content string `json:"content"`
+	// 不传表示不修改标签，传空数组表示清空标签
+	Tags []string `json:"tags"`
+}
+
+type ScheduleReq struct {
+	ArticleReq
+	// 定时发表的时间，毫秒数
+	PublishTime int64 `json:"publishTime"`
+}
+
+type LikeReq struct {
+	Id int64 `json:"id"`
+	// true 是点赞，false 是取消点赞
+	Like bool `json:"like"`
+}
+
+type CollectReq struct {
+	Id int64 `json:"id"`
+	// true 是收藏，false 是取消收藏
+	Collect bool `json:"collect"`
+}
+
+// PubListReq 读者文章列表的游标
+// 第一页 Utime 和 Id 都传 0，后面传上一页返回的 NextUtime 和 NextId
+type PubListReq struct {
+	Utime int64 `json:"utime"`
+	Id    int64 `json:"id"`
+	Limit int   `json:"limit"`
+}
+
+type PubListVo struct {
+	Articles  []ArticleVo `json:"articles"`
+	NextUtime int64       `json:"nextUtime"`
+	NextId    int64       `json:"nextId"`
+	HasMore   bool        `json:"hasMore"`
+}
+
+type RevisionVo struct {
+	Id        int64  `json:"id"`
+	ArticleId int64  `json:"articleId"`
+	Title     string `json:"title"`
+	Abstract  string `json:"abstract"`
+	Content   string `json:"content"`
+	Status    uint8  `json:"status"`
+	Ctime     string `json:"ctime"`
+}
+
+type TagListReq struct {
+	Tag    string `json:"tag"`
+	Offset int    `json:"offset"`
+	Limit  int    `json:"limit"`
+}
+
+type TagVo struct {
+	Name       string `json:"name"`
+	ArticleCnt int64  `json:"articleCnt"`
+}
+
+// SearchArticleVo 搜索结果，Title 和 Abstract 里面命中的部分用 <em> 包起来了
+type SearchArticleVo struct {
+	Id       int64  `json:"id"`
+	Title    string `json:"title"`
+	Abstract string `json:"abstract"`
+	Author   string `json:"author"`
+	Utime    string `json:"utime"`
 }
@@ -0,0 +1,223 @@
+package web
+
+import (
+	"gitee.com/geekbang/basic-go/webook/internal/domain"
+	"gitee.com/geekbang/basic-go/webook/internal/service"
+	"gitee.com/geekbang/basic-go/webook/internal/web/jwt"
+	"gitee.com/geekbang/basic-go/webook/pkg/logger"
+	"github.com/gin-gonic/gin"
+	"net/http"
+	"time"
+)
+
+type CommentHandler struct {
+	svc service.CommentService
+	// 目前只有文章可以评论
+	biz string
+	log logger.Logger
+}
+
+func NewCommentHandler(svc service.CommentService, log logger.Logger) *CommentHandler {
+	return &CommentHandler{
+		svc: svc,
+		biz: "article",
+		log: log,
+	}
+}
+
+func (h *CommentHandler) RegisterHandlers(engine *gin.Engine) {
+	g := engine.Group("/comments")
+	g.POST("/create", h.Create)
+	g.POST("/list", h.List)
+	g.POST("/replies", h.Replies)
+	g.POST("/delete", h.Delete)
+}
+
+func (h *CommentHandler) Create(ctx *gin.Context) {
+	var req CommentReq
+	if err := ctx.Bind(&req); err != nil {
+		h.log.Error("反序列化请求失败", logger.Error(err))
+		return
+	}
+
+	uc, ok := ctx.MustGet(jwt.KeyAccessClaims).(*jwt.AccessClaims)
+	if !ok {
+		ctx.JSON(http.StatusOK, Result{
+			Code: 5,
+			Msg:  "系统错误",
+		})
+		h.log.Error("获得用户会话信息失败")
+		return
+	}
+
+	id, err := h.svc.CreateComment(ctx, domain.Comment{
+		Biz:         h.biz,
+		BizId:       req.BizId,
+		Commentator: domain.Author{Id: uc.Uid},
+		Content:     req.Content,
+		ParentId:    req.ParentId,
+	})
+	switch err {
+	case nil:
+		ctx.JSON(http.StatusOK, Result{
+			Msg:  "评论成功",
+			Data: id,
+		})
+	case service.ErrInvalidComment:
+		ctx.JSON(http.StatusOK, Result{
+			Code: 4,
+			Msg:  "评论不能为空，也不能太长",
+		})
+	case service.ErrCommentTargetNotFound:
+		ctx.JSON(http.StatusOK, Result{
+			Code: 4,
+			Msg:  "文章或者评论不存在",
+		})
+	default:
+		ctx.JSON(http.StatusOK, Result{
+			Code: 5,
+			Msg:  "系统错误",
+		})
+		h.log.Error("发表评论失败", logger.Error(err))
+	}
+}
+
+// List 文章的根评论，按照时间倒序
+func (h *CommentHandler) List(ctx *gin.Context) {
+	type Req struct {
+		BizId int64 `json:"bizId"`
+		// 上一页最后一条评论的 ID，第一页不传
+		MaxId int64 `json:"maxId"`
+		Limit int   `json:"limit"`
+	}
+	var req Req
+	if err := ctx.Bind(&req); err != nil {
+		h.log.Error("反序列化请求失败", logger.Error(err))
+		return
+	}
+	if req.Limit > 100 || req.Limit <= 0 {
+		ctx.JSON(http.StatusOK, Result{
+			Code: 4,
+			Msg:  "请求有误",
+		})
+		h.log.Error("参数有误", logger.Field{Key: "req", Value: req})
+		return
+	}
+
+	cmts, err := h.svc.ListRoots(ctx, h.biz, req.BizId, req.MaxId, req.Limit)
+	if err != nil {
+		ctx.JSON(http.StatusOK, Result{
+			Code: 5,
+			Msg:  "系统错误",
+		})
+		h.log.Error("查询评论失败", logger.Error(err),
+			logger.Int64("bizId", req.BizId))
+		return
+	}
+	ctx.JSON(http.StatusOK, Result{
+		Msg:  "成功",
+		Data: h.toVos(cmts),
+	})
+}
+
+// Replies 根评论下面的回复，按照时间正序
+func (h *CommentHandler) Replies(ctx *gin.Context) {
+	type Req struct {
+		RootId int64 `json:"rootId"`
+		// 上一页最后一条回复的 ID，第一页不传
+		MinId int64 `json:"minId"`
+		Limit int   `json:"limit"`
+	}
+	var req Req
+	if err := ctx.Bind(&req); err != nil {
+		h.log.Error("反序列化请求失败", logger.Error(err))
+		return
+	}
+	if req.Limit > 100 || req.Limit <= 0 {
+		ctx.JSON(http.StatusOK, Result{
+			Code: 4,
+			Msg:  "请求有误",
+		})
+		h.log.Error("参数有误", logger.Field{Key: "req", Value: req})
+		return
+	}
+
+	cmts, err := h.svc.ListReplies(ctx, req.RootId, req.MinId, req.Limit)
+	if err != nil {
+		ctx.JSON(http.StatusOK, Result{
+			Code: 5,
+			Msg:  "系统错误",
+		})
+		h.log.Error("查询回复失败", logger.Error(err),
+			logger.Int64("rootId", req.RootId))
+		return
+	}
+	ctx.JSON(http.StatusOK, Result{
+		Msg:  "成功",
+		Data: h.toVos(cmts),
+	})
+}
+
+func (h *CommentHandler) Delete(ctx *gin.Context) {
+	type Req struct {
+		Id int64 `json:"id"`
+	}
+	var req Req
+	if err := ctx.Bind(&req); err != nil {
+		h.log.Error("反序列化请求失败", logger.Error(err))
+		return
+	}
+
+	uc, ok := ctx.MustGet(jwt.KeyAccessClaims).(*jwt.AccessClaims)
+	if !ok {
+		ctx.JSON(http.StatusOK, Result{
+			Code: 5,
+			Msg:  "系统错误",
+		})
+		h.log.Error("获得用户会话信息失败")
+		return
+	}
+
+	err := h.svc.DeleteComment(ctx, uc.Uid, req.Id)
+	switch err {
+	case nil:
+		ctx.JSON(http.StatusOK, Result{
+			Msg: "删除成功",
+		})
+	case service.ErrCommentNotFound:
+		ctx.JSON(http.StatusOK, Result{
+			Code: 4,
+			Msg:  "评论不存在",
+		})
+	case service.ErrNoCommentPermission:
+		ctx.JSON(http.StatusOK, Result{
+			Code: 4,
+			Msg:  "输入有误",
+		})
+		h.log.Error("非法删除评论",
+			logger.Int64("uid", uc.Uid), logger.Int64("cid", req.Id))
+	default:
+		ctx.JSON(http.StatusOK, Result{
+			Code: 5,
+			Msg:  "系统错误",
+		})
+		h.log.Error("删除评论失败", logger.Error(err))
+	}
+}
+
+func (h *CommentHandler) toVos(cmts []domain.Comment) []CommentVo {
+	res := make([]CommentVo, 0, len(cmts))
+	for _, c := range cmts {
+		res = append(res, CommentVo{
+			Id:          c.Id,
+			Uid:         c.Commentator.Id,
+			Commentator: c.Commentator.Name,
+			Content:     c.Content,
+			RootId:      c.RootId,
+			ParentId:    c.ParentId,
+			ReplyCnt:    c.ReplyCnt,
+			Ctime:       c.Ctime.Format(time.DateTime),
+		})
+	}
+	return res
+}
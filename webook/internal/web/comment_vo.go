@@ -0,0 +1,21 @@
+package web
+
+type CommentReq struct {
+	// 文章 ID
+	BizId   int64  `json:"bizId"`
+	Content string `json:"content"`
+	// 回复的评论，直接评论文章的时候不传
+	ParentId int64 `json:"parentId"`
+}
+
+type CommentVo struct {
+	Id          int64  `json:"id"`
+	Uid         int64  `json:"uid"`
+	Commentator string `json:"commentator"`
+	Content     string `json:"content"`
+	RootId      int64  `json:"rootId"`
+	ParentId    int64  `json:"parentId"`
+	// 只有根评论有
+	ReplyCnt int64  `json:"replyCnt"`
+	Ctime    string `json:"ctime"`
+}
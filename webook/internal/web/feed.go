@@ -0,0 +1,98 @@
+package web
+
+import (
+	"gitee.com/geekbang/basic-go/webook/internal/service"
+	"gitee.com/geekbang/basic-go/webook/internal/web/jwt"
+	"gitee.com/geekbang/basic-go/webook/pkg/logger"
+	"github.com/gin-gonic/gin"
+	"net/http"
+	"time"
+)
+
+type FeedHandler struct {
+	svc service.FeedService
+	log logger.Logger
+}
+
+func NewFeedHandler(svc service.FeedService, log logger.Logger) *FeedHandler {
+	return &FeedHandler{
+		svc: svc,
+		log: log,
+	}
+}
+
+func (h *FeedHandler) RegisterHandlers(engine *gin.Engine) {
+	engine.POST("/feed", h.Feed)
+}
+
+// Feed 关注的人发表的文章
+func (h *FeedHandler) Feed(ctx *gin.Context) {
+	var req FeedReq
+	if err := ctx.Bind(&req); err != nil {
+		h.log.Error("反序列化请求失败", logger.Error(err))
+		return
+	}
+	if req.Limit <= 0 || req.Limit > 100 || req.Ctime < 0 || req.ArticleId < 0 {
+		ctx.JSON(http.StatusOK, Result{
+			Code: 4,
+			Msg:  "请求有误",
+		})
+		h.log.Error("参数有误", logger.Field{Key: "req", Value: req})
+		return
+	}
+
+	uc, ok := ctx.MustGet(jwt.KeyAccessClaims).(*jwt.AccessClaims)
+	if !ok {
+		ctx.JSON(http.StatusOK, Result{
+			Code: 5,
+			Msg:  "系统错误",
+		})
+		h.log.Error("获得用户会话信息失败")
+		return
+	}
+
+	var ctime time.Time
+	if req.Ctime > 0 {
+		ctime = time.UnixMilli(req.Ctime)
+	}
+	events, err := h.svc.GetFeed(ctx, uc.Uid, ctime, req.ArticleId, req.Limit)
+	if err != nil {
+		ctx.JSON(http.StatusOK, Result{
+			Code: 5,
+			Msg:  "系统错误",
+		})
+		h.log.Error("查询时间线失败", logger.Error(err), logger.Int64("uid", uc.Uid))
+		return
+	}
+
+	res := FeedVo{
+		Articles: make([]ArticleVo, 0, len(events)),
+		HasMore:  len(events) == req.Limit,
+	}
+	for _, evt := range events {
+		// 已经撤回或者删除了
+		if evt.Article.Id == 0 {
+			continue
+		}
+		art := evt.Article
+		res.Articles = append(res.Articles, ArticleVo{
+			Id:          art.Id,
+			Title:       art.Title,
+			Abstract:    art.Abstract(),
+			Author:      art.Author.Name,
+			AuthorId:    art.Author.Id,
+			PublishTime: evt.Ctime.Format(time.DateTime),
+			Tags:        art.Tags,
+		})
+	}
+	if len(events) > 0 {
+		// 游标用最后一条事件，不管文章还在不在
+		last := events[len(events)-1]
+		res.NextCtime = last.Ctime.UnixMilli()
+		res.NextArticleId = last.ArticleId
+	}
+	ctx.JSON(http.StatusOK, Result{
+		Msg:  "成功",
+		Data: res,
+	})
+}
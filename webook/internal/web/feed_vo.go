@@ -0,0 +1,15 @@
+package web
+
+type FeedReq struct {
+	// 上一页最后一条的游标，第一页不传
+	Ctime     int64 `json:"ctime"`
+	ArticleId int64 `json:"articleId"`
+	Limit     int   `json:"limit"`
+}
+
+type FeedVo struct {
+	Articles      []ArticleVo `json:"articles"`
+	NextCtime     int64       `json:"nextCtime"`
+	NextArticleId int64       `json:"nextArticleId"`
+	HasMore       bool        `json:"hasMore"`
+}
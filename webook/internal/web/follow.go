@@ -0,0 +1,239 @@
+package web
+
+import (
+	"context"
+	"gitee.com/geekbang/basic-go/webook/internal/domain"
+	"gitee.com/geekbang/basic-go/webook/internal/service"
+	"gitee.com/geekbang/basic-go/webook/internal/web/jwt"
+	"gitee.com/geekbang/basic-go/webook/pkg/logger"
+	"github.com/gin-gonic/gin"
+	"net/http"
+	"time"
+)
+
+type FollowHandler struct {
+	svc service.FollowService
+	log logger.Logger
+}
+
+func NewFollowHandler(svc service.FollowService, log logger.Logger) *FollowHandler {
+	return &FollowHandler{
+		svc: svc,
+		log: log,
+	}
+}
+
+func (h *FollowHandler) RegisterHandlers(engine *gin.Engine) {
+	g := engine.Group("/follow")
+	g.POST("/follow", h.Follow)
+	g.POST("/cancel", h.CancelFollow)
+	g.POST("/followees", h.Followees)
+	g.POST("/followers", h.Followers)
+	g.POST("/statics", h.Statics)
+}
+
+func (h *FollowHandler) Follow(ctx *gin.Context) {
+	var req FollowReq
+	if err := ctx.Bind(&req); err != nil {
+		h.log.Error("反序列化请求失败", logger.Error(err))
+		return
+	}
+
+	uc, ok := ctx.MustGet(jwt.KeyAccessClaims).(*jwt.AccessClaims)
+	if !ok {
+		ctx.JSON(http.StatusOK, Result{
+			Code: 5,
+			Msg:  "系统错误",
+		})
+		h.log.Error("获得用户会话信息失败")
+		return
+	}
+
+	err := h.svc.Follow(ctx, uc.Uid, req.Followee)
+	switch err {
+	case nil:
+		ctx.JSON(http.StatusOK, Result{
+			Msg: "关注成功",
+		})
+	case service.ErrFollowSelf:
+		ctx.JSON(http.StatusOK, Result{
+			Code: 4,
+			Msg:  "不能关注自己",
+		})
+	case service.ErrFolloweeNotFound:
+		ctx.JSON(http.StatusOK, Result{
+			Code: 4,
+			Msg:  "用户不存在",
+		})
+	default:
+		ctx.JSON(http.StatusOK, Result{
+			Code: 5,
+			Msg:  "系统错误",
+		})
+		h.log.Error("关注失败", logger.Error(err),
+			logger.Int64("follower", uc.Uid), logger.Int64("followee", req.Followee))
+	}
+}
+
+func (h *FollowHandler) CancelFollow(ctx *gin.Context) {
+	var req FollowReq
+	if err := ctx.Bind(&req); err != nil {
+		h.log.Error("反序列化请求失败", logger.Error(err))
+		return
+	}
+
+	uc, ok := ctx.MustGet(jwt.KeyAccessClaims).(*jwt.AccessClaims)
+	if !ok {
+		ctx.JSON(http.StatusOK, Result{
+			Code: 5,
+			Msg:  "系统错误",
+		})
+		h.log.Error("获得用户会话信息失败")
+		return
+	}
+
+	err := h.svc.CancelFollow(ctx, uc.Uid, req.Followee)
+	if err != nil {
+		ctx.JSON(http.StatusOK, Result{
+			Code: 5,
+			Msg:  "系统错误",
+		})
+		h.log.Error("取消关注失败", logger.Error(err),
+			logger.Int64("follower", uc.Uid), logger.Int64("followee", req.Followee))
+		return
+	}
+	ctx.JSON(http.StatusOK, Result{
+		Msg: "取消关注成功",
+	})
+}
+
+// Followees 某个用户关注的人，不传 uid 就是自己
+func (h *FollowHandler) Followees(ctx *gin.Context) {
+	h.list(ctx, h.svc.ListFollowees, func(r domain.FollowRelation) int64 {
+		return r.Followee
+	})
+}
+
+// Followers 某个用户的粉丝，不传 uid 就是自己
+func (h *FollowHandler) Followers(ctx *gin.Context) {
+	h.list(ctx, h.svc.ListFollowers, func(r domain.FollowRelation) int64 {
+		return r.Follower
+	})
+}
+
+func (h *FollowHandler) list(ctx *gin.Context,
+	fn func(ctx context.Context, uid int64, utime time.Time, id int64, limit int) ([]domain.FollowRelation, error),
+	other func(r domain.FollowRelation) int64) {
+	var req FollowListReq
+	if err := ctx.Bind(&req); err != nil {
+		h.log.Error("反序列化请求失败", logger.Error(err))
+		return
+	}
+	if req.Limit <= 0 || req.Limit > 100 || req.Utime < 0 || req.Id < 0 {
+		ctx.JSON(http.StatusOK, Result{
+			Code: 4,
+			Msg:  "请求有误",
+		})
+		h.log.Error("参数有误", logger.Field{Key: "req", Value: req})
+		return
+	}
+
+	uc, ok := ctx.MustGet(jwt.KeyAccessClaims).(*jwt.AccessClaims)
+	if !ok {
+		ctx.JSON(http.StatusOK, Result{
+			Code: 5,
+			Msg:  "系统错误",
+		})
+		h.log.Error("获得用户会话信息失败")
+		return
+	}
+	uid := req.Uid
+	if uid == 0 {
+		uid = uc.Uid
+	}
+
+	var utime time.Time
+	if req.Utime > 0 {
+		utime = time.UnixMilli(req.Utime)
+	}
+	relations, err := fn(ctx, uid, utime, req.Id, req.Limit)
+	if err != nil {
+		ctx.JSON(http.StatusOK, Result{
+			Code: 5,
+			Msg:  "系统错误",
+		})
+		h.log.Error("查询关注列表失败", logger.Error(err), logger.Int64("uid", uid))
+		return
+	}
+
+	res := FollowListVo{
+		Users:   make([]FollowVo, 0, len(relations)),
+		HasMore: len(relations) == req.Limit,
+	}
+	for _, r := range relations {
+		res.Users = append(res.Users, FollowVo{
+			Uid:   other(r),
+			Utime: r.Utime.Format(time.DateTime),
+		})
+	}
+	if len(relations) > 0 {
+		last := relations[len(relations)-1]
+		res.NextUtime = last.Utime.UnixMilli()
+		res.NextId = last.Id
+	}
+	ctx.JSON(http.StatusOK, Result{
+		Msg:  "成功",
+		Data: res,
+	})
+}
+
+// Statics 关注数和粉丝数，不传 uid 就是自己
+func (h *FollowHandler) Statics(ctx *gin.Context) {
+	type Req struct {
+		Uid int64 `json:"uid"`
+	}
+	var req Req
+	if err := ctx.Bind(&req); err != nil {
+		h.log.Error("反序列化请求失败", logger.Error(err))
+		return
+	}
+
+	uc, ok := ctx.MustGet(jwt.KeyAccessClaims).(*jwt.AccessClaims)
+	if !ok {
+		ctx.JSON(http.StatusOK, Result{
+			Code: 5,
+			Msg:  "系统错误",
+		})
+		h.log.Error("获得用户会话信息失败")
+		return
+	}
+	uid := req.Uid
+	if uid == 0 {
+		uid = uc.Uid
+	}
+
+	statics, err := h.svc.GetStatics(ctx, uid)
+	if err != nil {
+		ctx.JSON(http.StatusOK, Result{
+			Code: 5,
+			Msg:  "系统错误",
+		})
+		h.log.Error("查询关注统计失败", logger.Error(err), logger.Int64("uid", uid))
+		return
+	}
+	res := FollowStaticsVo{
+		Followers: statics.Followers,
+		Followees: statics.Followees,
+	}
+	if uid != uc.Uid {
+		res.Followed, err = h.svc.IsFollowing(ctx, uc.Uid, uid)
+		if err != nil {
+			h.log.Error("查询关注关系失败", logger.Error(err),
+				logger.Int64("follower", uc.Uid), logger.Int64("followee", uid))
+		}
+	}
+	ctx.JSON(http.StatusOK, Result{
+		Msg:  "成功",
+		Data: res,
+	})
+}
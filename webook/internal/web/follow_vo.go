@@ -0,0 +1,34 @@
+package web
+
+type FollowReq struct {
+	Followee int64 `json:"followee"`
+}
+
+type FollowListReq struct {
+	// 查谁的列表，不传就是自己
+	Uid int64 `json:"uid"`
+	// 上一页最后一条的游标，第一页不传
+	Utime int64 `json:"utime"`
+	Id    int64 `json:"id"`
+	Limit int   `json:"limit"`
+}
+
+type FollowVo struct {
+	Uid int64 `json:"uid"`
+	// 关注的时间
+	Utime string `json:"utime"`
+}
+
+type FollowListVo struct {
+	Users     []FollowVo `json:"users"`
+	NextUtime int64      `json:"nextUtime"`
+	NextId    int64      `json:"nextId"`
+	HasMore   bool       `json:"hasMore"`
+}
+
+type FollowStaticsVo struct {
+	Followers int64 `json:"followers"`
+	Followees int64 `json:"followees"`
+	// 当前用户是否关注了这个人
+	Followed bool `json:"followed"`
+}
@@ -0,0 +1,28 @@
+package web
+
+import (
+	"gitee.com/geekbang/basic-go/webook/internal/web/jwt"
+	"github.com/gin-gonic/gin"
+	"net/http"
+)
+
+var _ handler = (*JWKSHandler)(nil)
+
+// JWKSHandler 公开校验 access token 用的公钥，别的服务可以自己校验 webook 的 token。
+// refresh token 只有 webook 自己校验，不公开
+type JWKSHandler struct {
+	keys *jwt.KeySet
+}
+
+func NewJWKSHandler(cfg jwt.Config) *JWKSHandler {
+	return &JWKSHandler{keys: cfg.Access.Keys}
+}
+
+func (h *JWKSHandler) RegisterHandlers(engine *gin.Engine) {
+	engine.GET("/.well-known/jwks.json", h.JWKS)
+}
+
+func (h *JWKSHandler) JWKS(ctx *gin.Context) {
+	ctx.Header("Cache-Control", "public, max-age=300")
+	ctx.JSON(http.StatusOK, h.keys.JWKS())
+}
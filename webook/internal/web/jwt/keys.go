@@ -0,0 +1,178 @@
+package jwt
+
+import (
+	"crypto"
+	"crypto/ed25519"
+	"crypto/rsa"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"github.com/golang-jwt/jwt/v5"
+	"math/big"
+	"sort"
+)
+
+var ErrUnknownKey = errors.New("未知的 kid")
+
+// Key 签名和校验 token 用的 key，用 kid 区分。
+// 轮换的时候先把新 key 加进来，再把签名换成新 key，
+// 等旧 key 签发的 token 都过期了再删掉旧 key
+type Key struct {
+	Kid    string
+	Method jwt.SigningMethod
+	// signKey 为 nil 说明只能用来校验
+	signKey   any
+	verifyKey any
+}
+
+// NewHMACKey 对称的 key，HS256。密钥不会出现在 JWKS 里面，其他服务没法校验
+func NewHMACKey(kid string, secret string) (Key, error) {
+	if secret == "" {
+		return Key{}, fmt.Errorf("key %s 没有 secret", kid)
+	}
+	return Key{
+		Kid:       kid,
+		Method:    jwt.SigningMethodHS256,
+		signKey:   []byte(secret),
+		verifyKey: []byte(secret),
+	}, nil
+}
+
+// NewRSAKey RS256，都是 PEM 格式。有私钥的时候可以不传公钥，只有公钥的只能用来校验
+func NewRSAKey(kid string, privatePEM string, publicPEM string) (Key, error) {
+	key := Key{Kid: kid, Method: jwt.SigningMethodRS256}
+	if privatePEM != "" {
+		pk, err := jwt.ParseRSAPrivateKeyFromPEM([]byte(privatePEM))
+		if err != nil {
+			return Key{}, fmt.Errorf("key %s 私钥不对 %w", kid, err)
+		}
+		key.signKey = pk
+		key.verifyKey = &pk.PublicKey
+		return key, nil
+	}
+	pub, err := jwt.ParseRSAPublicKeyFromPEM([]byte(publicPEM))
+	if err != nil {
+		return Key{}, fmt.Errorf("key %s 公钥不对 %w", kid, err)
+	}
+	key.verifyKey = pub
+	return key, nil
+}
+
+// NewEdDSAKey Ed25519，用法和 NewRSAKey 一样
+func NewEdDSAKey(kid string, privatePEM string, publicPEM string) (Key, error) {
+	key := Key{Kid: kid, Method: jwt.SigningMethodEdDSA}
+	if privatePEM != "" {
+		pk, err := jwt.ParseEdPrivateKeyFromPEM([]byte(privatePEM))
+		if err != nil {
+			return Key{}, fmt.Errorf("key %s 私钥不对 %w", kid, err)
+		}
+		key.signKey = pk
+		key.verifyKey = pk.(crypto.Signer).Public()
+		return key, nil
+	}
+	pub, err := jwt.ParseEdPublicKeyFromPEM([]byte(publicPEM))
+	if err != nil {
+		return Key{}, fmt.Errorf("key %s 公钥不对 %w", kid, err)
+	}
+	key.verifyKey = pub
+	return key, nil
+}
+
+// KeySet 用一个 key 签名，所有的 key 都可以用来校验
+type KeySet struct {
+	signing Key
+	keys    map[string]Key
+}
+
+// NewKeySet signingKid 对应的 key 必须能签名
+func NewKeySet(signingKid string, keys ...Key) (*KeySet, error) {
+	ks := &KeySet{keys: make(map[string]Key, len(keys))}
+	for _, k := range keys {
+		if _, ok := ks.keys[k.Kid]; ok {
+			return nil, fmt.Errorf("kid %s 重复了", k.Kid)
+		}
+		ks.keys[k.Kid] = k
+	}
+	signing, ok := ks.keys[signingKid]
+	if !ok {
+		return nil, fmt.Errorf("签名用的 key %s 不存在", signingKid)
+	}
+	if signing.signKey == nil {
+		return nil, fmt.Errorf("签名用的 key %s 没有私钥", signingKid)
+	}
+	ks.signing = signing
+	return ks, nil
+}
+
+// Sign 用当前的 key 签名，header 里面带上 kid
+func (ks *KeySet) Sign(claims jwt.Claims) (string, error) {
+	token := jwt.NewWithClaims(ks.signing.Method, claims)
+	token.Header["kid"] = ks.signing.Kid
+	return token.SignedString(ks.signing.signKey)
+}
+
+// Keyfunc 给 jwt.ParseWithClaims 用，按照 kid 找 key，算法也要对得上
+func (ks *KeySet) Keyfunc(token *jwt.Token) (any, error) {
+	key := ks.signing
+	// 以前签发的 token 没有 kid，用签名的 key 校验
+	if kid, ok := token.Header["kid"]; ok {
+		kidStr, _ := kid.(string)
+		key, ok = ks.keys[kidStr]
+		if !ok {
+			return nil, ErrUnknownKey
+		}
+	}
+	if token.Method.Alg() != key.Method.Alg() {
+		return nil, fmt.Errorf("算法不对 %s", token.Method.Alg())
+	}
+	return key.verifyKey, nil
+}
+
+// JWK 只有公钥，RFC 7517
+type JWK struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid"`
+	Use string `json:"use"`
+	Alg string `json:"alg"`
+	// RSA
+	N string `json:"n,omitempty"`
+	E string `json:"e,omitempty"`
+	// Ed25519
+	Crv string `json:"crv,omitempty"`
+	X   string `json:"x,omitempty"`
+}
+
+type JWKS struct {
+	Keys []JWK `json:"keys"`
+}
+
+// JWKS 公开的公钥，HMAC 的 key 不能公开
+func (ks *KeySet) JWKS() JWKS {
+	res := JWKS{Keys: make([]JWK, 0, len(ks.keys))}
+	for _, k := range ks.keys {
+		switch pub := k.verifyKey.(type) {
+		case *rsa.PublicKey:
+			res.Keys = append(res.Keys, JWK{
+				Kty: "RSA",
+				Kid: k.Kid,
+				Use: "sig",
+				Alg: k.Method.Alg(),
+				N:   base64.RawURLEncoding.EncodeToString(pub.N.Bytes()),
+				E:   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(pub.E)).Bytes()),
+			})
+		case ed25519.PublicKey:
+			res.Keys = append(res.Keys, JWK{
+				Kty: "OKP",
+				Kid: k.Kid,
+				Use: "sig",
+				Alg: k.Method.Alg(),
+				Crv: "Ed25519",
+				X:   base64.RawURLEncoding.EncodeToString(pub),
+			})
+		}
+	}
+	sort.Slice(res.Keys, func(i, j int) bool {
+		return res.Keys[i].Kid < res.Keys[j].Kid
+	})
+	return res
+}
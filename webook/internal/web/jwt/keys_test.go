@@ -0,0 +1,76 @@
+package jwt
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"testing"
+	"time"
+)
+
+func TestKeySet_Rotation(t *testing.T) {
+	oldKey, err := NewHMACKey("old", "old-secret")
+	require.NoError(t, err)
+	pub, priv, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+	newKey, err := NewEdDSAKey("new", ed25519PEM(t, priv), "")
+	require.NoError(t, err)
+
+	before, err := NewKeySet("old", oldKey)
+	require.NoError(t, err)
+	oldToken, err := before.Sign(testClaims())
+	require.NoError(t, err)
+
+	// 轮换期间，旧 key 签发的 token 还能用
+	during, err := NewKeySet("new", oldKey, newKey)
+	require.NoError(t, err)
+	newToken, err := during.Sign(testClaims())
+	require.NoError(t, err)
+	for _, tokenStr := range []string{oldToken, newToken} {
+		_, err = jwt.ParseWithClaims(tokenStr, &AccessClaims{}, during.Keyfunc)
+		assert.NoError(t, err)
+	}
+
+	// 旧 key 删掉之后就不行了
+	after, err := NewKeySet("new", newKey)
+	require.NoError(t, err)
+	_, err = jwt.ParseWithClaims(oldToken, &AccessClaims{}, after.Keyfunc)
+	assert.ErrorIs(t, err, ErrUnknownKey)
+
+	// 只公开 EdDSA 的公钥
+	jwks := during.JWKS()
+	require.Len(t, jwks.Keys, 1)
+	assert.Equal(t, "new", jwks.Keys[0].Kid)
+	assert.Equal(t, base64.RawURLEncoding.EncodeToString(pub), jwks.Keys[0].X)
+}
+
+func TestNewKeySet_VerifyOnlySigningKey(t *testing.T) {
+	pub, _, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+	bs, err := x509.MarshalPKIXPublicKey(pub)
+	require.NoError(t, err)
+	key, err := NewEdDSAKey("pub", "", string(pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: bs})))
+	require.NoError(t, err)
+	_, err = NewKeySet("pub", key)
+	assert.Error(t, err)
+}
+
+func ed25519PEM(t *testing.T, priv ed25519.PrivateKey) string {
+	bs, err := x509.MarshalPKCS8PrivateKey(priv)
+	require.NoError(t, err)
+	return string(pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: bs}))
+}
+
+func testClaims() AccessClaims {
+	return AccessClaims{
+		Uid: 123,
+		RegisteredClaims: jwt.RegisteredClaims{
+			ExpiresAt: jwt.NewNumericDate(time.Now().Add(time.Minute)),
+		},
+	}
+}
@@ -3,6 +3,7 @@ package jwt
 import (
 	"errors"
 	"fmt"
+	"gitee.com/geekbang/basic-go/webook/internal/domain"
 	"github.com/gin-gonic/gin"
 	"github.com/golang-jwt/jwt/v5"
 	"github.com/google/uuid"
@@ -11,19 +12,45 @@ import (
 	"time"
 )
 
-// JWTKey 因为 JWT Key 不太可能变，所以可以直接写成常量
-// 也可以考虑做成依赖注入
+// 两种 token 的 aud 不一样，解析的时候会校验，access token 不能拿来刷新，反过来也不行
+const (
+	audienceAccess  = "webook:access"
+	audienceRefresh = "webook:refresh"
+)
+
+// bannedKey 被封禁的用户 ID 集合，只有正在封禁的用户，所以不会很大。
+// 数据库里面的状态才是准的，这里丢了的话，封禁的时候已经撤销了会话，access token 过期之后也就登录不了了
+const bannedKey = "users:banned"
+
 var (
-	JWTKey = []byte("moyn8y9abnd7q4zkq2m73yw8tu9j5ixm")
+	ErrUserBanned         = errors.New("用户已被封禁")
+	ErrRefreshTokenReused = errors.New("refresh token 被重复使用")
 )
 
+// TokenConfig 一种 token 的 key 和有效期
+type TokenConfig struct {
+	Keys       *KeySet
+	Expiration time.Duration
+}
+
+// Config access token 和 refresh token 分开配置，用不同的 key。
+// 退出登录的 ssid 要记录 refresh token 的有效期那么久
+type Config struct {
+	Access  TokenConfig
+	Refresh TokenConfig
+}
+
 type JWTHandler struct {
-	cmd redis.Cmdable
+	cmd     redis.Cmdable
+	access  TokenConfig
+	refresh TokenConfig
 }
 
-func NewJWTHandler(cmd redis.Cmdable) Handler {
+func NewJWTHandler(cmd redis.Cmdable, cfg Config) Handler {
 	return &JWTHandler{
-		cmd: cmd,
+		cmd:     cmd,
+		access:  cfg.Access,
+		refresh: cfg.Refresh,
 	}
 }
 
@@ -31,50 +58,73 @@ func (j *JWTHandler) ClearToken(ctx *gin.Context) error {
 	ctx.Header("x-jwt-token", "")
 	ctx.Header("x-refresh-token", "")
 	claims := ctx.MustGet(KeyAccessClaims).(*AccessClaims)
-	return j.cmd.Set(ctx, j.getRedisKey(claims.Ssid), "", time.Hour*24*7).Err()
+	return j.cmd.Set(ctx, j.getRedisKey(claims.Ssid), "", j.refresh.Expiration).Err()
 }
 
-func (j *JWTHandler) CheckSession(ctx *gin.Context, ssid string) error {
-	val, err := j.cmd.Exists(ctx, fmt.Sprintf("users:ssid:%s", ssid)).Result()
-	switch err {
-	case redis.Nil:
-		return nil
-	case nil:
-		if val == 0 {
-			return nil
-		}
-		return errors.New("session 已经无效了")
-	default:
+func (j *JWTHandler) CheckSession(ctx *gin.Context, uid int64, ssid string) error {
+	// 每个请求都要检查，所以两个检查放在一个 pipeline 里面，只访问一次 Redis
+	pipe := j.cmd.Pipeline()
+	exists := pipe.Exists(ctx, j.getRedisKey(ssid))
+	banned := pipe.SIsMember(ctx, bannedKey, uid)
+	_, err := pipe.Exec(ctx)
+	if err != nil && err != redis.Nil {
 		return err
 	}
+	if banned.Val() {
+		return ErrUserBanned
+	}
+	if exists.Val() > 0 {
+		return errors.New("session 已经无效了")
+	}
+	return nil
+}
+
+func (j *JWTHandler) BanUser(ctx *gin.Context, uid int64) error {
+	return j.cmd.SAdd(ctx, bannedKey, uid).Err()
+}
+
+func (j *JWTHandler) UnbanUser(ctx *gin.Context, uid int64) error {
+	return j.cmd.SRem(ctx, bannedKey, uid).Err()
 }
 
 func (j *JWTHandler) getRedisKey(ssid string) string {
 	return fmt.Sprintf("users:ssid:%s", ssid)
 }
 
-func (j *JWTHandler) SetLoginToken(ctx *gin.Context, uid int64) error {
+func (j *JWTHandler) SetLoginToken(ctx *gin.Context, uid int64, roles []domain.Role, method domain.LoginMethod) error {
 	ssid := uuid.New().String()
-	err := j.SetAccessToken(ctx, uid, ssid)
+	err := j.SetAccessToken(ctx, uid, ssid, roles)
 	if err != nil {
 		return err
 	}
 	err = j.SetRefreshToken(ctx, uid, ssid)
-	return err
+	if err != nil {
+		return err
+	}
+	now := time.Now().UnixMilli()
+	return j.saveSession(ctx, uid, Session{
+		Ssid:       ssid,
+		UserAgent:  ctx.Request.UserAgent(),
+		IP:         ctx.ClientIP(),
+		Method:     method,
+		LoginTime:  now,
+		LastActive: now,
+	})
 }
 
-func (j *JWTHandler) SetAccessToken(ctx *gin.Context, uid int64, ssid string) error {
+func (j *JWTHandler) SetAccessToken(ctx *gin.Context, uid int64, ssid string, roles []domain.Role) error {
 	claims := AccessClaims{
 		Uid:       uid,
 		Ssid:      ssid,
 		UserAgent: ctx.Request.UserAgent(),
+		Roles:     roles,
+		Perms:     domain.Permissions(roles),
 		RegisteredClaims: jwt.RegisteredClaims{
-			// 演示目的设置为一分钟过期
-			ExpiresAt: jwt.NewNumericDate(time.Now().Add(time.Minute * 30)),
+			Audience:  jwt.ClaimStrings{audienceAccess},
+			ExpiresAt: jwt.NewNumericDate(time.Now().Add(j.access.Expiration)),
 		},
 	}
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
-	tokenStr, err := token.SignedString(JWTKey)
+	tokenStr, err := j.access.Keys.Sign(claims)
 	if err != nil {
 		return err
 	}
@@ -88,11 +138,12 @@ func (j *JWTHandler) SetRefreshToken(ctx *gin.Context, uid int64, ssid string) e
 		Ssid:      ssid,
 		UserAgent: ctx.Request.UserAgent(),
 		RegisteredClaims: jwt.RegisteredClaims{
-			ExpiresAt: jwt.NewNumericDate(time.Now().Add(time.Hour * 24 * 7)),
+			ID:        uuid.New().String(),
+			Audience:  jwt.ClaimStrings{audienceRefresh},
+			ExpiresAt: jwt.NewNumericDate(time.Now().Add(j.refresh.Expiration)),
 		},
 	}
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
-	tokenStr, err := token.SignedString(JWTKey)
+	tokenStr, err := j.refresh.Keys.Sign(claims)
 	if err != nil {
 		return err
 	}
@@ -100,15 +151,47 @@ func (j *JWTHandler) SetRefreshToken(ctx *gin.Context, uid int64, ssid string) e
 	return nil
 }
 
+func (j *JWTHandler) ConsumeRefreshToken(ctx *gin.Context, claims RefreshClaims) error {
+	if claims.ID == "" {
+		// 升级之前签发的 refresh token 没有 ID，没办法判断有没有用过，只能重新登录
+		return errors.New("refresh token 没有 ID")
+	}
+	// 标记只需要保留到 token 过期，过期之后本来也用不了
+	expiration := j.refresh.Expiration
+	if claims.ExpiresAt != nil {
+		expiration = time.Until(claims.ExpiresAt.Time)
+	}
+	if expiration <= 0 {
+		return errors.New("refresh token 已经过期")
+	}
+	ok, err := j.cmd.SetNX(ctx, j.getUsedRefreshKey(claims.ID), claims.Ssid, expiration).Result()
+	if err != nil {
+		return err
+	}
+	if ok {
+		return nil
+	}
+	// 正常的客户端每个 refresh token 只会用一次，
+	// 分不清是谁用的，所以这个会话直接失效，偷了 token 的人和用户都要重新登录
+	err = j.cmd.Set(ctx, j.getRedisKey(claims.Ssid), "", j.refresh.Expiration).Err()
+	if err != nil {
+		return err
+	}
+	return ErrRefreshTokenReused
+}
+
+func (j *JWTHandler) getUsedRefreshKey(id string) string {
+	return fmt.Sprintf("users:refresh_used:%s", id)
+}
+
 func (j *JWTHandler) ExtractAccessClaims(ctx *gin.Context) (AccessClaims, error) {
 	uc := AccessClaims{}
 	tokenStr := j.extractTokenStr(ctx)
 	if tokenStr == "" {
 		return uc, errors.New("AccessToken 不存在")
 	}
-	token, err := jwt.ParseWithClaims(tokenStr, &uc, func(token *jwt.Token) (interface{}, error) {
-		return JWTKey, nil
-	})
+	token, err := jwt.ParseWithClaims(tokenStr, &uc, j.access.Keys.Keyfunc,
+		jwt.WithAudience(audienceAccess), jwt.WithExpirationRequired())
 	if err != nil {
 		return uc, err
 	}
@@ -123,12 +206,11 @@ func (j *JWTHandler) ExtractRefreshClaims(ctx *gin.Context) (RefreshClaims, erro
 	uc := RefreshClaims{}
 	tokenStr := j.extractTokenStr(ctx)
 	if tokenStr == "" {
-		return uc, errors.New("AccessToken 不存在")
+		return uc, errors.New("RefreshToken 不存在")
 	}
 
-	token, err := jwt.ParseWithClaims(tokenStr, &uc, func(token *jwt.Token) (interface{}, error) {
-		return JWTKey, nil
-	})
+	token, err := jwt.ParseWithClaims(tokenStr, &uc, j.refresh.Keys.Keyfunc,
+		jwt.WithAudience(audienceRefresh), jwt.WithExpirationRequired())
 	if err != nil {
 		return uc, err
 	}
@@ -0,0 +1,139 @@
+package jwt
+
+import (
+	"context"
+	"gitee.com/geekbang/basic-go/webook/internal/repository/cache/redismocks"
+	"github.com/gin-gonic/gin"
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestJWTHandler_ConsumeRefreshToken(t *testing.T) {
+	testCases := []struct {
+		name string
+		mock func(ctrl *gomock.Controller) redis.Cmdable
+
+		wantErr error
+	}{
+		{
+			name: "第一次使用",
+			mock: func(ctrl *gomock.Controller) redis.Cmdable {
+				cmd := redismocks.NewMockCmdable(ctrl)
+				res := redis.NewBoolCmd(context.Background())
+				res.SetVal(true)
+				cmd.EXPECT().SetNX(gomock.Any(), "users:refresh_used:jti", "ssid", gomock.Any()).
+					Return(res)
+				return cmd
+			},
+		},
+		{
+			name: "重复使用，会话失效",
+			mock: func(ctrl *gomock.Controller) redis.Cmdable {
+				cmd := redismocks.NewMockCmdable(ctrl)
+				res := redis.NewBoolCmd(context.Background())
+				res.SetVal(false)
+				cmd.EXPECT().SetNX(gomock.Any(), "users:refresh_used:jti", "ssid", gomock.Any()).
+					Return(res)
+				setRes := redis.NewStatusCmd(context.Background())
+				setRes.SetVal("OK")
+				cmd.EXPECT().Set(gomock.Any(), "users:ssid:ssid", "", time.Hour*24*7).
+					Return(setRes)
+				return cmd
+			},
+			wantErr: ErrRefreshTokenReused,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+			hdl := NewJWTHandler(tc.mock(ctrl), Config{
+				Refresh: TokenConfig{Expiration: time.Hour * 24 * 7},
+			})
+			ctx, _ := gin.CreateTestContext(httptest.NewRecorder())
+			err := hdl.ConsumeRefreshToken(ctx, RefreshClaims{
+				Uid:  123,
+				Ssid: "ssid",
+				RegisteredClaims: jwt.RegisteredClaims{
+					ID:        "jti",
+					ExpiresAt: jwt.NewNumericDate(time.Now().Add(time.Hour)),
+				},
+			})
+			assert.Equal(t, tc.wantErr, err)
+		})
+	}
+}
+
+func TestJWTHandler_RevokeSession(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	cmd := redismocks.NewMockCmdable(ctrl)
+	res := redis.NewBoolCmd(context.Background())
+	res.SetVal(false)
+	cmd.EXPECT().HExists(gomock.Any(), "users:session_info:123", "other").Return(res)
+
+	// 别人的会话不能退出
+	hdl := NewJWTHandler(cmd, Config{})
+	ctx, _ := gin.CreateTestContext(httptest.NewRecorder())
+	err := hdl.RevokeSession(ctx, 123, "other")
+	assert.Equal(t, ErrSessionNotFound, err)
+}
+
+func TestJWTHandler_TouchSession(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	cmd := redismocks.NewMockCmdable(ctrl)
+	res := redis.NewStringCmd(context.Background())
+	res.SetErr(redis.Nil)
+	cmd.EXPECT().HGet(gomock.Any(), "users:session_info:123", "ssid").Return(res)
+
+	// 已经被清理掉的会话不算错误，也不会再加回去
+	hdl := NewJWTHandler(cmd, Config{})
+	ctx, _ := gin.CreateTestContext(httptest.NewRecorder())
+	err := hdl.TouchSession(ctx, 123, "ssid")
+	assert.NoError(t, err)
+}
+
+func TestJWTHandler_TokenType(t *testing.T) {
+	// 就算两种 token 配置成了同一套 key，aud 不对也解析不了
+	key, err := NewHMACKey("k1", "secret")
+	require.NoError(t, err)
+	keys, err := NewKeySet("k1", key)
+	require.NoError(t, err)
+	hdl := NewJWTHandler(nil, Config{
+		Access:  TokenConfig{Keys: keys, Expiration: time.Minute},
+		Refresh: TokenConfig{Keys: keys, Expiration: time.Hour},
+	})
+
+	recorder := httptest.NewRecorder()
+	ctx, _ := gin.CreateTestContext(recorder)
+	ctx.Request = httptest.NewRequest(http.MethodPost, "/", nil)
+	require.NoError(t, hdl.SetAccessToken(ctx, 123, "ssid", nil))
+	require.NoError(t, hdl.SetRefreshToken(ctx, 123, "ssid"))
+	accessToken := recorder.Header().Get("x-jwt-token")
+	refreshToken := recorder.Header().Get("x-refresh-token")
+
+	parse := func(token string) *gin.Context {
+		ctx, _ := gin.CreateTestContext(httptest.NewRecorder())
+		ctx.Request = httptest.NewRequest(http.MethodPost, "/", nil)
+		ctx.Request.Header.Set("Authorization", "Bearer "+token)
+		return ctx
+	}
+	_, err = hdl.ExtractAccessClaims(parse(accessToken))
+	assert.NoError(t, err)
+	_, err = hdl.ExtractRefreshClaims(parse(refreshToken))
+	assert.NoError(t, err)
+	// 拿错了 token 都不行
+	_, err = hdl.ExtractRefreshClaims(parse(accessToken))
+	assert.Error(t, err)
+	_, err = hdl.ExtractAccessClaims(parse(refreshToken))
+	assert.Error(t, err)
+}
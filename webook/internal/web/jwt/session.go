@@ -0,0 +1,142 @@
+package jwt
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/gin-gonic/gin"
+	"github.com/redis/go-redis/v9"
+	"sort"
+	"time"
+)
+
+var ErrSessionNotFound = errors.New("会话不存在")
+
+// getSessionsKey 用户所有的会话，hash 结构，field 是 ssid，value 是 Session 的 JSON
+func (j *JWTHandler) getSessionsKey(uid int64) string {
+	return fmt.Sprintf("users:session_info:%d", uid)
+}
+
+func (j *JWTHandler) saveSession(ctx *gin.Context, uid int64, s Session) error {
+	val, err := json.Marshal(s)
+	if err != nil {
+		return err
+	}
+	key := j.getSessionsKey(uid)
+	pipe := j.cmd.Pipeline()
+	pipe.HSet(ctx, key, s.Ssid, val)
+	// 最后一次活跃之后，所有的 refresh token 都过期了，这些会话也就没用了
+	pipe.Expire(ctx, key, j.refresh.Expiration)
+	_, err = pipe.Exec(ctx)
+	return err
+}
+
+func (j *JWTHandler) TouchSession(ctx *gin.Context, uid int64, ssid string) error {
+	val, err := j.cmd.HGet(ctx, j.getSessionsKey(uid), ssid).Bytes()
+	if err == redis.Nil {
+		// 会话已经被清理出列表了，不用再加回去
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	var s Session
+	if err = json.Unmarshal(val, &s); err != nil {
+		return err
+	}
+	s.LastActive = time.Now().UnixMilli()
+	return j.saveSession(ctx, uid, s)
+}
+
+func (j *JWTHandler) ListSessions(ctx *gin.Context, uid int64) ([]Session, error) {
+	key := j.getSessionsKey(uid)
+	vals, err := j.cmd.HGetAll(ctx, key).Result()
+	if err != nil {
+		return nil, err
+	}
+	sessions := make([]Session, 0, len(vals))
+	for _, val := range vals {
+		var s Session
+		if err = json.Unmarshal([]byte(val), &s); err != nil {
+			return nil, err
+		}
+		sessions = append(sessions, s)
+	}
+	// 退出登录只是记录了 ssid，这里顺便把已经退出和已经过期的清理掉
+	pipe := j.cmd.Pipeline()
+	revoked := make([]*redis.IntCmd, 0, len(sessions))
+	for _, s := range sessions {
+		revoked = append(revoked, pipe.Exists(ctx, j.getRedisKey(s.Ssid)))
+	}
+	if _, err = pipe.Exec(ctx); err != nil {
+		return nil, err
+	}
+	deadline := time.Now().Add(-j.refresh.Expiration).UnixMilli()
+	res := make([]Session, 0, len(sessions))
+	var dead []string
+	for i, s := range sessions {
+		if revoked[i].Val() > 0 || s.LastActive < deadline {
+			dead = append(dead, s.Ssid)
+			continue
+		}
+		res = append(res, s)
+	}
+	if len(dead) > 0 {
+		if err = j.cmd.HDel(ctx, key, dead...).Err(); err != nil {
+			return nil, err
+		}
+	}
+	sort.Slice(res, func(i, k int) bool {
+		return res[i].LastActive > res[k].LastActive
+	})
+	return res, nil
+}
+
+func (j *JWTHandler) RevokeSession(ctx *gin.Context, uid int64, ssid string) error {
+	key := j.getSessionsKey(uid)
+	// 先确认是自己的会话，不然可以让别人的会话失效
+	ok, err := j.cmd.HExists(ctx, key, ssid).Result()
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return ErrSessionNotFound
+	}
+	pipe := j.cmd.Pipeline()
+	pipe.Set(ctx, j.getRedisKey(ssid), "", j.refresh.Expiration)
+	pipe.HDel(ctx, key, ssid)
+	_, err = pipe.Exec(ctx)
+	return err
+}
+
+func (j *JWTHandler) RevokeOtherSessions(ctx *gin.Context, uid int64, ssid string) error {
+	return j.revokeSessions(ctx, uid, ssid)
+}
+
+func (j *JWTHandler) RevokeSessions(ctx *gin.Context, uid int64) error {
+	return j.revokeSessions(ctx, uid, "")
+}
+
+// revokeSessions keep 不为空的时候保留这个会话
+func (j *JWTHandler) revokeSessions(ctx *gin.Context, uid int64, keep string) error {
+	key := j.getSessionsKey(uid)
+	ssids, err := j.cmd.HKeys(ctx, key).Result()
+	if err != nil {
+		return err
+	}
+	var revoked []string
+	pipe := j.cmd.Pipeline()
+	for _, ssid := range ssids {
+		if ssid == keep {
+			continue
+		}
+		pipe.Set(ctx, j.getRedisKey(ssid), "", j.refresh.Expiration)
+		revoked = append(revoked, ssid)
+	}
+	if len(revoked) == 0 {
+		return nil
+	}
+	pipe.HDel(ctx, key, revoked...)
+	_, err = pipe.Exec(ctx)
+	return err
+}
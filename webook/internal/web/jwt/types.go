@@ -1,15 +1,37 @@
 package jwt
 
 import (
+	"gitee.com/geekbang/basic-go/webook/internal/domain"
 	"github.com/gin-gonic/gin"
 	"github.com/golang-jwt/jwt/v5"
+	"slices"
 )
 
 type Handler interface {
-	SetLoginToken(ctx *gin.Context, uid int64) error
-	SetAccessToken(ctx *gin.Context, uid int64, ssid string) error
+	// SetLoginToken roles 和对应的权限都会放进 access token 里面，同时记录会话的信息
+	SetLoginToken(ctx *gin.Context, uid int64, roles []domain.Role, method domain.LoginMethod) error
+	SetAccessToken(ctx *gin.Context, uid int64, ssid string, roles []domain.Role) error
+	// SetRefreshToken 每个 refresh token 都有自己的 ID，只能用一次
+	SetRefreshToken(ctx *gin.Context, uid int64, ssid string) error
+	// ConsumeRefreshToken 把 refresh token 标记成用过了。
+	// 用过的 token 又拿来刷新，说明 token 泄露了，整个会话都会失效，返回 ErrRefreshTokenReused
+	ConsumeRefreshToken(ctx *gin.Context, claims RefreshClaims) error
 	ClearToken(ctx *gin.Context) error
-	CheckSession(ctx *gin.Context, ssid string) error
+	// CheckSession 会话已经退出或者用户被封禁了都返回 error，封禁的是 ErrUserBanned
+	CheckSession(ctx *gin.Context, uid int64, ssid string) error
+	// BanUser 加入封禁名单，没有过期的 access token 也马上不能用了
+	BanUser(ctx *gin.Context, uid int64) error
+	UnbanUser(ctx *gin.Context, uid int64) error
+	// RevokeSessions 让用户所有登录的会话都失效，比如说重置密码之后
+	RevokeSessions(ctx *gin.Context, uid int64) error
+	// ListSessions 用户还没有退出的会话，也就是登录了的设备
+	ListSessions(ctx *gin.Context, uid int64) ([]Session, error)
+	// TouchSession 刷新 token 的时候更新最后活跃的时间，会话已经不在列表里面的时候什么也不做
+	TouchSession(ctx *gin.Context, uid int64, ssid string) error
+	// RevokeSession 退出某一个会话，不是这个用户的会话返回 ErrSessionNotFound
+	RevokeSession(ctx *gin.Context, uid int64, ssid string) error
+	// RevokeOtherSessions 退出除了 ssid 以外的所有会话
+	RevokeOtherSessions(ctx *gin.Context, uid int64, ssid string) error
 	ExtractAccessClaims(ctx *gin.Context) (AccessClaims, error)
 	ExtractRefreshClaims(ctx *gin.Context) (RefreshClaims, error)
 }
@@ -19,12 +41,30 @@ type AccessClaims struct {
 	Uid       int64
 	Ssid      string
 	UserAgent string
+	Roles     []domain.Role       `json:",omitempty"`
+	Perms     []domain.Permission `json:",omitempty"`
 	jwt.RegisteredClaims
 }
 
+func (c *AccessClaims) HasPermission(perm domain.Permission) bool {
+	return slices.Contains(c.Perms, perm)
+}
+
 type RefreshClaims struct {
 	Uid       int64
 	Ssid      string
 	UserAgent string
 	jwt.RegisteredClaims
 }
+
+// Session 一次登录，每个设备一个
+type Session struct {
+	Ssid      string
+	UserAgent string
+	IP        string
+	Method    domain.LoginMethod
+	// 毫秒数
+	LoginTime int64
+	// 最后一次刷新 token 的时间，毫秒数。每个请求都更新的话太贵了
+	LastActive int64
+}
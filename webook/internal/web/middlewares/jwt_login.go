@@ -55,7 +55,11 @@ func (j *JWTLoginMiddlewareBuilder) Build() gin.HandlerFunc {
 			return
 		}
 
-		err = j.CheckSession(ctx, uc.Ssid)
+		err = j.CheckSession(ctx, uc.Uid, uc.Ssid)
+		if err == ijwt.ErrUserBanned {
+			ctx.AbortWithStatus(http.StatusForbidden)
+			return
+		}
 		if err != nil {
 			// 要么 redis 有问题，要么已经退出登录
 			ctx.AbortWithStatus(http.StatusUnauthorized)
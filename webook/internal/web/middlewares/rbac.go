@@ -0,0 +1,42 @@
+package middlewares
+
+import (
+	"gitee.com/geekbang/basic-go/webook/internal/domain"
+	ijwt "gitee.com/geekbang/basic-go/webook/internal/web/jwt"
+	"github.com/gin-gonic/gin"
+	"net/http"
+)
+
+// RBACMiddlewareBuilder 校验 access token 里面的权限，
+// 要放在 JWTLoginMiddlewareBuilder 后面
+type RBACMiddlewareBuilder struct {
+	perms []domain.Permission
+}
+
+// NewRBACMiddlewareBuilder perms 要全部都有才能访问
+func NewRBACMiddlewareBuilder(perms ...domain.Permission) *RBACMiddlewareBuilder {
+	return &RBACMiddlewareBuilder{
+		perms: perms,
+	}
+}
+
+func (b *RBACMiddlewareBuilder) Build() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		val, ok := ctx.Get(ijwt.KeyAccessClaims)
+		if !ok {
+			ctx.AbortWithStatus(http.StatusUnauthorized)
+			return
+		}
+		claims, ok := val.(*ijwt.AccessClaims)
+		if !ok {
+			ctx.AbortWithStatus(http.StatusUnauthorized)
+			return
+		}
+		for _, perm := range b.perms {
+			if !claims.HasPermission(perm) {
+				ctx.AbortWithStatus(http.StatusForbidden)
+				return
+			}
+		}
+	}
+}
@@ -7,3 +7,6 @@ type Result struct {
 	Msg  string `json:"msg"`
 	Data any    `json:"data"`
 }
+
+// codeUserBanned 账号被封禁，前端要提示联系管理员，不能当成普通的登录失败
+const codeUserBanned = 6
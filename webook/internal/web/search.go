@@ -0,0 +1,72 @@
+package web
+
+import (
+	"gitee.com/geekbang/basic-go/webook/internal/service"
+	"gitee.com/geekbang/basic-go/webook/pkg/logger"
+	"github.com/gin-gonic/gin"
+	"net/http"
+	"time"
+)
+
+type SearchHandler struct {
+	svc service.SearchService
+	log logger.Logger
+}
+
+func NewSearchHandler(svc service.SearchService, log logger.Logger) *SearchHandler {
+	return &SearchHandler{
+		svc: svc,
+		log: log,
+	}
+}
+
+func (h *SearchHandler) RegisterHandlers(engine *gin.Engine) {
+	g := engine.Group("/search")
+	g.POST("/articles", h.SearchArticles)
+}
+
+func (h *SearchHandler) SearchArticles(ctx *gin.Context) {
+	type Req struct {
+		Query  string `json:"query"`
+		Offset int    `json:"offset"`
+		Limit  int    `json:"limit"`
+	}
+	var req Req
+	if err := ctx.Bind(&req); err != nil {
+		h.log.Error("反序列化请求失败", logger.Error(err))
+		return
+	}
+	if req.Limit > 100 || req.Limit <= 0 || req.Offset < 0 || len([]rune(req.Query)) > 100 {
+		ctx.JSON(http.StatusOK, Result{
+			Code: 4,
+			Msg:  "请求有误",
+		})
+		h.log.Error("参数有误", logger.Field{Key: "req", Value: req})
+		return
+	}
+
+	arts, err := h.svc.SearchArticles(ctx, req.Query, req.Offset, req.Limit)
+	if err != nil {
+		ctx.JSON(http.StatusOK, Result{
+			Code: 5,
+			Msg:  "系统错误",
+		})
+		h.log.Error("搜索文章失败", logger.Error(err),
+			logger.String("query", req.Query))
+		return
+	}
+	res := make([]SearchArticleVo, 0, len(arts))
+	for _, art := range arts {
+		res = append(res, SearchArticleVo{
+			Id:       art.Id,
+			Title:    art.Title,
+			Abstract: art.Abstract,
+			Author:   art.Author.Name,
+			Utime:    art.Utime.Format(time.DateTime),
+		})
+	}
+	ctx.JSON(http.StatusOK, Result{
+		Msg:  "成功",
+		Data: res,
+	})
+}
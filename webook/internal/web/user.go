@@ -1,6 +1,8 @@
 package web
 
 import (
+	"context"
+	"fmt"
 	"gitee.com/geekbang/basic-go/webook/internal/domain"
 	"gitee.com/geekbang/basic-go/webook/internal/service"
 	"gitee.com/geekbang/basic-go/webook/internal/web/jwt"
@@ -9,10 +11,21 @@ import (
 	regexp "github.com/dlclark/regexp2"
 	"github.com/gin-gonic/gin"
 	"net/http"
+	"strings"
 	"time"
+	"unicode/utf8"
 )
 
-const biz = "login"
+const (
+	biz              = "login"
+	signupBiz        = "signup"
+	resetPasswordBiz = "reset_password"
+	bindPhoneBiz     = "bind_phone"
+	bindEmailBiz     = "bind_email"
+
+	maxNicknameLen = 32
+	maxAboutMeLen  = 256
+)
 
 var _ handler = (*UserHandler)(nil)
 
@@ -48,16 +61,32 @@ func (u *UserHandler) RegisterHandlers(engine *gin.Engine) {
 	ug := engine.Group("/users")
 
 	ug.POST("/signup", u.SignUp)
+	ug.POST("/signup/confirm", u.SignUpConfirm)
 	//ug.POST("/login", u.Login)
 	//ug.GET("/profile", u.Profile)
 
 	ug.POST("/login", u.LoginJWT)
 	ug.GET("/profile", u.ProfileJWT)
-	//ug.POST("/edit", u.Edit)
+	ug.POST("/edit", u.Edit)
 	ug.POST("/login_sms/code/send", u.SendLoginSMSCode)
 	ug.POST("/login_sms", u.LoginSMS)
 	ug.POST("/refresh_token", u.RefreshToken)
 	ug.POST("/logout", u.LogoutJWT)
+	ug.POST("/reset_password/code/send", u.SendResetPasswordCode)
+	ug.POST("/reset_password/verify", u.VerifyResetPasswordCode)
+	ug.POST("/reset_password", u.ResetPassword)
+	ug.POST("/change_password", u.ChangePassword)
+	ug.POST("/phone/code/send", u.SendBindPhoneCode)
+	ug.POST("/phone/bind", u.BindPhone)
+	ug.POST("/email/code/send", u.SendBindEmailCode)
+	ug.POST("/email/bind", u.BindEmail)
+	ug.GET("/login_methods", u.LoginMethods)
+	ug.POST("/login_methods/unlink", u.UnlinkLoginMethod)
+	ug.POST("/deactivate", u.Deactivate)
+	ug.GET("/export", u.Export)
+	ug.GET("/sessions", u.Sessions)
+	ug.POST("/sessions/logout", u.LogoutSession)
+	ug.POST("/sessions/logout_others", u.LogoutOtherSessions)
 }
 
 func (u *UserHandler) SignUp(ctx *gin.Context) {
@@ -110,17 +139,138 @@ func (u *UserHandler) SignUp(ctx *gin.Context) {
 		ctx.String(http.StatusOK, "手机/邮箱已注册")
 		return
 	case nil:
-		ctx.String(http.StatusOK, "注册成功")
-		return
 	default:
 		u.log.Error("用户注册失败", logger.Error(err))
 		ctx.String(http.StatusOK, "系统错误")
+		return
+	}
+
+	// 要确认了验证码才能登录
+	err = u.codeSvc.Send(ctx, signupBiz, service.CodeChannelEmail, req.Email)
+	switch err {
+	case nil:
+		// 验证码发出去了才暂存密码，保证密码和邮件里面的验证码是同一次注册的
+		if err = u.svc.SetSignUpPassword(ctx, req.Email, req.Password); err != nil {
+			u.log.Error("暂存注册密码失败", logger.Error(err))
+			ctx.String(http.StatusOK, "系统错误")
+			return
+		}
+		ctx.String(http.StatusOK, "注册成功，请输入邮件里面的验证码")
+	case service.ErrCodeSendTooMany:
+		ctx.String(http.StatusOK, "注册成功，验证码发送太频繁，请稍后再试")
+	default:
+		u.log.Error("发送注册验证码失败", logger.Error(err))
+		ctx.String(http.StatusOK, "注册成功，验证码发送失败，请重新注册")
+	}
+}
+
+// SignUpConfirm 确认邮箱注册的验证码，确认之后才能登录
+func (u *UserHandler) SignUpConfirm(ctx *gin.Context) {
+	type Req struct {
+		Email string `json:"email"`
+		Code  string `json:"code"`
+	}
+	var req Req
+	if err := ctx.Bind(&req); err != nil {
+		return
+	}
+
+	ok, err := u.codeSvc.Verify(ctx, signupBiz, service.CodeChannelEmail, req.Email, req.Code)
+	if err != nil {
+		u.log.Error("校验注册验证码失败", logger.Error(err))
+		ctx.JSON(http.StatusOK, Result{
+			Code: 5,
+			Msg:  "系统错误",
+		})
+		return
+	}
+	if !ok {
+		ctx.JSON(http.StatusOK, Result{
+			Code: 4,
+			Msg:  "验证码有误",
+		})
+		return
 	}
 
+	err = u.svc.ActivateEmail(ctx, req.Email)
+	if err != nil {
+		u.log.Error("激活邮箱失败", logger.Error(err))
+		ctx.JSON(http.StatusOK, Result{
+			Code: 5,
+			Msg:  "系统错误",
+		})
+		return
+	}
+	ctx.JSON(http.StatusOK, Result{
+		Msg: "注册成功",
+	})
 }
 
-func (u *UserHandler) Edit(context *gin.Context) {
-	panic("implement me")
+func (u *UserHandler) Edit(ctx *gin.Context) {
+	type Req struct {
+		Nickname string `json:"nickname"`
+		// 2006-01-02 格式，不传表示清空
+		Birthday string `json:"birthday"`
+		AboutMe  string `json:"aboutMe"`
+	}
+	var req Req
+	if err := ctx.Bind(&req); err != nil {
+		return
+	}
+
+	req.Nickname = strings.TrimSpace(req.Nickname)
+	if req.Nickname == "" || utf8.RuneCountInString(req.Nickname) > maxNicknameLen {
+		ctx.JSON(http.StatusOK, Result{
+			Code: 4,
+			Msg:  "昵称不能为空，并且不能超过 32 个字符",
+		})
+		return
+	}
+	if utf8.RuneCountInString(req.AboutMe) > maxAboutMeLen {
+		ctx.JSON(http.StatusOK, Result{
+			Code: 4,
+			Msg:  "个人简介不能超过 256 个字符",
+		})
+		return
+	}
+	var birthday time.Time
+	if req.Birthday != "" {
+		var err error
+		birthday, err = time.ParseInLocation(time.DateOnly, req.Birthday, time.Local)
+		if err != nil || birthday.After(time.Now()) || birthday.Year() < 1900 {
+			ctx.JSON(http.StatusOK, Result{
+				Code: 4,
+				Msg:  "生日格式不对",
+			})
+			return
+		}
+	}
+
+	claims, ok := ctx.MustGet(jwt.KeyAccessClaims).(*jwt.AccessClaims)
+	if !ok {
+		ctx.JSON(http.StatusOK, Result{
+			Code: 5,
+			Msg:  "系统错误",
+		})
+		return
+	}
+	err := u.svc.UpdateNonSensitiveInfo(ctx, domain.User{
+		Id:       claims.Uid,
+		Nickname: req.Nickname,
+		Birthday: birthday,
+		AboutMe:  req.AboutMe,
+	})
+	if err != nil {
+		u.log.Error("修改用户信息失败", logger.Error(err), logger.Int64("uid", claims.Uid))
+		ctx.JSON(http.StatusOK, Result{
+			Code: 5,
+			Msg:  "系统错误",
+		})
+		return
+	}
+	ctx.JSON(http.StatusOK, Result{
+		Msg: "修改成功",
+	})
 }
 
 func (u *UserHandler) Login(ctx *gin.Context) {
@@ -140,6 +290,17 @@ func (u *UserHandler) Login(ctx *gin.Context) {
 		ctx.String(http.StatusOK, "邮箱或者密码错误")
 		return
 	}
+	if err == service.ErrUserNotActivated {
+		ctx.String(http.StatusOK, "请先输入邮件里面的验证码完成注册")
+		return
+	}
+	if err == service.ErrUserBanned {
+		ctx.JSON(http.StatusOK, Result{
+			Code: codeUserBanned,
+			Msg:  "账号已被封禁",
+		})
+		return
+	}
 
 	if err != nil {
 		ctx.String(http.StatusOK, "系统错误")
@@ -195,22 +356,48 @@ func (u *UserHandler) LoginJWT(ctx *gin.Context) {
 		ctx.String(http.StatusOK, "邮箱或者密码错误")
 		return
 	}
+	if err == service.ErrUserNotActivated {
+		ctx.String(http.StatusOK, "请先输入邮件里面的验证码完成注册")
+		return
+	}
+	if err == service.ErrUserBanned {
+		ctx.JSON(http.StatusOK, Result{
+			Code: codeUserBanned,
+			Msg:  "账号已被封禁",
+		})
+		return
+	}
 
 	if err != nil {
 		ctx.String(http.StatusOK, "系统错误")
 		return
 	}
 
-	if err = u.SetLoginToken(ctx, user.Id); err != nil {
+	if err = setLoginToken(ctx, u.svc, u.Handler, user.Id, domain.LoginMethodEmail); err != nil {
 		ctx.String(http.StatusOK, "系统错误")
 		return
 	}
 	ctx.String(http.StatusOK, "登录成功")
 }
 
+// setLoginToken 查出用户的角色，和 token 一起设置好
+func setLoginToken(ctx *gin.Context, svc service.UserService, hdl jwt.Handler,
+	uid int64, method domain.LoginMethod) error {
+	roles, err := svc.Roles(ctx, uid)
+	if err != nil {
+		return err
+	}
+	return hdl.SetLoginToken(ctx, uid, roles, method)
+}
+
 func (u *UserHandler) ProfileJWT(ctx *gin.Context) {
 	type Resp struct {
-		Email string `json:"email"`
+		Email    string `json:"email"`
+		Phone    string `json:"phone"`
+		Nickname string `json:"nickname"`
+		Birthday string `json:"birthday"`
+		AboutMe  string `json:"aboutMe"`
+		Ctime    string `json:"ctime"`
 	}
 
 	claims, ok := ctx.MustGet(jwt.KeyAccessClaims).(*jwt.AccessClaims)
@@ -226,7 +413,17 @@ func (u *UserHandler) ProfileJWT(ctx *gin.Context) {
 		ctx.String(http.StatusOK, "系统错误")
 		return
 	}
-	ctx.JSON(http.StatusOK, Resp{Email: user.Email})
+	resp := Resp{
+		Email:    user.Email,
+		Phone:    user.Phone,
+		Nickname: user.Nickname,
+		AboutMe:  user.AboutMe,
+		Ctime:    user.Ctime.Format(time.DateTime),
+	}
+	if !user.Birthday.IsZero() {
+		resp.Birthday = user.Birthday.Format(time.DateOnly)
+	}
+	ctx.JSON(http.StatusOK, resp)
 
 }
 
@@ -253,7 +450,7 @@ func (u *UserHandler) SendLoginSMSCode(ctx *gin.Context) {
 		return
 	}
 
-	err := u.codeSvc.Send(ctx, biz, req.Phone)
+	err := u.codeSvc.Send(ctx, biz, service.CodeChannelSMS, req.Phone)
 	switch err {
 	case nil:
 		ctx.JSON(http.StatusOK, Result{
@@ -283,7 +480,7 @@ func (u *UserHandler) LoginSMS(ctx *gin.Context) {
 	}
 
 	// 这边，可以加上各种校验
-	ok, err := u.codeSvc.Verify(ctx, biz, req.Phone, req.Code)
+	ok, err := u.codeSvc.Verify(ctx, biz, service.CodeChannelSMS, req.Phone, req.Code)
 	if err != nil {
 		ctx.JSON(http.StatusOK, Result{
 			Code: 5,
@@ -302,6 +499,13 @@ func (u *UserHandler) LoginSMS(ctx *gin.Context) {
 	// 我这个手机号，会不会是一个新用户呢？
 	// 这样子
 	user, err := u.svc.FindOrCreate(ctx, req.Phone)
+	if err == service.ErrUserBanned {
+		ctx.JSON(http.StatusOK, Result{
+			Code: codeUserBanned,
+			Msg:  "账号已被封禁",
+		})
+		return
+	}
 	if err != nil {
 		ctx.JSON(http.StatusOK, Result{
 			Code: 5,
@@ -312,7 +516,7 @@ func (u *UserHandler) LoginSMS(ctx *gin.Context) {
 
 	// 这边要怎么办呢？
 	// 从哪来？
-	if err = u.SetLoginToken(ctx, user.Id); err != nil {
+	if err = setLoginToken(ctx, u.svc, u.Handler, user.Id, domain.LoginMethodPhone); err != nil {
 		ctx.JSON(http.StatusOK, Result{
 			Code: 5,
 			Msg:  "系统错误",
@@ -338,17 +542,46 @@ func (u *UserHandler) RefreshToken(ctx *gin.Context) {
 		return
 	}
 
-	err = u.CheckSession(ctx, claims.Ssid)
+	err = u.CheckSession(ctx, claims.Uid, claims.Ssid)
+	if err != nil {
+		ctx.AbortWithStatus(http.StatusUnauthorized)
+		return
+	}
+	err = u.ConsumeRefreshToken(ctx, claims)
+	if err == jwt.ErrRefreshTokenReused {
+		u.log.Warn("refresh token 被重复使用，会话已经失效",
+			logger.Int64("uid", claims.Uid), logger.String("ssid", claims.Ssid))
+	}
+	if err != nil {
+		ctx.AbortWithStatus(http.StatusUnauthorized)
+		return
+	}
+	// 每次刷新都重新查角色，授予或者收回的角色在下一次刷新的时候生效
+	roles, err := u.svc.Roles(ctx, claims.Uid)
+	if err != nil {
+		u.log.Error("查询用户角色失败", logger.Error(err), logger.Int64("uid", claims.Uid))
+		ctx.JSON(http.StatusOK, Result{
+			Code: 5,
+			Msg:  "系统错误",
+		})
+		return
+	}
+	// 搞个新的 access_token，refresh token 也要换新的，旧的已经用过了
+	err = u.SetAccessToken(ctx, claims.Uid, claims.Ssid, roles)
 	if err != nil {
 		ctx.AbortWithStatus(http.StatusUnauthorized)
 		return
 	}
-	// 搞个新的 access_token
-	err = u.SetAccessToken(ctx, claims.Uid, claims.Ssid)
+	err = u.SetRefreshToken(ctx, claims.Uid, claims.Ssid)
 	if err != nil {
 		ctx.AbortWithStatus(http.StatusUnauthorized)
 		return
 	}
+	if err = u.TouchSession(ctx, claims.Uid, claims.Ssid); err != nil {
+		// 只是会话列表里面的最后活跃时间不准
+		u.log.Error("更新会话活跃时间失败", logger.Error(err),
+			logger.Int64("uid", claims.Uid), logger.String("ssid", claims.Ssid))
+	}
 	ctx.JSON(http.StatusOK, Result{
 		Msg: "刷新成功",
 	})
@@ -367,3 +600,652 @@ func (u *UserHandler) LogoutJWT(ctx *gin.Context) {
 		Msg: "退出登录OK",
 	})
 }
+
+// SendResetPasswordCode 手机号或者邮箱二选一，验证码发到对应的渠道
+func (u *UserHandler) SendResetPasswordCode(ctx *gin.Context) {
+	type Req struct {
+		Phone string `json:"phone"`
+		Email string `json:"email"`
+	}
+	var req Req
+	if err := ctx.Bind(&req); err != nil {
+		return
+	}
+	channel, target, ok := u.resetPasswordTarget(ctx, req.Phone, req.Email)
+	if !ok {
+		return
+	}
+
+	err := u.codeSvc.Send(ctx, resetPasswordBiz, channel, target)
+	switch err {
+	case nil:
+		ctx.JSON(http.StatusOK, Result{
+			Msg: "发送成功",
+		})
+	case service.ErrCodeSendTooMany:
+		ctx.JSON(http.StatusOK, Result{
+			Msg: "发送太频繁，请稍后再试",
+		})
+	default:
+		u.log.Error("发送重置密码验证码失败", logger.Error(err))
+		ctx.JSON(http.StatusOK, Result{
+			Code: 5,
+			Msg:  "系统错误",
+		})
+	}
+}
+
+// VerifyResetPasswordCode 验证码校验通过之后，返回一个短时间有效的凭证，用来设置新密码
+func (u *UserHandler) VerifyResetPasswordCode(ctx *gin.Context) {
+	type Req struct {
+		Phone string `json:"phone"`
+		Email string `json:"email"`
+		Code  string `json:"code"`
+	}
+	var req Req
+	if err := ctx.Bind(&req); err != nil {
+		return
+	}
+	channel, target, ok := u.resetPasswordTarget(ctx, req.Phone, req.Email)
+	if !ok {
+		return
+	}
+
+	ok, err := u.codeSvc.Verify(ctx, resetPasswordBiz, channel, target, req.Code)
+	if err != nil {
+		u.log.Error("校验重置密码验证码失败", logger.Error(err))
+		ctx.JSON(http.StatusOK, Result{
+			Code: 5,
+			Msg:  "系统错误",
+		})
+		return
+	}
+	if !ok {
+		ctx.JSON(http.StatusOK, Result{
+			Code: 4,
+			Msg:  "验证码有误",
+		})
+		return
+	}
+
+	ticket, err := u.svc.CreateResetTicket(ctx, channel, target)
+	switch err {
+	case nil:
+		ctx.JSON(http.StatusOK, Result{
+			Msg:  "验证码校验通过",
+			Data: ticket,
+		})
+	case service.ErrUserNotFound:
+		// 已经证明了手机号或者邮箱是自己的，所以可以告诉他没有注册
+		ctx.JSON(http.StatusOK, Result{
+			Code: 4,
+			Msg:  "手机号或邮箱未注册",
+		})
+	default:
+		u.log.Error("生成重置密码凭证失败", logger.Error(err))
+		ctx.JSON(http.StatusOK, Result{
+			Code: 5,
+			Msg:  "系统错误",
+		})
+	}
+}
+
+// resetPasswordTarget 手机号和邮箱只能填一个，返回对应的验证码渠道。输入不对的时候已经写好了响应
+func (u *UserHandler) resetPasswordTarget(ctx *gin.Context, phone string, email string) (string, string, bool) {
+	switch {
+	case phone != "" && email == "":
+		return service.CodeChannelSMS, phone, true
+	case email != "" && phone == "":
+		ok, err := u.emailExp.MatchString(email)
+		if err != nil {
+			u.log.Error("邮箱 正则匹配 失败", logger.Error(err))
+			ctx.JSON(http.StatusOK, Result{
+				Code: 5,
+				Msg:  "系统错误",
+			})
+			return "", "", false
+		}
+		if ok {
+			return service.CodeChannelEmail, email, true
+		}
+	}
+	ctx.JSON(http.StatusOK, Result{
+		Code: 4,
+		Msg:  "输入有误",
+	})
+	return "", "", false
+}
+
+// ResetPassword 设置新密码，成功之后所有已经登录的地方都要重新登录
+func (u *UserHandler) ResetPassword(ctx *gin.Context) {
+	type Req struct {
+		Ticket          string `json:"ticket"`
+		Password        string `json:"password"`
+		ConfirmPassword string `json:"confirmPassword"`
+	}
+	var req Req
+	if err := ctx.Bind(&req); err != nil {
+		return
+	}
+	if req.Password != req.ConfirmPassword {
+		ctx.JSON(http.StatusOK, Result{
+			Code: 4,
+			Msg:  "两次密码不一致",
+		})
+		return
+	}
+	ok, err := u.passwordExp.MatchString(req.Password)
+	if err != nil {
+		u.log.Error("密码 正则匹配 失败", logger.Error(err))
+		ctx.JSON(http.StatusOK, Result{
+			Code: 5,
+			Msg:  "系统错误",
+		})
+		return
+	}
+	if !ok {
+		ctx.JSON(http.StatusOK, Result{
+			Code: 4,
+			Msg:  "密码必须大于8位，包含数字、特殊字符",
+		})
+		return
+	}
+
+	uid, err := u.svc.ResetPassword(ctx, req.Ticket, req.Password)
+	switch err {
+	case nil:
+	case service.ErrResetTicketInvalid:
+		ctx.JSON(http.StatusOK, Result{
+			Code: 4,
+			Msg:  "验证已过期，请重新获取验证码",
+		})
+		return
+	default:
+		u.log.Error("重置密码失败", logger.Error(err))
+		ctx.JSON(http.StatusOK, Result{
+			Code: 5,
+			Msg:  "系统错误",
+		})
+		return
+	}
+
+	if err = u.RevokeSessions(ctx, uid); err != nil {
+		// 密码已经改了，只是旧的会话没能踢掉
+		u.log.Error("重置密码之后清除会话失败", logger.Error(err), logger.Int64("uid", uid))
+	}
+	ctx.JSON(http.StatusOK, Result{
+		Msg: "密码重置成功，请重新登录",
+	})
+}
+
+func (u *UserHandler) ChangePassword(ctx *gin.Context) {
+	type Req struct {
+		OldPassword     string `json:"oldPassword"`
+		Password        string `json:"password"`
+		ConfirmPassword string `json:"confirmPassword"`
+	}
+	var req Req
+	if err := ctx.Bind(&req); err != nil {
+		return
+	}
+	if req.Password != req.ConfirmPassword {
+		ctx.JSON(http.StatusOK, Result{
+			Code: 4,
+			Msg:  "两次密码不一致",
+		})
+		return
+	}
+	ok, err := u.passwordExp.MatchString(req.Password)
+	if err != nil {
+		u.log.Error("密码 正则匹配 失败", logger.Error(err))
+		ctx.JSON(http.StatusOK, Result{
+			Code: 5,
+			Msg:  "系统错误",
+		})
+		return
+	}
+	if !ok {
+		ctx.JSON(http.StatusOK, Result{
+			Code: 4,
+			Msg:  "密码必须大于8位，包含数字、特殊字符",
+		})
+		return
+	}
+
+	claims, ok := ctx.MustGet(jwt.KeyAccessClaims).(*jwt.AccessClaims)
+	if !ok {
+		ctx.JSON(http.StatusOK, Result{
+			Code: 5,
+			Msg:  "系统错误",
+		})
+		return
+	}
+	err = u.svc.ChangePassword(ctx, claims.Uid, req.OldPassword, req.Password)
+	switch err {
+	case nil:
+		ctx.JSON(http.StatusOK, Result{
+			Msg: "修改密码成功",
+		})
+	case service.ErrInvalidUserOrPassword:
+		ctx.JSON(http.StatusOK, Result{
+			Code: 4,
+			Msg:  "旧密码不对",
+		})
+	default:
+		u.log.Error("修改密码失败", logger.Error(err), logger.Int64("uid", claims.Uid))
+		ctx.JSON(http.StatusOK, Result{
+			Code: 5,
+			Msg:  "系统错误",
+		})
+	}
+}
+
+func (u *UserHandler) SendBindPhoneCode(ctx *gin.Context) {
+	type Req struct {
+		Phone string `json:"phone"`
+	}
+	var req Req
+	if err := ctx.Bind(&req); err != nil {
+		return
+	}
+	if req.Phone == "" {
+		ctx.JSON(http.StatusOK, Result{
+			Code: 4,
+			Msg:  "输入有误",
+		})
+		return
+	}
+	u.sendCode(ctx, bindPhoneBiz, service.CodeChannelSMS, req.Phone)
+}
+
+// BindPhone 绑定或者修改手机号
+func (u *UserHandler) BindPhone(ctx *gin.Context) {
+	type Req struct {
+		Phone string `json:"phone"`
+		Code  string `json:"code"`
+	}
+	var req Req
+	if err := ctx.Bind(&req); err != nil {
+		return
+	}
+	u.bind(ctx, bindPhoneBiz, service.CodeChannelSMS, req.Phone, req.Code, u.svc.BindPhone, "手机号已经被其他账号绑定")
+}
+
+func (u *UserHandler) SendBindEmailCode(ctx *gin.Context) {
+	type Req struct {
+		Email string `json:"email"`
+	}
+	var req Req
+	if err := ctx.Bind(&req); err != nil {
+		return
+	}
+	ok, err := u.emailExp.MatchString(req.Email)
+	if err != nil || !ok {
+		ctx.JSON(http.StatusOK, Result{
+			Code: 4,
+			Msg:  "邮箱格式不对",
+		})
+		return
+	}
+	u.sendCode(ctx, bindEmailBiz, service.CodeChannelEmail, req.Email)
+}
+
+// BindEmail 绑定或者修改邮箱
+func (u *UserHandler) BindEmail(ctx *gin.Context) {
+	type Req struct {
+		Email string `json:"email"`
+		Code  string `json:"code"`
+	}
+	var req Req
+	if err := ctx.Bind(&req); err != nil {
+		return
+	}
+	u.bind(ctx, bindEmailBiz, service.CodeChannelEmail, req.Email, req.Code, u.svc.BindEmail, "邮箱已经被其他账号绑定")
+}
+
+func (u *UserHandler) sendCode(ctx *gin.Context, biz string, channel string, target string) {
+	err := u.codeSvc.Send(ctx, biz, channel, target)
+	switch err {
+	case nil:
+		ctx.JSON(http.StatusOK, Result{
+			Msg: "发送成功",
+		})
+	case service.ErrCodeSendTooMany:
+		ctx.JSON(http.StatusOK, Result{
+			Msg: "发送太频繁，请稍后再试",
+		})
+	default:
+		u.log.Error("发送验证码失败", logger.Error(err), logger.String("biz", biz))
+		ctx.JSON(http.StatusOK, Result{
+			Code: 5,
+			Msg:  "系统错误",
+		})
+	}
+}
+
+func (u *UserHandler) bind(ctx *gin.Context, biz string, channel string, target string, code string,
+	fn func(ctx context.Context, uid int64, target string) error, conflictMsg string) {
+	claims, ok := ctx.MustGet(jwt.KeyAccessClaims).(*jwt.AccessClaims)
+	if !ok {
+		ctx.JSON(http.StatusOK, Result{
+			Code: 5,
+			Msg:  "系统错误",
+		})
+		return
+	}
+
+	ok, err := u.codeSvc.Verify(ctx, biz, channel, target, code)
+	if err != nil {
+		u.log.Error("校验验证码失败", logger.Error(err), logger.String("biz", biz))
+		ctx.JSON(http.StatusOK, Result{
+			Code: 5,
+			Msg:  "系统错误",
+		})
+		return
+	}
+	if !ok {
+		ctx.JSON(http.StatusOK, Result{
+			Code: 4,
+			Msg:  "验证码有误",
+		})
+		return
+	}
+
+	err = fn(ctx, claims.Uid, target)
+	switch err {
+	case nil:
+		ctx.JSON(http.StatusOK, Result{
+			Msg: "绑定成功",
+		})
+	case service.ErrUserDuplicate:
+		ctx.JSON(http.StatusOK, Result{
+			Code: 4,
+			Msg:  conflictMsg,
+		})
+	default:
+		u.log.Error("绑定失败", logger.Error(err),
+			logger.String("biz", biz), logger.Int64("uid", claims.Uid))
+		ctx.JSON(http.StatusOK, Result{
+			Code: 5,
+			Msg:  "系统错误",
+		})
+	}
+}
+
+// LoginMethods 当前账号可以用哪些方式登录
+func (u *UserHandler) LoginMethods(ctx *gin.Context) {
+	type LoginMethodVo struct {
+		Method string `json:"method"`
+		// 邮箱或者手机号，微信没有
+		Account string `json:"account,omitempty"`
+	}
+	claims, ok := ctx.MustGet(jwt.KeyAccessClaims).(*jwt.AccessClaims)
+	if !ok {
+		ctx.JSON(http.StatusOK, Result{
+			Code: 5,
+			Msg:  "系统错误",
+		})
+		return
+	}
+	user, err := u.svc.Profile(ctx, claims.Uid)
+	if err != nil {
+		u.log.Error("查询用户失败", logger.Error(err), logger.Int64("uid", claims.Uid))
+		ctx.JSON(http.StatusOK, Result{
+			Code: 5,
+			Msg:  "系统错误",
+		})
+		return
+	}
+	methods := user.LoginMethods()
+	res := make([]LoginMethodVo, 0, len(methods))
+	for _, m := range methods {
+		vo := LoginMethodVo{Method: string(m)}
+		switch m {
+		case domain.LoginMethodEmail:
+			vo.Account = user.Email
+		case domain.LoginMethodPhone:
+			vo.Account = user.Phone
+		}
+		res = append(res, vo)
+	}
+	ctx.JSON(http.StatusOK, Result{
+		Msg:  "成功",
+		Data: res,
+	})
+}
+
+// UnlinkLoginMethod 解绑一种登录方式，至少要保留一种
+func (u *UserHandler) UnlinkLoginMethod(ctx *gin.Context) {
+	type Req struct {
+		Method string `json:"method"`
+	}
+	var req Req
+	if err := ctx.Bind(&req); err != nil {
+		return
+	}
+	method := domain.LoginMethod(req.Method)
+	switch method {
+	case domain.LoginMethodEmail, domain.LoginMethodPhone, domain.LoginMethodWechat:
+	default:
+		ctx.JSON(http.StatusOK, Result{
+			Code: 4,
+			Msg:  "登录方式不对",
+		})
+		return
+	}
+
+	claims, ok := ctx.MustGet(jwt.KeyAccessClaims).(*jwt.AccessClaims)
+	if !ok {
+		ctx.JSON(http.StatusOK, Result{
+			Code: 5,
+			Msg:  "系统错误",
+		})
+		return
+	}
+	err := u.svc.UnlinkLoginMethod(ctx, claims.Uid, method)
+	switch err {
+	case nil:
+		ctx.JSON(http.StatusOK, Result{
+			Msg: "解绑成功",
+		})
+	case service.ErrLastLoginMethod:
+		ctx.JSON(http.StatusOK, Result{
+			Code: 4,
+			Msg:  "至少要保留一种登录方式",
+		})
+	case service.ErrLoginMethodNotLinked:
+		ctx.JSON(http.StatusOK, Result{
+			Code: 4,
+			Msg:  "没有绑定这种登录方式",
+		})
+	default:
+		u.log.Error("解绑登录方式失败", logger.Error(err),
+			logger.Int64("uid", claims.Uid), logger.String("method", req.Method))
+		ctx.JSON(http.StatusOK, Result{
+			Code: 5,
+			Msg:  "系统错误",
+		})
+	}
+}
+
+// Deactivate 注销账号，撤回文章，清空个人信息，所有的会话都失效
+func (u *UserHandler) Deactivate(ctx *gin.Context) {
+	claims, ok := ctx.MustGet(jwt.KeyAccessClaims).(*jwt.AccessClaims)
+	if !ok {
+		ctx.JSON(http.StatusOK, Result{
+			Code: 5,
+			Msg:  "系统错误",
+		})
+		return
+	}
+	err := u.svc.Deactivate(ctx, claims.Uid)
+	if err != nil {
+		u.log.Error("注销账号失败", logger.Error(err), logger.Int64("uid", claims.Uid))
+		ctx.JSON(http.StatusOK, Result{
+			Code: 5,
+			Msg:  "系统错误",
+		})
+		return
+	}
+	if err = u.RevokeSessions(ctx, claims.Uid); err != nil {
+		// 账号已经注销了，只是别的设备上的会话没能踢掉
+		u.log.Error("注销账号之后清除会话失败", logger.Error(err), logger.Int64("uid", claims.Uid))
+	}
+	if err = u.ClearToken(ctx); err != nil {
+		u.log.Error("注销账号之后退出登录失败", logger.Error(err), logger.Int64("uid", claims.Uid))
+	}
+	ctx.JSON(http.StatusOK, Result{
+		Msg: "账号已注销",
+	})
+}
+
+// Export 导出个人信息和所有的文章，浏览器里面直接下载成 JSON 文件
+func (u *UserHandler) Export(ctx *gin.Context) {
+	claims, ok := ctx.MustGet(jwt.KeyAccessClaims).(*jwt.AccessClaims)
+	if !ok {
+		ctx.JSON(http.StatusOK, Result{
+			Code: 5,
+			Msg:  "系统错误",
+		})
+		return
+	}
+	user, arts, err := u.svc.Export(ctx, claims.Uid)
+	if err != nil {
+		u.log.Error("导出用户数据失败", logger.Error(err), logger.Int64("uid", claims.Uid))
+		ctx.JSON(http.StatusOK, Result{
+			Code: 5,
+			Msg:  "系统错误",
+		})
+		return
+	}
+	profile := ExportProfileVo{
+		Id:       user.Id,
+		Email:    user.Email,
+		Phone:    user.Phone,
+		Nickname: user.Nickname,
+		AboutMe:  user.AboutMe,
+		Ctime:    user.Ctime.Format(time.DateTime),
+	}
+	if !user.Birthday.IsZero() {
+		profile.Birthday = user.Birthday.Format(time.DateOnly)
+	}
+	vos := make([]ExportArticleVo, 0, len(arts))
+	for _, art := range arts {
+		vos = append(vos, ExportArticleVo{
+			Id:      art.Id,
+			Title:   art.Title,
+			Content: art.Content,
+			Status:  art.Status.ToUint8(),
+			Tags:    art.Tags,
+			Ctime:   art.Ctime.Format(time.DateTime),
+			Utime:   art.Utime.Format(time.DateTime),
+		})
+	}
+	now := time.Now()
+	ctx.Header("Content-Disposition",
+		fmt.Sprintf(`attachment; filename="webook-%d-%s.json"`, user.Id, now.Format("20060102150405")))
+	ctx.JSON(http.StatusOK, ExportVo{
+		Profile:    profile,
+		Articles:   vos,
+		ExportedAt: now.Format(time.DateTime),
+	})
+}
+
+// Sessions 登录了的设备，最近活跃的在前面
+func (u *UserHandler) Sessions(ctx *gin.Context) {
+	claims, ok := ctx.MustGet(jwt.KeyAccessClaims).(*jwt.AccessClaims)
+	if !ok {
+		ctx.JSON(http.StatusOK, Result{
+			Code: 5,
+			Msg:  "系统错误",
+		})
+		return
+	}
+	sessions, err := u.ListSessions(ctx, claims.Uid)
+	if err != nil {
+		u.log.Error("查询会话失败", logger.Error(err), logger.Int64("uid", claims.Uid))
+		ctx.JSON(http.StatusOK, Result{
+			Code: 5,
+			Msg:  "系统错误",
+		})
+		return
+	}
+	res := make([]SessionVo, 0, len(sessions))
+	for _, s := range sessions {
+		res = append(res, SessionVo{
+			Ssid:       s.Ssid,
+			UserAgent:  s.UserAgent,
+			IP:         s.IP,
+			Method:     string(s.Method),
+			LoginTime:  time.UnixMilli(s.LoginTime).Format(time.DateTime),
+			LastActive: time.UnixMilli(s.LastActive).Format(time.DateTime),
+			Current:    s.Ssid == claims.Ssid,
+		})
+	}
+	ctx.JSON(http.StatusOK, Result{
+		Msg:  "成功",
+		Data: res,
+	})
+}
+
+// LogoutSession 退出某一个设备，可以是当前的设备
+func (u *UserHandler) LogoutSession(ctx *gin.Context) {
+	type Req struct {
+		Ssid string `json:"ssid"`
+	}
+	var req Req
+	if err := ctx.Bind(&req); err != nil {
+		return
+	}
+	claims, ok := ctx.MustGet(jwt.KeyAccessClaims).(*jwt.AccessClaims)
+	if !ok {
+		ctx.JSON(http.StatusOK, Result{
+			Code: 5,
+			Msg:  "系统错误",
+		})
+		return
+	}
+	err := u.RevokeSession(ctx, claims.Uid, req.Ssid)
+	switch err {
+	case nil:
+		ctx.JSON(http.StatusOK, Result{
+			Msg: "退出成功",
+		})
+	case jwt.ErrSessionNotFound:
+		ctx.JSON(http.StatusOK, Result{
+			Code: 4,
+			Msg:  "会话不存在",
+		})
+	default:
+		u.log.Error("退出会话失败", logger.Error(err),
+			logger.Int64("uid", claims.Uid), logger.String("ssid", req.Ssid))
+		ctx.JSON(http.StatusOK, Result{
+			Code: 5,
+			Msg:  "系统错误",
+		})
+	}
+}
+
+// LogoutOtherSessions 只保留当前的设备
+func (u *UserHandler) LogoutOtherSessions(ctx *gin.Context) {
+	claims, ok := ctx.MustGet(jwt.KeyAccessClaims).(*jwt.AccessClaims)
+	if !ok {
+		ctx.JSON(http.StatusOK, Result{
+			Code: 5,
+			Msg:  "系统错误",
+		})
+		return
+	}
+	err := u.RevokeOtherSessions(ctx, claims.Uid, claims.Ssid)
+	if err != nil {
+		u.log.Error("退出其他会话失败", logger.Error(err), logger.Int64("uid", claims.Uid))
+		ctx.JSON(http.StatusOK, Result{
+			Code: 5,
+			Msg:  "系统错误",
+		})
+		return
+	}
+	ctx.JSON(http.StatusOK, Result{
+		Msg: "已退出其他设备",
+	})
+}
@@ -2,16 +2,22 @@ package web
 
 import (
 	"bytes"
+	"encoding/json"
+	"gitee.com/geekbang/basic-go/webook/internal/domain"
 	"gitee.com/geekbang/basic-go/webook/internal/service"
 	mock_service "gitee.com/geekbang/basic-go/webook/internal/service/mocks"
+	"gitee.com/geekbang/basic-go/webook/internal/web/jwt"
+	"gitee.com/geekbang/basic-go/webook/pkg/logger"
 	"github.com/gin-gonic/gin"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 	"go.uber.org/mock/gomock"
+	"go.uber.org/zap"
 	"golang.org/x/crypto/bcrypt"
 	"net/http"
 	"net/http/httptest"
 	"testing"
+	"time"
 )
 
 func TestPingRoute(t *testing.T) {
@@ -29,7 +35,7 @@ func TestPingRoute(t *testing.T) {
 }
 
 func TestEncrypt(t *testing.T) {
-	_ = NewUserHandler(nil, nil)
+	_ = NewUserHandler(nil, nil, nil, nil)
 	password := "hello#world123"
 	encrypted, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
 	if err != nil {
@@ -42,7 +48,7 @@ func TestEncrypt(t *testing.T) {
 func TestUserHandler_SignUp(t *testing.T) {
 	testCases := []struct {
 		name    string
-		mock    func(ctrl *gomock.Controller) service.UserService
+		mock    func(ctrl *gomock.Controller) (service.UserService, service.CodeService)
 		reqBody string
 
 		wantCode int
@@ -50,10 +56,13 @@ func TestUserHandler_SignUp(t *testing.T) {
 	}{
 		{
 			name: "注册成功",
-			mock: func(ctrl *gomock.Controller) service.UserService {
+			mock: func(ctrl *gomock.Controller) (service.UserService, service.CodeService) {
 				ret := mock_service.NewMockUserService(ctrl)
 				ret.EXPECT().SignUp(gomock.Any(), gomock.Any()).Return(nil)
-				return ret
+				codeSvc := mock_service.NewMockCodeService(ctrl)
+				codeSvc.EXPECT().Send(gomock.Any(), signupBiz, service.CodeChannelEmail, "123@qq.com").Return(nil)
+				ret.EXPECT().SetSignUpPassword(gomock.Any(), "123@qq.com", "hello#world123").Return(nil)
+				return ret, codeSvc
 			},
 
 			reqBody: `
@@ -64,14 +73,14 @@ func TestUserHandler_SignUp(t *testing.T) {
 }
 `,
 			wantCode: http.StatusOK,
-			wantBody: "注册成功",
+			wantBody: "注册成功，请输入邮件里面的验证码",
 		},
 		{
 			name: "参数不对，bind 失败",
-			mock: func(ctrl *gomock.Controller) service.UserService {
+			mock: func(ctrl *gomock.Controller) (service.UserService, service.CodeService) {
 				usersvc := mock_service.NewMockUserService(ctrl)
 				// 注册成功是 return nil
-				return usersvc
+				return usersvc, nil
 			},
 
 			reqBody: `
@@ -81,15 +90,53 @@ func TestUserHandler_SignUp(t *testing.T) {
 `,
 			wantCode: http.StatusBadRequest,
 		},
+		{
+			name: "邮箱已经注册",
+			mock: func(ctrl *gomock.Controller) (service.UserService, service.CodeService) {
+				ret := mock_service.NewMockUserService(ctrl)
+				ret.EXPECT().SignUp(gomock.Any(), gomock.Any()).Return(service.ErrUserDuplicate)
+				return ret, nil
+			},
+
+			reqBody: `
+{
+	"email": "123@qq.com",
+	"password": "hello#world123",
+	"confirmPassword": "hello#world123"
+}
+`,
+			wantCode: http.StatusOK,
+			wantBody: "手机/邮箱已注册",
+		},
+		{
+			name: "验证码发送太频繁，不暂存密码",
+			mock: func(ctrl *gomock.Controller) (service.UserService, service.CodeService) {
+				ret := mock_service.NewMockUserService(ctrl)
+				ret.EXPECT().SignUp(gomock.Any(), gomock.Any()).Return(nil)
+				codeSvc := mock_service.NewMockCodeService(ctrl)
+				codeSvc.EXPECT().Send(gomock.Any(), signupBiz, service.CodeChannelEmail, "123@qq.com").
+					Return(service.ErrCodeSendTooMany)
+				return ret, codeSvc
+			},
+
+			reqBody: `
+{
+	"email": "123@qq.com",
+	"password": "hello#world123",
+	"confirmPassword": "hello#world123"
+}
+`,
+			wantCode: http.StatusOK,
+			wantBody: "注册成功，验证码发送太频繁，请稍后再试",
+		},
 	}
 
 	for _, tc := range testCases {
 		t.Run(tc.name, func(t *testing.T) {
 			ctrl := gomock.NewController(t)
 			defer ctrl.Finish()
-			userService := tc.mock(ctrl)
-			// 用不上 codeSvc
-			userHandler := NewUserHandler(userService, nil)
+			userService, codeSvc := tc.mock(ctrl)
+			userHandler := NewUserHandler(userService, codeSvc, nil, logger.NewZapLogger(zap.NewNop()))
 
 			engine := gin.Default()
 
@@ -109,3 +156,259 @@ func TestUserHandler_SignUp(t *testing.T) {
 		})
 	}
 }
+
+func TestUserHandler_Edit(t *testing.T) {
+	testCases := []struct {
+		name    string
+		mock    func(ctrl *gomock.Controller) service.UserService
+		reqBody string
+
+		wantResult Result
+	}{
+		{
+			name: "修改成功",
+			mock: func(ctrl *gomock.Controller) service.UserService {
+				ret := mock_service.NewMockUserService(ctrl)
+				ret.EXPECT().UpdateNonSensitiveInfo(gomock.Any(), domain.User{
+					Id:       123,
+					Nickname: "大明",
+					Birthday: time.Date(2000, 1, 1, 0, 0, 0, 0, time.Local),
+					AboutMe:  "你好",
+				}).Return(nil)
+				return ret
+			},
+			reqBody:    `{"nickname": " 大明 ", "birthday": "2000-01-01", "aboutMe": "你好"}`,
+			wantResult: Result{Msg: "修改成功"},
+		},
+		{
+			name: "昵称为空",
+			mock: func(ctrl *gomock.Controller) service.UserService {
+				return mock_service.NewMockUserService(ctrl)
+			},
+			reqBody:    `{"nickname": "  ", "birthday": "2000-01-01"}`,
+			wantResult: Result{Code: 4, Msg: "昵称不能为空，并且不能超过 32 个字符"},
+		},
+		{
+			name: "生日格式不对",
+			mock: func(ctrl *gomock.Controller) service.UserService {
+				return mock_service.NewMockUserService(ctrl)
+			},
+			reqBody:    `{"nickname": "大明", "birthday": "2000/01/01"}`,
+			wantResult: Result{Code: 4, Msg: "生日格式不对"},
+		},
+		{
+			name: "生日在未来",
+			mock: func(ctrl *gomock.Controller) service.UserService {
+				return mock_service.NewMockUserService(ctrl)
+			},
+			reqBody:    `{"nickname": "大明", "birthday": "9999-01-01"}`,
+			wantResult: Result{Code: 4, Msg: "生日格式不对"},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+			userHandler := NewUserHandler(tc.mock(ctrl), nil, nil, nil)
+
+			engine := gin.Default()
+			engine.Use(func(ctx *gin.Context) {
+				ctx.Set(jwt.KeyAccessClaims, &jwt.AccessClaims{Uid: 123})
+			})
+			userHandler.RegisterHandlers(engine)
+			req, err := http.NewRequest(http.MethodPost, "/users/edit", bytes.NewReader([]byte(tc.reqBody)))
+			require.NoError(t, err)
+			req.Header.Set("Content-Type", "application/json")
+
+			resp := httptest.NewRecorder()
+			engine.ServeHTTP(resp, req)
+			assert.Equal(t, http.StatusOK, resp.Code)
+			var res Result
+			err = json.NewDecoder(resp.Body).Decode(&res)
+			require.NoError(t, err)
+			assert.Equal(t, tc.wantResult, res)
+		})
+	}
+}
+
+func TestUserHandler_BindPhone(t *testing.T) {
+	testCases := []struct {
+		name string
+		mock func(ctrl *gomock.Controller) (service.UserService, service.CodeService)
+
+		wantResult Result
+	}{
+		{
+			name: "绑定成功",
+			mock: func(ctrl *gomock.Controller) (service.UserService, service.CodeService) {
+				userSvc := mock_service.NewMockUserService(ctrl)
+				codeSvc := mock_service.NewMockCodeService(ctrl)
+				codeSvc.EXPECT().Verify(gomock.Any(), bindPhoneBiz, service.CodeChannelSMS, "15212345678", "123456").Return(true, nil)
+				userSvc.EXPECT().BindPhone(gomock.Any(), int64(123), "15212345678").Return(nil)
+				return userSvc, codeSvc
+			},
+			wantResult: Result{Msg: "绑定成功"},
+		},
+		{
+			name: "验证码不对",
+			mock: func(ctrl *gomock.Controller) (service.UserService, service.CodeService) {
+				userSvc := mock_service.NewMockUserService(ctrl)
+				codeSvc := mock_service.NewMockCodeService(ctrl)
+				codeSvc.EXPECT().Verify(gomock.Any(), bindPhoneBiz, service.CodeChannelSMS, "15212345678", "123456").Return(false, nil)
+				return userSvc, codeSvc
+			},
+			wantResult: Result{Code: 4, Msg: "验证码有误"},
+		},
+		{
+			name: "手机号被别人用了",
+			mock: func(ctrl *gomock.Controller) (service.UserService, service.CodeService) {
+				userSvc := mock_service.NewMockUserService(ctrl)
+				codeSvc := mock_service.NewMockCodeService(ctrl)
+				codeSvc.EXPECT().Verify(gomock.Any(), bindPhoneBiz, service.CodeChannelSMS, "15212345678", "123456").Return(true, nil)
+				userSvc.EXPECT().BindPhone(gomock.Any(), int64(123), "15212345678").
+					Return(service.ErrUserDuplicate)
+				return userSvc, codeSvc
+			},
+			wantResult: Result{Code: 4, Msg: "手机号已经被其他账号绑定"},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+			userSvc, codeSvc := tc.mock(ctrl)
+			userHandler := NewUserHandler(userSvc, codeSvc, nil, nil)
+
+			engine := gin.Default()
+			engine.Use(func(ctx *gin.Context) {
+				ctx.Set(jwt.KeyAccessClaims, &jwt.AccessClaims{Uid: 123})
+			})
+			userHandler.RegisterHandlers(engine)
+			req, err := http.NewRequest(http.MethodPost, "/users/phone/bind",
+				bytes.NewReader([]byte(`{"phone": "15212345678", "code": "123456"}`)))
+			require.NoError(t, err)
+			req.Header.Set("Content-Type", "application/json")
+
+			resp := httptest.NewRecorder()
+			engine.ServeHTTP(resp, req)
+			assert.Equal(t, http.StatusOK, resp.Code)
+			var res Result
+			err = json.NewDecoder(resp.Body).Decode(&res)
+			require.NoError(t, err)
+			assert.Equal(t, tc.wantResult, res)
+		})
+	}
+}
+
+func TestUserHandler_VerifyResetPasswordCode(t *testing.T) {
+	testCases := []struct {
+		name string
+		mock func(ctrl *gomock.Controller) (service.UserService, service.CodeService)
+		body string
+
+		wantResult Result
+	}{
+		{
+			name: "邮箱",
+			mock: func(ctrl *gomock.Controller) (service.UserService, service.CodeService) {
+				userSvc := mock_service.NewMockUserService(ctrl)
+				codeSvc := mock_service.NewMockCodeService(ctrl)
+				codeSvc.EXPECT().Verify(gomock.Any(), resetPasswordBiz, service.CodeChannelEmail, "123@qq.com", "123456").Return(true, nil)
+				userSvc.EXPECT().CreateResetTicket(gomock.Any(), service.CodeChannelEmail, "123@qq.com").Return("ticket", nil)
+				return userSvc, codeSvc
+			},
+			body:       `{"email": "123@qq.com", "code": "123456"}`,
+			wantResult: Result{Msg: "验证码校验通过", Data: "ticket"},
+		},
+		{
+			name: "手机号",
+			mock: func(ctrl *gomock.Controller) (service.UserService, service.CodeService) {
+				userSvc := mock_service.NewMockUserService(ctrl)
+				codeSvc := mock_service.NewMockCodeService(ctrl)
+				codeSvc.EXPECT().Verify(gomock.Any(), resetPasswordBiz, service.CodeChannelSMS, "15212345678", "123456").Return(true, nil)
+				userSvc.EXPECT().CreateResetTicket(gomock.Any(), service.CodeChannelSMS, "15212345678").Return("ticket", nil)
+				return userSvc, codeSvc
+			},
+			body:       `{"phone": "15212345678", "code": "123456"}`,
+			wantResult: Result{Msg: "验证码校验通过", Data: "ticket"},
+		},
+		{
+			name: "邮箱未注册",
+			mock: func(ctrl *gomock.Controller) (service.UserService, service.CodeService) {
+				userSvc := mock_service.NewMockUserService(ctrl)
+				codeSvc := mock_service.NewMockCodeService(ctrl)
+				codeSvc.EXPECT().Verify(gomock.Any(), resetPasswordBiz, service.CodeChannelEmail, "123@qq.com", "123456").Return(true, nil)
+				userSvc.EXPECT().CreateResetTicket(gomock.Any(), service.CodeChannelEmail, "123@qq.com").
+					Return("", service.ErrUserNotFound)
+				return userSvc, codeSvc
+			},
+			body:       `{"email": "123@qq.com", "code": "123456"}`,
+			wantResult: Result{Code: 4, Msg: "手机号或邮箱未注册"},
+		},
+		{
+			name: "邮箱格式不对",
+			mock: func(ctrl *gomock.Controller) (service.UserService, service.CodeService) {
+				return mock_service.NewMockUserService(ctrl), mock_service.NewMockCodeService(ctrl)
+			},
+			body:       `{"email": "123", "code": "123456"}`,
+			wantResult: Result{Code: 4, Msg: "输入有误"},
+		},
+		{
+			name: "手机号和邮箱都填了",
+			mock: func(ctrl *gomock.Controller) (service.UserService, service.CodeService) {
+				return mock_service.NewMockUserService(ctrl), mock_service.NewMockCodeService(ctrl)
+			},
+			body:       `{"phone": "15212345678", "email": "123@qq.com", "code": "123456"}`,
+			wantResult: Result{Code: 4, Msg: "输入有误"},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+			userSvc, codeSvc := tc.mock(ctrl)
+			userHandler := NewUserHandler(userSvc, codeSvc, nil, nil)
+
+			engine := gin.Default()
+			userHandler.RegisterHandlers(engine)
+			req, err := http.NewRequest(http.MethodPost, "/users/reset_password/verify",
+				bytes.NewReader([]byte(tc.body)))
+			require.NoError(t, err)
+			req.Header.Set("Content-Type", "application/json")
+
+			resp := httptest.NewRecorder()
+			engine.ServeHTTP(resp, req)
+			assert.Equal(t, http.StatusOK, resp.Code)
+			var res Result
+			err = json.NewDecoder(resp.Body).Decode(&res)
+			require.NoError(t, err)
+			assert.Equal(t, tc.wantResult, res)
+		})
+	}
+}
+
+func TestUserHandler_SendResetPasswordCode(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	codeSvc := mock_service.NewMockCodeService(ctrl)
+	// 填的是邮箱就发邮件
+	codeSvc.EXPECT().Send(gomock.Any(), resetPasswordBiz, service.CodeChannelEmail, "123@qq.com").Return(nil)
+	userHandler := NewUserHandler(mock_service.NewMockUserService(ctrl), codeSvc, nil, nil)
+
+	engine := gin.Default()
+	userHandler.RegisterHandlers(engine)
+	req, err := http.NewRequest(http.MethodPost, "/users/reset_password/code/send",
+		bytes.NewReader([]byte(`{"email": "123@qq.com"}`)))
+	require.NoError(t, err)
+	req.Header.Set("Content-Type", "application/json")
+
+	resp := httptest.NewRecorder()
+	engine.ServeHTTP(resp, req)
+	var res Result
+	err = json.NewDecoder(resp.Body).Decode(&res)
+	require.NoError(t, err)
+	assert.Equal(t, Result{Msg: "发送成功"}, res)
+}
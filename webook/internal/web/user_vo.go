@@ -0,0 +1,41 @@
+package web
+
+// ExportVo 导出的个人数据
+type ExportVo struct {
+	Profile    ExportProfileVo   `json:"profile"`
+	Articles   []ExportArticleVo `json:"articles"`
+	ExportedAt string            `json:"exportedAt"`
+}
+
+type ExportProfileVo struct {
+	Id       int64  `json:"id"`
+	Email    string `json:"email"`
+	Phone    string `json:"phone"`
+	Nickname string `json:"nickname"`
+	Birthday string `json:"birthday"`
+	AboutMe  string `json:"aboutMe"`
+	Ctime    string `json:"ctime"`
+}
+
+// ExportArticleVo 导出的文章带全文，包括还没有发表的
+type ExportArticleVo struct {
+	Id      int64    `json:"id"`
+	Title   string   `json:"title"`
+	Content string   `json:"content"`
+	Status  uint8    `json:"status"`
+	Tags    []string `json:"tags"`
+	Ctime   string   `json:"ctime"`
+	Utime   string   `json:"utime"`
+}
+
+// SessionVo 登录了的设备
+type SessionVo struct {
+	Ssid       string `json:"ssid"`
+	UserAgent  string `json:"userAgent"`
+	IP         string `json:"ip"`
+	Method     string `json:"method"`
+	LoginTime  string `json:"loginTime"`
+	LastActive string `json:"lastActive"`
+	// 是不是现在这个请求的会话
+	Current bool `json:"current"`
+}
@@ -3,6 +3,7 @@ package web
 import (
 	"errors"
 	"fmt"
+	"gitee.com/geekbang/basic-go/webook/internal/domain"
 	"gitee.com/geekbang/basic-go/webook/internal/service"
 	"gitee.com/geekbang/basic-go/webook/internal/service/oauth2/wechat"
 	ijwt "gitee.com/geekbang/basic-go/webook/internal/web/jwt"
@@ -35,10 +36,29 @@ func NewOAuth2WechatHandler(svc wechat.Service,
 func (h *OAuth2WechatHandler) RegisterHandlers(s *gin.Engine) {
 	g := s.Group("/oauth2/wechat")
 	g.GET("/authurl", h.AuthURL)
+	// 已经登录的用户绑定微信，需要登录
+	g.GET("/bind/authurl", h.BindAuthURL)
 	g.Any("/callback", h.Callback)
 }
 
 func (h *OAuth2WechatHandler) AuthURL(ctx *gin.Context) {
+	h.authURL(ctx, 0)
+}
+
+func (h *OAuth2WechatHandler) BindAuthURL(ctx *gin.Context) {
+	claims, ok := ctx.MustGet(ijwt.KeyAccessClaims).(*ijwt.AccessClaims)
+	if !ok {
+		ctx.JSON(http.StatusOK, Result{
+			Code: 5,
+			Msg:  "系统错误",
+		})
+		return
+	}
+	h.authURL(ctx, claims.Uid)
+}
+
+// authURL uid 不为 0 表示扫码之后绑定到这个用户上，而不是登录
+func (h *OAuth2WechatHandler) authURL(ctx *gin.Context, uid int64) {
 	state := uuid.New().String()
 	url, err := h.svc.AuthURL(ctx, state)
 	if err != nil {
@@ -49,7 +69,7 @@ func (h *OAuth2WechatHandler) AuthURL(ctx *gin.Context) {
 		return
 	}
 
-	if err = h.setStateCookie(ctx, state); err != nil {
+	if err = h.setStateCookie(ctx, state, uid); err != nil {
 		ctx.JSON(http.StatusOK, Result{
 			Code: 5,
 			Msg:  "系统错误",
@@ -62,7 +82,7 @@ func (h *OAuth2WechatHandler) AuthURL(ctx *gin.Context) {
 
 func (h *OAuth2WechatHandler) Callback(ctx *gin.Context) {
 	code := ctx.Query("code")
-	err := h.verifyState(ctx)
+	claims, err := h.verifyState(ctx)
 	if err != nil {
 		ctx.JSON(http.StatusOK, Result{
 			Code: 5,
@@ -79,8 +99,21 @@ func (h *OAuth2WechatHandler) Callback(ctx *gin.Context) {
 		return
 	}
 
+	// 微信跳转回来的时候不会带上我们的 token，所以要绑定的用户记在 state 里面
+	if claims.Uid > 0 {
+		h.bind(ctx, claims.Uid, info)
+		return
+	}
+
 	// 从 userService 里面拿 uid
 	u, err := h.userSvc.FindOrCreateByWechat(ctx, info)
+	if err == service.ErrUserBanned {
+		ctx.JSON(http.StatusOK, Result{
+			Code: codeUserBanned,
+			Msg:  "账号已被封禁",
+		})
+		return
+	}
 	if err != nil {
 		ctx.JSON(http.StatusOK, Result{
 			Code: 5,
@@ -89,7 +122,7 @@ func (h *OAuth2WechatHandler) Callback(ctx *gin.Context) {
 		return
 	}
 
-	err = h.SetLoginToken(ctx, u.Id)
+	err = setLoginToken(ctx, h.userSvc, h.Handler, u.Id, domain.LoginMethodWechat)
 	if err != nil {
 		ctx.JSON(http.StatusOK, Result{
 			Code: 5,
@@ -103,10 +136,31 @@ func (h *OAuth2WechatHandler) Callback(ctx *gin.Context) {
 
 }
 
+func (h *OAuth2WechatHandler) bind(ctx *gin.Context, uid int64, info domain.WechatInfo) {
+	err := h.userSvc.BindWechat(ctx, uid, info)
+	switch err {
+	case nil:
+		ctx.JSON(http.StatusOK, Result{
+			Msg: "绑定成功",
+		})
+	case service.ErrUserDuplicate:
+		ctx.JSON(http.StatusOK, Result{
+			Code: 4,
+			Msg:  "微信已经被其他账号绑定",
+		})
+	default:
+		ctx.JSON(http.StatusOK, Result{
+			Code: 5,
+			Msg:  "系统错误",
+		})
+	}
+}
+
 // setStateCookie 只有微信这里用，所以定义在这里
-func (h *OAuth2WechatHandler) setStateCookie(ctx *gin.Context, state string) error {
+func (h *OAuth2WechatHandler) setStateCookie(ctx *gin.Context, state string, uid int64) error {
 	token := jwt.NewWithClaims(jwt.SigningMethodHS256, StateClaims{
 		State: state,
+		Uid:   uid,
 	})
 	tokenStr, err := token.SignedString(h.stateTokenKey)
 	if err != nil {
@@ -121,27 +175,29 @@ func (h *OAuth2WechatHandler) setStateCookie(ctx *gin.Context, state string) err
 	return nil
 }
 
-func (h *OAuth2WechatHandler) verifyState(ctx *gin.Context) error {
+func (h *OAuth2WechatHandler) verifyState(ctx *gin.Context) (*StateClaims, error) {
 	state := ctx.Query("state")
 	tokenStr, err := ctx.Cookie("jwt-state")
 	if err != nil {
-		return err
+		return nil, err
 	}
 	claims := &StateClaims{}
 	token, err := jwt.ParseWithClaims(tokenStr, claims, func(token *jwt.Token) (interface{}, error) {
 		return h.stateTokenKey, nil
 	})
 	if err != nil || !token.Valid {
-		return fmt.Errorf("token 已经过期了, %w", err)
+		return nil, fmt.Errorf("token 已经过期了, %w", err)
 	}
 
 	if claims.State != state {
-		return errors.New("state 不相等")
+		return nil, errors.New("state 不相等")
 	}
-	return nil
+	return claims, nil
 }
 
 type StateClaims struct {
 	State string
+	// Uid 绑定微信的时候才有
+	Uid int64
 	jwt.RegisteredClaims
 }
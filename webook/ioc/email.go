@@ -0,0 +1,38 @@
+package ioc
+
+import (
+	"gitee.com/geekbang/basic-go/webook/internal/service/email"
+	"gitee.com/geekbang/basic-go/webook/internal/service/email/memory"
+	"gitee.com/geekbang/basic-go/webook/internal/service/email/smtp"
+	"github.com/spf13/viper"
+	"net"
+	stdsmtp "net/smtp"
+)
+
+func InitEmailService() email.Service {
+	type Config struct {
+		// host:port
+		Addr     string `yaml:"addr"`
+		From     string `yaml:"from"`
+		Username string `yaml:"username"`
+		Password string `yaml:"password"`
+	}
+	var c Config
+	err := viper.UnmarshalKey("email", &c)
+	if err != nil {
+		panic(err)
+	}
+	// 没有配置 SMTP 服务器的时候，用内存实现
+	if c.Addr == "" {
+		return memory.NewService()
+	}
+	var auth stdsmtp.Auth
+	if c.Username != "" {
+		host, _, err := net.SplitHostPort(c.Addr)
+		if err != nil {
+			panic(err)
+		}
+		auth = stdsmtp.PlainAuth("", c.Username, c.Password, host)
+	}
+	return smtp.NewService(c.Addr, c.From, auth)
+}
@@ -0,0 +1,60 @@
+package ioc
+
+import (
+	"context"
+	"fmt"
+	"gitee.com/geekbang/basic-go/webook/internal/domain"
+	"gitee.com/geekbang/basic-go/webook/internal/job"
+	"gitee.com/geekbang/basic-go/webook/internal/repository"
+	"gitee.com/geekbang/basic-go/webook/internal/service"
+	"gitee.com/geekbang/basic-go/webook/pkg/logger"
+	"gitee.com/geekbang/basic-go/webook/pkg/utils/lock"
+	"github.com/google/uuid"
+	"github.com/robfig/cron/v3"
+	"os"
+	"time"
+)
+
+func InitRankingJob(svc service.RankingService, client *lock.Client, l logger.Logger) *job.RankingJob {
+	return job.NewRankingJob(svc, client, l, time.Second*30)
+}
+
+func InitJobs(l logger.Logger, rankingJob *job.RankingJob) *cron.Cron {
+	res := cron.New(cron.WithSeconds())
+	bd := job.NewCronJobBuilder(l)
+	// 每三分钟算一次热榜
+	_, err := res.AddJob("0 */3 * * * ?", bd.Build(rankingJob))
+	if err != nil {
+		panic(err)
+	}
+	return res
+}
+
+func InitCronJobService(repo repository.CronJobRepository, l logger.Logger) service.CronJobService {
+	hostname, _ := os.Hostname()
+	// 同一台机器上可能跑多个实例，所以加上 uuid
+	owner := fmt.Sprintf("%s-%s", hostname, uuid.New().String())
+	return service.NewCronJobService(repo, owner, l)
+}
+
+// InitScheduler 数据库里面定义的任务，由抢占到的实例来执行
+func InitScheduler(l logger.Logger, svc service.CronJobService, artSvc service.ArticleService) *job.Scheduler {
+	res := job.NewScheduler(svc, l)
+	local := job.NewLocalFuncExecutor()
+	publishJob := job.NewPublishJob(artSvc, l)
+	local.RegisterFunc(publishJob.Name(), publishJob.Exec)
+	res.RegisterExecutor(local)
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	// 每分钟检查一次有没有到点的定时文章
+	err := svc.AddJob(ctx, domain.Job{
+		Name:       publishJob.Name(),
+		Executor:   local.Name(),
+		Expression: "0 * * * * ?",
+	})
+	if err != nil {
+		panic(err)
+	}
+	return res
+}
@@ -0,0 +1,70 @@
+package ioc
+
+import (
+	"fmt"
+	"gitee.com/geekbang/basic-go/webook/internal/web/jwt"
+	"github.com/spf13/viper"
+	"time"
+)
+
+// InitJWTConfig access token 和 refresh token 分别在 jwt.access 和 jwt.refresh 下面配置
+func InitJWTConfig() jwt.Config {
+	return jwt.Config{
+		Access:  initTokenConfig("jwt.access"),
+		Refresh: initTokenConfig("jwt.refresh"),
+	}
+}
+
+func initTokenConfig(key string) jwt.TokenConfig {
+	type KeyConfig struct {
+		Kid string `yaml:"kid"`
+		// HS256、RS256 或者 EdDSA
+		Alg string `yaml:"alg"`
+		// HS256 用
+		Secret string `yaml:"secret"`
+		// RS256 和 EdDSA 用，PEM 格式。只用来校验的旧 key 只需要公钥
+		PrivateKey string `yaml:"privateKey"`
+		PublicKey  string `yaml:"publicKey"`
+	}
+	type Config struct {
+		// 签名用的 key，其他的 key 只用来校验
+		SigningKid string      `yaml:"signingKid"`
+		Keys       []KeyConfig `yaml:"keys"`
+		// 比如说 30m、168h
+		Expiration time.Duration `yaml:"expiration"`
+	}
+	var c Config
+	err := viper.UnmarshalKey(key, &c)
+	if err != nil {
+		panic(err)
+	}
+	if c.Expiration <= 0 {
+		panic(fmt.Errorf("%s 没有配置有效期", key))
+	}
+	keys := make([]jwt.Key, 0, len(c.Keys))
+	for _, kc := range c.Keys {
+		var k jwt.Key
+		switch kc.Alg {
+		case "HS256":
+			k, err = jwt.NewHMACKey(kc.Kid, kc.Secret)
+		case "RS256":
+			k, err = jwt.NewRSAKey(kc.Kid, kc.PrivateKey, kc.PublicKey)
+		case "EdDSA":
+			k, err = jwt.NewEdDSAKey(kc.Kid, kc.PrivateKey, kc.PublicKey)
+		default:
+			err = fmt.Errorf("key %s 不支持的算法 %s", kc.Kid, kc.Alg)
+		}
+		if err != nil {
+			panic(err)
+		}
+		keys = append(keys, k)
+	}
+	ks, err := jwt.NewKeySet(c.SigningKid, keys...)
+	if err != nil {
+		panic(err)
+	}
+	return jwt.TokenConfig{
+		Keys:       ks,
+		Expiration: c.Expiration,
+	}
+}
@@ -0,0 +1,22 @@
+package ioc
+
+import (
+	"context"
+	"gitee.com/geekbang/basic-go/webook/internal/repository"
+	"gitee.com/geekbang/basic-go/webook/internal/repository/dao/article"
+	"gitee.com/geekbang/basic-go/webook/pkg/logger"
+	"time"
+)
+
+// InitSearchRepository 用内存索引，启动的时候在后台从数据库重建
+func InitSearchRepository(dao article.ArticleDao, l logger.Logger) repository.SearchRepository {
+	res := repository.NewLocalSearchRepository(dao, l)
+	go func() {
+		ctx, cancel := context.WithTimeout(context.Background(), time.Minute*5)
+		defer cancel()
+		if err := res.Rebuild(ctx); err != nil {
+			l.Error("重建搜索索引失败", logger.Error(err))
+		}
+	}()
+	return res
+}
@@ -21,13 +21,25 @@ import (
 func InitWebServer(mdls []gin.HandlerFunc,
 	userHdl *web.UserHandler,
 	oauth2WechatHdl *web.OAuth2WechatHandler,
-	artHdl *web.ArticleHandler) *gin.Engine {
+	artHdl *web.ArticleHandler,
+	commentHdl *web.CommentHandler,
+	followHdl *web.FollowHandler,
+	feedHdl *web.FeedHandler,
+	searchHdl *web.SearchHandler,
+	adminHdl *web.AdminHandler,
+	jwksHdl *web.JWKSHandler) *gin.Engine {
 
 	server := gin.Default()
 	server.Use(mdls...)
 	userHdl.RegisterHandlers(server)
 	oauth2WechatHdl.RegisterHandlers(server)
 	artHdl.RegisterHandlers(server)
+	commentHdl.RegisterHandlers(server)
+	followHdl.RegisterHandlers(server)
+	feedHdl.RegisterHandlers(server)
+	searchHdl.RegisterHandlers(server)
+	adminHdl.RegisterHandlers(server)
+	jwksHdl.RegisterHandlers(server)
 	return server
 }
 
@@ -47,13 +59,18 @@ func InitMiddlewares(redisClient redis.Cmdable, jwtHdl jwt.Handler, log logger.L
 		// jwt 登录校验
 		middlewares.NewJWTLoginMiddlewareBuilder(jwtHdl).
 			IgnorePath("/users/signup").
+			IgnorePath("/users/signup/confirm").
 			IgnorePath("/users/login").
 			IgnorePath("/users/login_sms/code/send").
 			IgnorePath("/users/login_sms").
 			IgnorePath("/users/login").
-			IgnorePath("users/refresh_token").
+			IgnorePath("/users/refresh_token").
+			IgnorePath("/users/reset_password/code/send").
+			IgnorePath("/users/reset_password/verify").
+			IgnorePath("/users/reset_password").
 			IgnorePath("/oauth2/wechat/authurl").
-			IgnorePath("/oauth2/wechat/callback").Build(),
+			IgnorePath("/oauth2/wechat/callback").
+			IgnorePath("/.well-known/jwks.json").Build(),
 		ratelimit.NewBuilder(ratelimit2.NewRedisSlideWindowLimiter(redisClient, time.Second, 100)).Build(),
 	}
 }
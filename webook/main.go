@@ -1,6 +1,7 @@
 package main
 
 import (
+	"context"
 	"fmt"
 	"github.com/fsnotify/fsnotify"
 	"github.com/spf13/pflag"
@@ -9,9 +10,20 @@ import (
 
 func main() {
 	initViper()
-	server := InitWebServer()
+	app := InitApp()
+	app.cron.Start()
+	defer func() {
+		// 等正在运行的任务结束
+		<-app.cron.Stop().Done()
+		_ = app.rankingJob.Close()
+	}()
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go func() {
+		_ = app.scheduler.Schedule(ctx)
+	}()
 
-	err := server.Run(":8080")
+	err := app.server.Run(":8080")
 	if err != nil {
 		return
 	}
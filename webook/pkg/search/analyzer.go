@@ -0,0 +1,92 @@
+package search
+
+import (
+	"strings"
+	"unicode"
+)
+
+// Analyze 把文本切成索引用的词
+// 英文和数字按照单词切分并且转小写；
+// 中文没有词典，按照单字加上相邻两个字（bigram）切分，这样任意长度的中文查询都能命中
+func Analyze(text string) []string {
+	var res []string
+	for _, seg := range segments(text) {
+		if !seg.han {
+			res = append(res, seg.text)
+			continue
+		}
+		runes := []rune(seg.text)
+		for i := range runes {
+			res = append(res, string(runes[i]))
+			if i+1 < len(runes) {
+				res = append(res, string(runes[i:i+2]))
+			}
+		}
+	}
+	return res
+}
+
+// AnalyzeQuery 把查询切成词，和 Analyze 的区别是连续的中文只用 bigram
+// 因为单字太常见了，用来查询几乎没有区分度
+func AnalyzeQuery(query string) []string {
+	var res []string
+	seen := make(map[string]struct{})
+	add := func(term string) {
+		if _, ok := seen[term]; ok {
+			return
+		}
+		seen[term] = struct{}{}
+		res = append(res, term)
+	}
+	for _, seg := range segments(query) {
+		runes := []rune(seg.text)
+		if !seg.han || len(runes) == 1 {
+			add(seg.text)
+			continue
+		}
+		for i := 0; i+1 < len(runes); i++ {
+			add(string(runes[i : i+2]))
+		}
+	}
+	return res
+}
+
+type segment struct {
+	text string
+	han  bool
+}
+
+// segments 按照字符的类别切分，标点和空白都是分隔符
+func segments(text string) []segment {
+	var (
+		res []segment
+		sb  strings.Builder
+		han bool
+	)
+	flush := func() {
+		if sb.Len() > 0 {
+			res = append(res, segment{text: sb.String(), han: han})
+			sb.Reset()
+		}
+	}
+	for _, r := range text {
+		switch {
+		case unicode.Is(unicode.Han, r):
+			if !han {
+				flush()
+				han = true
+			}
+			sb.WriteRune(r)
+		case unicode.IsLetter(r) || unicode.IsDigit(r):
+			if han {
+				flush()
+				han = false
+			}
+			sb.WriteRune(unicode.ToLower(r))
+		default:
+			flush()
+		}
+	}
+	flush()
+	return res
+}
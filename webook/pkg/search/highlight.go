@@ -0,0 +1,81 @@
+package search
+
+import (
+	"html"
+	"sort"
+	"strings"
+	"unicode"
+)
+
+// Highlight 用 pre 和 post 把 text 里面命中 query 的部分包起来
+// maxLen 大于 0 的时候，只截取第一个命中的地方附近 maxLen 个字
+// 除了 pre 和 post，其余的内容都会做 HTML 转义
+func Highlight(text string, query string, pre string, post string, maxLen int) string {
+	runes := []rune(text)
+	lower := make([]rune, len(runes))
+	for i, r := range runes {
+		lower[i] = unicode.ToLower(r)
+	}
+	var spans [][2]int
+	for _, term := range AnalyzeQuery(query) {
+		tr := []rune(term)
+		for i := 0; i+len(tr) <= len(lower); i++ {
+			if string(lower[i:i+len(tr)]) == term {
+				spans = append(spans, [2]int{i, i + len(tr)})
+			}
+		}
+	}
+	spans = mergeSpans(spans)
+
+	start, end := 0, len(runes)
+	if maxLen > 0 && len(runes) > maxLen {
+		if len(spans) > 0 {
+			// 命中的地方前面留一点上下文
+			start = spans[0][0] - maxLen/5
+			if start < 0 {
+				start = 0
+			}
+		}
+		end = start + maxLen
+		if end > len(runes) {
+			end = len(runes)
+			start = end - maxLen
+		}
+	}
+
+	var sb strings.Builder
+	cur := start
+	for _, sp := range spans {
+		if sp[1] <= start || sp[0] >= end {
+			continue
+		}
+		s, e := max(sp[0], start), min(sp[1], end)
+		sb.WriteString(html.EscapeString(string(runes[cur:s])))
+		sb.WriteString(pre)
+		sb.WriteString(html.EscapeString(string(runes[s:e])))
+		sb.WriteString(post)
+		cur = e
+	}
+	sb.WriteString(html.EscapeString(string(runes[cur:end])))
+	return sb.String()
+}
+
+// mergeSpans 合并重叠或者相邻的区间，比如 bigram 命中的 [0,2) 和 [1,3)
+func mergeSpans(spans [][2]int) [][2]int {
+	if len(spans) == 0 {
+		return nil
+	}
+	sort.Slice(spans, func(i, j int) bool {
+		return spans[i][0] < spans[j][0]
+	})
+	res := [][2]int{spans[0]}
+	for _, sp := range spans[1:] {
+		last := &res[len(res)-1]
+		if sp[0] <= last[1] {
+			last[1] = max(last[1], sp[1])
+			continue
+		}
+		res = append(res, sp)
+	}
+	return res
+}
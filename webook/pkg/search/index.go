@@ -0,0 +1,132 @@
+package search
+
+import (
+	"math"
+	"sort"
+	"sync"
+)
+
+// Document 被索引的文档，不同的字段分开计算词频，按照权重加起来
+type Document struct {
+	Id     int64
+	Fields map[string]string
+}
+
+// Hit 一条搜索结果
+type Hit struct {
+	Id    int64
+	Score float64
+}
+
+// Index 内存里面的倒排索引，并发安全
+type Index struct {
+	mu sync.RWMutex
+	// 每个字段的权重，不在里面的字段权重是 1
+	boosts map[string]float64
+	// term => 文档 ID => 字段 => 词频
+	postings map[string]map[int64]map[string]int
+	// 文档 ID => 文档包含的 term，删除的时候用
+	docs map[int64][]string
+}
+
+func NewIndex(boosts map[string]float64) *Index {
+	return &Index{
+		boosts:   boosts,
+		postings: make(map[string]map[int64]map[string]int),
+		docs:     make(map[int64][]string),
+	}
+}
+
+// Put 新增或者覆盖文档
+func (idx *Index) Put(doc Document) {
+	idx.mu.Lock()
+	defer idx.mu.Unlock()
+	idx.delete(doc.Id)
+	terms := make([]string, 0, 16)
+	for field, text := range doc.Fields {
+		for _, term := range Analyze(text) {
+			docs, ok := idx.postings[term]
+			if !ok {
+				docs = make(map[int64]map[string]int)
+				idx.postings[term] = docs
+			}
+			fields, ok := docs[doc.Id]
+			if !ok {
+				fields = make(map[string]int)
+				docs[doc.Id] = fields
+				terms = append(terms, term)
+			}
+			fields[field]++
+		}
+	}
+	idx.docs[doc.Id] = terms
+}
+
+func (idx *Index) Delete(id int64) {
+	idx.mu.Lock()
+	defer idx.mu.Unlock()
+	idx.delete(id)
+}
+
+func (idx *Index) delete(id int64) {
+	for _, term := range idx.docs[id] {
+		docs := idx.postings[term]
+		delete(docs, id)
+		if len(docs) == 0 {
+			delete(idx.postings, term)
+		}
+	}
+	delete(idx.docs, id)
+}
+
+// Search 按照 TF-IDF 计分，命中的查询词越多分数越高
+// 分数相同的时候，ID 大的（新的）排在前面
+func (idx *Index) Search(query string, offset int, limit int) []Hit {
+	terms := AnalyzeQuery(query)
+	if len(terms) == 0 {
+		return nil
+	}
+	idx.mu.RLock()
+	n := float64(len(idx.docs))
+	scores := make(map[int64]float64)
+	matched := make(map[int64]int)
+	for _, term := range terms {
+		docs := idx.postings[term]
+		if len(docs) == 0 {
+			continue
+		}
+		idf := math.Log(1 + n/float64(len(docs)))
+		for id, fields := range docs {
+			for field, tf := range fields {
+				boost, ok := idx.boosts[field]
+				if !ok {
+					boost = 1
+				}
+				scores[id] += idf * boost * (1 + math.Log(float64(tf)))
+			}
+			matched[id]++
+		}
+	}
+	idx.mu.RUnlock()
+
+	hits := make([]Hit, 0, len(scores))
+	for id, score := range scores {
+		// 只命中部分查询词的要打折扣
+		coverage := float64(matched[id]) / float64(len(terms))
+		hits = append(hits, Hit{Id: id, Score: score * coverage})
+	}
+	sort.Slice(hits, func(i, j int) bool {
+		if hits[i].Score != hits[j].Score {
+			return hits[i].Score > hits[j].Score
+		}
+		return hits[i].Id > hits[j].Id
+	})
+	if offset >= len(hits) {
+		return nil
+	}
+	hits = hits[offset:]
+	if len(hits) > limit {
+		hits = hits[:limit]
+	}
+	return hits
+}
@@ -0,0 +1,105 @@
+package search
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestAnalyze(t *testing.T) {
+	assert.Equal(t, []string{"go", "语", "语言", "言"}, Analyze("Go语言"))
+	assert.Equal(t, []string{"go", "语言"}, AnalyzeQuery("Go 语言"))
+	assert.Equal(t, []string{"猫"}, AnalyzeQuery("猫"))
+}
+
+func TestIndex_Search(t *testing.T) {
+	idx := NewIndex(map[string]float64{"title": 2})
+	idx.Put(Document{Id: 1, Fields: map[string]string{
+		"title":   "学习 Go 语言",
+		"content": "并发编程",
+	}})
+	idx.Put(Document{Id: 2, Fields: map[string]string{
+		"title":   "Java 入门",
+		"content": "Go 语言和 Java 的对比",
+	}})
+	idx.Put(Document{Id: 3, Fields: map[string]string{
+		"title":   "今天的天气",
+		"content": "晴天",
+	}})
+
+	testCases := []struct {
+		name  string
+		query string
+
+		wantIds []int64
+	}{
+		{
+			name:    "标题命中的排在前面",
+			query:   "go语言",
+			wantIds: []int64{1, 2},
+		},
+		{
+			name:    "单个汉字",
+			query:   "晴",
+			wantIds: []int64{3},
+		},
+		{
+			name:  "没有命中",
+			query: "python",
+		},
+		{
+			name:  "只有标点",
+			query: "，。",
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			var ids []int64
+			for _, hit := range idx.Search(tc.query, 0, 10) {
+				ids = append(ids, hit.Id)
+			}
+			assert.Equal(t, tc.wantIds, ids)
+		})
+	}
+
+	// 覆盖之后旧的内容搜不到了
+	idx.Put(Document{Id: 3, Fields: map[string]string{"title": "下雨"}})
+	assert.Empty(t, idx.Search("晴天", 0, 10))
+	idx.Delete(3)
+	assert.Empty(t, idx.Search("下雨", 0, 10))
+}
+
+func TestHighlight(t *testing.T) {
+	testCases := []struct {
+		name   string
+		text   string
+		query  string
+		maxLen int
+
+		want string
+	}{
+		{
+			name:  "合并相邻的 bigram",
+			text:  "学习Go语言编程",
+			query: "go 语言编",
+			want:  "学习<em>Go语言编</em>程",
+		},
+		{
+			name:  "转义 HTML",
+			text:  "<b>Go</b>",
+			query: "go",
+			want:  "&lt;b&gt;<em>Go</em>&lt;/b&gt;",
+		},
+		{
+			name:   "截取命中附近的内容",
+			text:   "一二三四五六七八九十甲乙丙丁",
+			query:  "十甲",
+			maxLen: 5,
+			want:   "九<em>十甲</em>乙丙",
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.want, Highlight(tc.text, tc.query, "<em>", "</em>", tc.maxLen))
+		})
+	}
+}
@@ -0,0 +1,19 @@
+-- 锁的 key
+local key = KEYS[1]
+-- 唯一标识，只有持有这个值的人才能解锁、续约
+local val = ARGV[1]
+-- 过期时间，毫秒
+local expiration = tonumber(ARGV[2])
+
+local cur = redis.call("GET", key)
+if cur == false then
+    -- 没人持有锁
+    return redis.call("SET", key, val, "PX", expiration)
+elseif cur == val then
+    -- 上一次加锁其实成功了，只是超时了没有收到响应，重试的时候进来这里
+    redis.call("PEXPIRE", key, expiration)
+    return "OK"
+else
+    -- 锁被别人拿着
+    return ""
+end
@@ -0,0 +1,6 @@
+-- 检查是不是自己的锁，是的话才续约
+if redis.call("GET", KEYS[1]) == ARGV[1] then
+    return redis.call("PEXPIRE", KEYS[1], ARGV[2])
+else
+    return 0
+end
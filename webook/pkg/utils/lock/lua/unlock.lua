@@ -0,0 +1,7 @@
+-- 检查是不是自己的锁，是的话才删除
+-- 否则会把别人的锁删掉
+if redis.call("GET", KEYS[1]) == ARGV[1] then
+    return redis.call("DEL", KEYS[1])
+else
+    return 0
+end
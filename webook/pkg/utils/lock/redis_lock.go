@@ -0,0 +1,171 @@
+package lock
+
+import (
+	"context"
+	_ "embed"
+	"errors"
+	"fmt"
+	"github.com/google/uuid"
+	"github.com/redis/go-redis/v9"
+	"sync"
+	"time"
+)
+
+var (
+	//go:embed lua/lock.lua
+	luaLock string
+	//go:embed lua/unlock.lua
+	luaUnlock string
+	//go:embed lua/refresh.lua
+	luaRefresh string
+)
+
+// Client 基于 Redis 的分布式锁
+type Client struct {
+	cmd redis.Cmdable
+}
+
+func NewClient(cmd redis.Cmdable) *Client {
+	return &Client{cmd: cmd}
+}
+
+// TryLock 只尝试一次，锁被别人持有的时候返回 ErrFailedToPreemptLock
+func (c *Client) TryLock(ctx context.Context, key string, expiration time.Duration) (*Lock, error) {
+	val := uuid.New().String()
+	res, err := c.cmd.Eval(ctx, luaLock, []string{key}, val, expiration.Milliseconds()).Result()
+	if err != nil {
+		return nil, err
+	}
+	if res != "OK" {
+		return nil, ErrFailedToPreemptLock
+	}
+	return newLock(c.cmd, key, val, expiration), nil
+}
+
+// Lock 加锁，失败了按照 retry 的策略重试
+// timeout 是每一次加锁请求的超时时间
+func (c *Client) Lock(ctx context.Context, key string, expiration time.Duration,
+	retry RetryStrategy, timeout time.Duration) (*Lock, error) {
+	// 重试的时候用同一个值，这样上一次超时但其实成功了的情况也能拿到锁
+	val := uuid.New().String()
+	var timer *time.Timer
+	defer func() {
+		if timer != nil {
+			timer.Stop()
+		}
+	}()
+	for {
+		lctx, cancel := context.WithTimeout(ctx, timeout)
+		res, err := c.cmd.Eval(lctx, luaLock, []string{key}, val, expiration.Milliseconds()).Result()
+		cancel()
+		if err != nil && !errors.Is(err, context.DeadlineExceeded) {
+			return nil, err
+		}
+		if res == "OK" {
+			return newLock(c.cmd, key, val, expiration), nil
+		}
+
+		interval, ok := retry.Next()
+		if !ok {
+			if err != nil {
+				return nil, fmt.Errorf("lock: 重试机会耗尽，最后一次错误 %w", err)
+			}
+			return nil, ErrFailedToPreemptLock
+		}
+		if timer == nil {
+			timer = time.NewTimer(interval)
+		} else {
+			timer.Reset(interval)
+		}
+		select {
+		case <-timer.C:
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	}
+}
+
+type Lock struct {
+	cmd        redis.Cmdable
+	key        string
+	value      string
+	expiration time.Duration
+
+	unlockOnce sync.Once
+	unlock     chan struct{}
+	lostOnce   sync.Once
+	lost       chan struct{}
+}
+
+func newLock(cmd redis.Cmdable, key string, value string, expiration time.Duration) *Lock {
+	return &Lock{
+		cmd:        cmd,
+		key:        key,
+		value:      value,
+		expiration: expiration,
+		unlock:     make(chan struct{}),
+		lost:       make(chan struct{}),
+	}
+}
+
+// Refresh 续约一次
+func (l *Lock) Refresh(ctx context.Context) error {
+	res, err := l.cmd.Eval(ctx, luaRefresh, []string{l.key}, l.value, l.expiration.Milliseconds()).Int64()
+	if err != nil {
+		return err
+	}
+	if res != 1 {
+		return ErrLockNotHold
+	}
+	return nil
+}
+
+// AutoRefresh 每隔 interval 续约一次，直到 Unlock 或者锁丢了
+// 调用者一般是开一个 goroutine 来执行。锁丢了的时候返回 ErrLockNotHold，同时 Lost 会被关闭
+func (l *Lock) AutoRefresh(interval time.Duration, timeout time.Duration) error {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	// 超时了立刻重试，不等下一个周期
+	retry := make(chan struct{}, 1)
+	for {
+		select {
+		case <-ticker.C:
+		case <-retry:
+		case <-l.unlock:
+			return nil
+		}
+		ctx, cancel := context.WithTimeout(context.Background(), timeout)
+		err := l.Refresh(ctx)
+		cancel()
+		switch {
+		case err == nil:
+		case errors.Is(err, context.DeadlineExceeded):
+			retry <- struct{}{}
+		default:
+			l.lostOnce.Do(func() {
+				close(l.lost)
+			})
+			return err
+		}
+	}
+}
+
+// Lost 续约失败的时候会被关闭，持有锁的业务应该停下来
+func (l *Lock) Lost() <-chan struct{} {
+	return l.lost
+}
+
+// Unlock 释放锁，只会释放自己持有的锁
+func (l *Lock) Unlock(ctx context.Context) error {
+	l.unlockOnce.Do(func() {
+		close(l.unlock)
+	})
+	res, err := l.cmd.Eval(ctx, luaUnlock, []string{l.key}, l.value).Int64()
+	if err != nil {
+		return err
+	}
+	if res != 1 {
+		return ErrLockNotHold
+	}
+	return nil
+}
@@ -0,0 +1,211 @@
+package lock
+
+import (
+	"context"
+	"errors"
+	"gitee.com/geekbang/basic-go/webook/internal/repository/cache/redismocks"
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
+	"testing"
+	"time"
+)
+
+func TestClient_TryLock(t *testing.T) {
+	testCases := []struct {
+		name string
+		mock func(ctrl *gomock.Controller) redis.Cmdable
+
+		wantErr error
+	}{
+		{
+			name: "加锁成功",
+			mock: func(ctrl *gomock.Controller) redis.Cmdable {
+				cmd := redismocks.NewMockCmdable(ctrl)
+				res := redis.NewCmd(context.Background())
+				res.SetVal("OK")
+				cmd.EXPECT().Eval(gomock.Any(), luaLock, []string{"key1"}, gomock.Any()).
+					Return(res)
+				return cmd
+			},
+		},
+		{
+			name: "锁被别人持有",
+			mock: func(ctrl *gomock.Controller) redis.Cmdable {
+				cmd := redismocks.NewMockCmdable(ctrl)
+				res := redis.NewCmd(context.Background())
+				res.SetVal("")
+				cmd.EXPECT().Eval(gomock.Any(), luaLock, []string{"key1"}, gomock.Any()).
+					Return(res)
+				return cmd
+			},
+			wantErr: ErrFailedToPreemptLock,
+		},
+		{
+			name: "网络错误",
+			mock: func(ctrl *gomock.Controller) redis.Cmdable {
+				cmd := redismocks.NewMockCmdable(ctrl)
+				res := redis.NewCmd(context.Background())
+				res.SetErr(errors.New("network error"))
+				cmd.EXPECT().Eval(gomock.Any(), luaLock, []string{"key1"}, gomock.Any()).
+					Return(res)
+				return cmd
+			},
+			wantErr: errors.New("network error"),
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+			client := NewClient(tc.mock(ctrl))
+			l, err := client.TryLock(context.Background(), "key1", time.Minute)
+			assert.Equal(t, tc.wantErr, err)
+			if err != nil {
+				return
+			}
+			assert.Equal(t, "key1", l.key)
+			assert.NotEmpty(t, l.value)
+			assert.Equal(t, time.Minute, l.expiration)
+		})
+	}
+}
+
+func TestClient_Lock(t *testing.T) {
+	testCases := []struct {
+		name  string
+		mock  func(ctrl *gomock.Controller) redis.Cmdable
+		retry RetryStrategy
+
+		wantErr error
+	}{
+		{
+			name: "重试之后加锁成功",
+			mock: func(ctrl *gomock.Controller) redis.Cmdable {
+				cmd := redismocks.NewMockCmdable(ctrl)
+				first := redis.NewCmd(context.Background())
+				first.SetVal("")
+				second := redis.NewCmd(context.Background())
+				second.SetVal("OK")
+				gomock.InOrder(
+					cmd.EXPECT().Eval(gomock.Any(), luaLock, []string{"key1"}, gomock.Any()).
+						Return(first),
+					cmd.EXPECT().Eval(gomock.Any(), luaLock, []string{"key1"}, gomock.Any()).
+						Return(second),
+				)
+				return cmd
+			},
+			retry: &FixIntervalRetry{Interval: time.Millisecond, Max: 3},
+		},
+		{
+			name: "超时之后重试成功",
+			mock: func(ctrl *gomock.Controller) redis.Cmdable {
+				cmd := redismocks.NewMockCmdable(ctrl)
+				first := redis.NewCmd(context.Background())
+				first.SetErr(context.DeadlineExceeded)
+				second := redis.NewCmd(context.Background())
+				second.SetVal("OK")
+				gomock.InOrder(
+					cmd.EXPECT().Eval(gomock.Any(), luaLock, []string{"key1"}, gomock.Any()).
+						Return(first),
+					cmd.EXPECT().Eval(gomock.Any(), luaLock, []string{"key1"}, gomock.Any()).
+						Return(second),
+				)
+				return cmd
+			},
+			retry: &FixIntervalRetry{Interval: time.Millisecond, Max: 3},
+		},
+		{
+			name: "重试次数耗尽",
+			mock: func(ctrl *gomock.Controller) redis.Cmdable {
+				cmd := redismocks.NewMockCmdable(ctrl)
+				res := redis.NewCmd(context.Background())
+				res.SetVal("")
+				cmd.EXPECT().Eval(gomock.Any(), luaLock, []string{"key1"}, gomock.Any()).
+					Times(3).Return(res)
+				return cmd
+			},
+			retry:   &FixIntervalRetry{Interval: time.Millisecond, Max: 2},
+			wantErr: ErrFailedToPreemptLock,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+			client := NewClient(tc.mock(ctrl))
+			_, err := client.Lock(context.Background(), "key1", time.Minute, tc.retry, time.Second)
+			assert.Equal(t, tc.wantErr, err)
+		})
+	}
+}
+
+func TestLock_Unlock(t *testing.T) {
+	testCases := []struct {
+		name string
+		mock func(ctrl *gomock.Controller) redis.Cmdable
+
+		wantErr error
+	}{
+		{
+			name: "解锁成功",
+			mock: func(ctrl *gomock.Controller) redis.Cmdable {
+				cmd := redismocks.NewMockCmdable(ctrl)
+				res := redis.NewCmd(context.Background())
+				res.SetVal(int64(1))
+				cmd.EXPECT().Eval(gomock.Any(), luaUnlock, []string{"key1"}, "value1").
+					Return(res)
+				return cmd
+			},
+		},
+		{
+			name: "锁不是自己的",
+			mock: func(ctrl *gomock.Controller) redis.Cmdable {
+				cmd := redismocks.NewMockCmdable(ctrl)
+				res := redis.NewCmd(context.Background())
+				res.SetVal(int64(0))
+				cmd.EXPECT().Eval(gomock.Any(), luaUnlock, []string{"key1"}, "value1").
+					Return(res)
+				return cmd
+			},
+			wantErr: ErrLockNotHold,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+			l := newLock(tc.mock(ctrl), "key1", "value1", time.Minute)
+			err := l.Unlock(context.Background())
+			assert.Equal(t, tc.wantErr, err)
+		})
+	}
+}
+
+func TestLock_AutoRefresh(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	cmd := redismocks.NewMockCmdable(ctrl)
+	ok := redis.NewCmd(context.Background())
+	ok.SetVal(int64(1))
+	lost := redis.NewCmd(context.Background())
+	lost.SetVal(int64(0))
+	gomock.InOrder(
+		cmd.EXPECT().Eval(gomock.Any(), luaRefresh, []string{"key1"}, "value1", int64(60000)).
+			Return(ok),
+		// 第二次续约的时候发现锁已经不是自己的了
+		cmd.EXPECT().Eval(gomock.Any(), luaRefresh, []string{"key1"}, "value1", int64(60000)).
+			Return(lost),
+	)
+	l := newLock(cmd, "key1", "value1", time.Minute)
+	err := l.AutoRefresh(time.Millisecond*10, time.Second)
+	assert.Equal(t, ErrLockNotHold, err)
+	select {
+	case <-l.Lost():
+	default:
+		t.Fatal("锁丢了之后 Lost 应该被关闭")
+	}
+}
@@ -0,0 +1,31 @@
+package lock
+
+import (
+	"errors"
+	"time"
+)
+
+var (
+	// ErrFailedToPreemptLock 锁被别人持有
+	ErrFailedToPreemptLock = errors.New("lock: 抢锁失败")
+	// ErrLockNotHold 锁不是自己的，要么已经过期了，要么被别人拿走了
+	ErrLockNotHold = errors.New("lock: 未持有锁")
+)
+
+// RetryStrategy 加锁的重试策略
+type RetryStrategy interface {
+	// Next 返回下一次重试的间隔，false 表示不要再重试了
+	Next() (time.Duration, bool)
+}
+
+// FixIntervalRetry 固定间隔重试，最多重试 Max 次
+type FixIntervalRetry struct {
+	Interval time.Duration
+	Max      int
+	cnt      int
+}
+
+func (f *FixIntervalRetry) Next() (time.Duration, bool) {
+	f.cnt++
+	return f.Interval, f.cnt <= f.Max
+}
@@ -7,15 +7,19 @@ import (
 	"gitee.com/geekbang/basic-go/webook/internal/repository/cache"
 	"gitee.com/geekbang/basic-go/webook/internal/repository/dao"
 	"gitee.com/geekbang/basic-go/webook/internal/repository/dao/article"
+	"gitee.com/geekbang/basic-go/webook/internal/repository/dao/comment"
+	"gitee.com/geekbang/basic-go/webook/internal/repository/dao/feed"
+	"gitee.com/geekbang/basic-go/webook/internal/repository/dao/follow"
+	"gitee.com/geekbang/basic-go/webook/internal/repository/dao/interactive"
 	"gitee.com/geekbang/basic-go/webook/internal/service"
 	"gitee.com/geekbang/basic-go/webook/internal/web"
 	"gitee.com/geekbang/basic-go/webook/internal/web/jwt"
 	"gitee.com/geekbang/basic-go/webook/ioc"
-	"github.com/gin-gonic/gin"
+	"gitee.com/geekbang/basic-go/webook/pkg/utils/lock"
 	"github.com/google/wire"
 )
 
-var thirdProvider = wire.NewSet(ioc.InitDB, ioc.InitRedis, ioc.InitLogger, jwt.NewJWTHandler)
+var thirdProvider = wire.NewSet(ioc.InitDB, ioc.InitRedis, ioc.InitLogger, ioc.InitJWTConfig, jwt.NewJWTHandler)
 var userSvcProvider = wire.NewSet(
 	dao.NewUserDaoGorm,
 	cache.NewRedisUserCache,
@@ -29,8 +33,53 @@ var articleSvcProvider = wire.NewSet(
 	cache.NewRedisArticleCache,
 )
 
+var interactiveSvcProvider = wire.NewSet(
+	service.NewInteractiveService,
+	repository.NewInteractiveRepository,
+	interactive.NewInteractiveDaoGORM,
+	cache.NewRedisInteractiveCache,
+)
+
+var rankingSvcProvider = wire.NewSet(
+	service.NewBatchRankingService,
+	repository.NewCachedRankingRepository,
+	cache.NewRankingRedisCache,
+	cache.NewRankingLocalCache,
+)
+
+var cronJobProvider = wire.NewSet(
+	ioc.InitCronJobService,
+	repository.NewCronJobRepository,
+	dao.NewJobDaoGORM,
+)
+
+var commentSvcProvider = wire.NewSet(
+	service.NewCommentService,
+	repository.NewCommentRepository,
+	comment.NewCommentDaoGORM,
+	cache.NewRedisCommentCache,
+)
+
+var followSvcProvider = wire.NewSet(
+	service.NewFollowService,
+	repository.NewFollowRepository,
+	follow.NewFollowDaoGORM,
+	cache.NewRedisFollowCache,
+)
+
+var feedSvcProvider = wire.NewSet(
+	service.NewFeedService,
+	repository.NewFeedRepository,
+	feed.NewFeedDaoGORM,
+)
+
+var searchSvcProvider = wire.NewSet(
+	service.NewSearchService,
+	ioc.InitSearchRepository,
+)
+
 var codeSvcProvider = wire.NewSet(
-	ioc.InitSMSService, cache.NewCodeCacheImpl,
+	ioc.InitSMSService, ioc.InitEmailService, cache.NewCodeCacheImpl,
 	repository.NewCodeRepoImpl,
 	service.NewCodeServiceImpl,
 )
@@ -39,7 +88,7 @@ var weChatProvider = wire.NewSet(
 	ioc.InitWechatService,
 )
 
-func InitWebServer() *gin.Engine {
+func InitApp() *App {
 	wire.Build(
 		thirdProvider,
 		userSvcProvider,
@@ -53,11 +102,44 @@ func InitWebServer() *gin.Engine {
 
 		// article
 		articleSvcProvider,
+		interactiveSvcProvider,
+		rankingSvcProvider,
 		web.NewArticleHandler,
 
+		// comment
+		commentSvcProvider,
+		web.NewCommentHandler,
+
+		// follow
+		followSvcProvider,
+		web.NewFollowHandler,
+
+		// feed
+		feedSvcProvider,
+		web.NewFeedHandler,
+
+		// search
+		searchSvcProvider,
+		web.NewSearchHandler,
+
+		// admin
+		web.NewAdminHandler,
+
+		// jwks
+		web.NewJWKSHandler,
+
 		// web
 		ioc.InitMiddlewares,
 		ioc.InitWebServer,
+
+		// job
+		lock.NewClient,
+		ioc.InitRankingJob,
+		ioc.InitJobs,
+		cronJobProvider,
+		ioc.InitScheduler,
+
+		wire.Struct(new(App), "*"),
 	)
-	return &gin.Engine{}
+	return new(App)
 }
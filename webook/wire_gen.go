@@ -11,50 +11,110 @@ import (
 	"gitee.com/geekbang/basic-go/webook/internal/repository/cache"
 	"gitee.com/geekbang/basic-go/webook/internal/repository/dao"
 	"gitee.com/geekbang/basic-go/webook/internal/repository/dao/article"
+	"gitee.com/geekbang/basic-go/webook/internal/repository/dao/comment"
+	"gitee.com/geekbang/basic-go/webook/internal/repository/dao/feed"
+	"gitee.com/geekbang/basic-go/webook/internal/repository/dao/follow"
+	"gitee.com/geekbang/basic-go/webook/internal/repository/dao/interactive"
 	"gitee.com/geekbang/basic-go/webook/internal/service"
 	"gitee.com/geekbang/basic-go/webook/internal/web"
 	"gitee.com/geekbang/basic-go/webook/internal/web/jwt"
 	"gitee.com/geekbang/basic-go/webook/ioc"
-	"github.com/gin-gonic/gin"
+	"gitee.com/geekbang/basic-go/webook/pkg/utils/lock"
 	"github.com/google/wire"
 )
 
 // Injectors from wire.go:
 
-func InitWebServer() *gin.Engine {
+func InitApp() *App {
 	cmdable := ioc.InitRedis()
-	handler := jwt.NewJWTHandler(cmdable)
+	config := ioc.InitJWTConfig()
+	handler := jwt.NewJWTHandler(cmdable, config)
 	logger := ioc.InitLogger()
 	v := ioc.InitMiddlewares(cmdable, handler, logger)
 	db := ioc.InitDB(logger)
 	userDao := dao.NewUserDaoGorm(db)
 	userCache := cache.NewRedisUserCache(cmdable)
 	userRepo := repository.NewUserRepoImpl(userDao, userCache)
-	userService := service.NewUserServiceImpl(userRepo)
+	articleDao := article.NewArticleDaoGORM(db)
+	articleCache := cache.NewRedisArticleCache(cmdable)
+	searchRepository := ioc.InitSearchRepository(articleDao, logger)
+	articleRepository := repository.NewArticleRepository(articleDao, articleCache, logger, userRepo, searchRepository)
+	userService := service.NewUserServiceImpl(userRepo, articleRepository, logger)
 	smsService := ioc.InitSMSService()
+	emailService := ioc.InitEmailService()
 	codeCache := cache.NewCodeCacheImpl(cmdable)
 	codeRepo := repository.NewCodeRepoImpl(codeCache)
-	codeService := service.NewCodeServiceImpl(smsService, codeRepo)
+	codeService := service.NewCodeServiceImpl(smsService, emailService, codeRepo)
 	userHandler := web.NewUserHandler(userService, codeService, handler, logger)
 	wechatService := ioc.InitWechatService()
 	oAuth2WechatHandler := web.NewOAuth2WechatHandler(wechatService, userService, handler)
-	articleDao := article.NewArticleDaoGORM(db)
-	articleCache := cache.NewRedisArticleCache(cmdable)
-	articleRepository := repository.NewArticleRepository(articleDao, articleCache, logger, userRepo)
-	articleService := service.NewArticleService(articleRepository, logger)
-	articleHandler := web.NewArticleHandler(articleService, logger)
-	engine := ioc.InitWebServer(v, userHandler, oAuth2WechatHandler, articleHandler)
-	return engine
+	followDao := follow.NewFollowDaoGORM(db)
+	followCache := cache.NewRedisFollowCache(cmdable)
+	followRepository := repository.NewFollowRepository(followDao, followCache, logger)
+	followService := service.NewFollowService(followRepository, userRepo)
+	feedDao := feed.NewFeedDaoGORM(db)
+	feedRepository := repository.NewFeedRepository(feedDao)
+	feedService := service.NewFeedService(feedRepository, articleRepository, followService, logger)
+	articleService := service.NewArticleService(articleRepository, feedService, logger)
+	interactiveDao := interactive.NewInteractiveDaoGORM(db)
+	interactiveCache := cache.NewRedisInteractiveCache(cmdable)
+	interactiveRepository := repository.NewInteractiveRepository(interactiveDao, interactiveCache, logger)
+	interactiveService := service.NewInteractiveService(interactiveRepository, logger)
+	rankingRedisCache := cache.NewRankingRedisCache(cmdable)
+	rankingLocalCache := cache.NewRankingLocalCache()
+	rankingRepository := repository.NewCachedRankingRepository(rankingRedisCache, rankingLocalCache, logger)
+	rankingService := service.NewBatchRankingService(articleService, interactiveService, rankingRepository)
+	commentDao := comment.NewCommentDaoGORM(db)
+	commentCache := cache.NewRedisCommentCache(cmdable)
+	commentRepository := repository.NewCommentRepository(commentDao, commentCache, userRepo, logger)
+	commentService := service.NewCommentService(commentRepository, articleService)
+	articleHandler := web.NewArticleHandler(articleService, interactiveService, rankingService, commentService, followService, logger)
+	commentHandler := web.NewCommentHandler(commentService, logger)
+	followHandler := web.NewFollowHandler(followService, logger)
+	feedHandler := web.NewFeedHandler(feedService, logger)
+	searchService := service.NewSearchService(searchRepository, userRepo, logger)
+	searchHandler := web.NewSearchHandler(searchService, logger)
+	adminHandler := web.NewAdminHandler(userService, articleService, handler, logger)
+	jwksHandler := web.NewJWKSHandler(config)
+	engine := ioc.InitWebServer(v, userHandler, oAuth2WechatHandler, articleHandler, commentHandler, followHandler, feedHandler, searchHandler, adminHandler, jwksHandler)
+	client := lock.NewClient(cmdable)
+	rankingJob := ioc.InitRankingJob(rankingService, client, logger)
+	cron := ioc.InitJobs(logger, rankingJob)
+	jobDao := dao.NewJobDaoGORM(db)
+	cronJobRepository := repository.NewCronJobRepository(jobDao)
+	cronJobService := ioc.InitCronJobService(cronJobRepository, logger)
+	scheduler := ioc.InitScheduler(logger, cronJobService, articleService)
+	app := &App{
+		server:     engine,
+		cron:       cron,
+		scheduler:  scheduler,
+		rankingJob: rankingJob,
+	}
+	return app
 }
 
 // wire.go:
 
-var thirdProvider = wire.NewSet(ioc.InitDB, ioc.InitRedis, ioc.InitLogger, jwt.NewJWTHandler)
+var thirdProvider = wire.NewSet(ioc.InitDB, ioc.InitRedis, ioc.InitLogger, ioc.InitJWTConfig, jwt.NewJWTHandler)
 
 var userSvcProvider = wire.NewSet(dao.NewUserDaoGorm, cache.NewRedisUserCache, repository.NewUserRepoImpl, service.NewUserServiceImpl)
 
 var articleSvcProvider = wire.NewSet(service.NewArticleService, repository.NewArticleRepository, article.NewArticleDaoGORM, cache.NewRedisArticleCache)
 
-var codeSvcProvider = wire.NewSet(ioc.InitSMSService, cache.NewCodeCacheImpl, repository.NewCodeRepoImpl, service.NewCodeServiceImpl)
+var interactiveSvcProvider = wire.NewSet(service.NewInteractiveService, repository.NewInteractiveRepository, interactive.NewInteractiveDaoGORM, cache.NewRedisInteractiveCache)
+
+var rankingSvcProvider = wire.NewSet(service.NewBatchRankingService, repository.NewCachedRankingRepository, cache.NewRankingRedisCache, cache.NewRankingLocalCache)
+
+var cronJobProvider = wire.NewSet(ioc.InitCronJobService, repository.NewCronJobRepository, dao.NewJobDaoGORM)
+
+var commentSvcProvider = wire.NewSet(service.NewCommentService, repository.NewCommentRepository, comment.NewCommentDaoGORM, cache.NewRedisCommentCache)
+
+var followSvcProvider = wire.NewSet(service.NewFollowService, repository.NewFollowRepository, follow.NewFollowDaoGORM, cache.NewRedisFollowCache)
+
+var feedSvcProvider = wire.NewSet(service.NewFeedService, repository.NewFeedRepository, feed.NewFeedDaoGORM)
+
+var searchSvcProvider = wire.NewSet(service.NewSearchService, ioc.InitSearchRepository)
+
+var codeSvcProvider = wire.NewSet(ioc.InitSMSService, ioc.InitEmailService, cache.NewCodeCacheImpl, repository.NewCodeRepoImpl, service.NewCodeServiceImpl)
 
 var weChatProvider = wire.NewSet(ioc.InitWechatService)